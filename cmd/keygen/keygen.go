@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/dbunt1tled/fiber-go-api/pkg/hasher"
+)
+
+const usage = `Usage: keygen -alg <algorithm> [-dir <path>]
+
+Algorithms: RS256 RS512 ES256 ES384 ES512 EdDSA HS256 HS512 v4.public v4.local
+
+Prints the environment lines of a new token key. With -dir a key pair is
+written as <kid>.pem and <kid>.pub.pem for APP_SERVER_JWT_KEYS_DIR instead.
+`
+
+func main() {
+	fs := flag.NewFlagSet("keygen", flag.ExitOnError)
+	fs.Usage = func() { fmt.Print(usage) }
+	alg := fs.String("alg", hasher.AlgRS256, "token algorithm")
+	dir := fs.String("dir", "", "key directory")
+	_ = fs.Parse(os.Args[1:])
+
+	key, err := hasher.GenerateKey(*alg)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("APP_SERVER_JWT_ALGORITHM=%s\n", key.Algorithm)
+	switch {
+	case key.Secret != "":
+		fmt.Printf("APP_SERVER_JWT_SECRET=%s\n", key.Secret)
+	case *dir != "":
+		if err = writeKeyPair(*dir, key); err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Printf("APP_SERVER_JWT_KEYS_DIR=%s\n", *dir)
+		fmt.Printf("APP_SERVER_JWT_KEYS_ACTIVE=%s\n", key.KeyID)
+	default:
+		fmt.Printf("APP_SERVER_JWT_KID=%s\n", key.KeyID)
+		fmt.Printf("APP_SERVER_JWT_PUBLIC=%s\n", key.PublicKey())
+		fmt.Printf("APP_SERVER_JWT_PRIVATE=%s\n", key.PrivateKey())
+	}
+}
+
+func writeKeyPair(dir string, key *hasher.GeneratedKey) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil { //nolint:mnd // owner only
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, key.KeyID+".pem"), key.PrivatePEM, 0o600); err != nil { //nolint:mnd // owner only
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, key.KeyID+".pub.pem"), key.PublicPEM, 0o644) //nolint:mnd,gosec // public key
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/bytedance/sonic"
+	"github.com/dbunt1tled/fiber-go-api/internal/config"
+	"github.com/dbunt1tled/fiber-go-api/pkg/queue"
+)
+
+const usage = `Usage: queue <command> [arguments]
+
+Commands:
+  queues                                   list queues with stats
+  tasks   -queue emails [-state archived] [-page 1] [-limit 20]
+                                           list tasks (payloads are redacted)
+  retry   -queue emails (-id <task> | -all) run archived task(s) again
+  delete  -queue emails (-id <task> | -all) delete archived task(s)
+  pause   -queue emails                    pause processing of a queue
+  unpause -queue emails                    resume processing of a queue
+  cancel  -id <task>                       cancel an active task
+`
+
+func main() {
+	if len(os.Args) < 2 { //nolint:mnd // command name is required
+		fmt.Print(usage)
+		os.Exit(2) //nolint:mnd // usage error
+	}
+	config.Load()
+
+	inspector := queue.NewInspector(config.Get().Redis.Options())
+	defer func() {
+		_ = inspector.Close()
+	}()
+
+	result, err := run(inspector, os.Args[1], os.Args[2:])
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	out, err := sonic.ConfigFastest.MarshalIndent(result, "", "  ")
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Println(string(out))
+}
+
+func run(inspector *queue.Inspector, command string, args []string) (any, error) {
+	fs := flag.NewFlagSet(command, flag.ExitOnError)
+	name := fs.String("queue", "", "queue name")
+	id := fs.String("id", "", "task id")
+	all := fs.Bool("all", false, "apply to every archived task of the queue")
+	state := fs.String("state", queue.StateArchived, "task state")
+	page := fs.Int("page", 1, "page")
+	limit := fs.Int("limit", 20, "page size") //nolint:mnd // default page size
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	switch command {
+	case "queues":
+		return inspector.Queues()
+	case "tasks":
+		return inspector.Tasks(*name, *state, *page, *limit)
+	case "retry":
+		if *all {
+			return affected(inspector.RunAllArchived(*name))
+		}
+		return affected(1, inspector.RunTask(*name, *id))
+	case "delete":
+		if *all {
+			return affected(inspector.DeleteAllArchived(*name))
+		}
+		return affected(1, inspector.DeleteTask(*name, *id))
+	case "pause":
+		if err := inspector.Pause(*name); err != nil {
+			return nil, err
+		}
+		return inspector.Queue(*name)
+	case "unpause":
+		if err := inspector.Unpause(*name); err != nil {
+			return nil, err
+		}
+		return inspector.Queue(*name)
+	case "cancel":
+		return affected(1, inspector.Cancel(*id))
+	default:
+		fmt.Print(usage)
+		return nil, errors.New("unknown command " + command)
+	}
+}
+
+func affected(n int, err error) (any, error) {
+	if err != nil {
+		return nil, err
+	}
+	return map[string]int{"affected": n}, nil
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"context"
+
+	"github.com/dbunt1tled/fiber-go-api/internal/app"
+	"github.com/dbunt1tled/fiber-go-api/internal/config"
+	"github.com/dbunt1tled/fiber-go-api/pkg/log"
+)
+
+func main() {
+	config.Load()
+	log.Load(config.Get().Name, config.Get().Env, config.Get().Log.Level, config.Get().Log.File)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := app.Bootstrap(ctx, config.ModeWorker)
+	application := app.NewApp(cfg)
+	err := application.Run(ctx)
+	if err != nil {
+		panic(err)
+	}
+}
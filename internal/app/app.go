@@ -2,12 +2,12 @@ package app
 
 import (
 	"context"
+	"crypto/rand"
 	"fmt"
 	"os"
 	"os/signal"
 	"strconv"
 	"strings"
-	"sync"
 	"syscall"
 	"time"
 
@@ -15,14 +15,34 @@ import (
 	"github.com/dbunt1tled/fiber-go-api/internal/config"
 	"github.com/dbunt1tled/fiber-go-api/internal/lib/aemail"
 	"github.com/dbunt1tled/fiber-go-api/internal/lib/email"
+	"github.com/dbunt1tled/fiber-go-api/internal/lib/view"
+	"github.com/dbunt1tled/fiber-go-api/internal/modules/apikey"
+	"github.com/dbunt1tled/fiber-go-api/internal/modules/auditlog"
 	"github.com/dbunt1tled/fiber-go-api/internal/modules/auth"
+	"github.com/dbunt1tled/fiber-go-api/internal/modules/client"
+	"github.com/dbunt1tled/fiber-go-api/internal/modules/dev"
+	"github.com/dbunt1tled/fiber-go-api/internal/modules/file"
+	"github.com/dbunt1tled/fiber-go-api/internal/modules/identity"
+	"github.com/dbunt1tled/fiber-go-api/internal/modules/impersonation"
+	"github.com/dbunt1tled/fiber-go-api/internal/modules/jobs"
+	"github.com/dbunt1tled/fiber-go-api/internal/modules/notification"
+	"github.com/dbunt1tled/fiber-go-api/internal/modules/realtime"
 	"github.com/dbunt1tled/fiber-go-api/internal/modules/user"
+	"github.com/dbunt1tled/fiber-go-api/internal/modules/webhook"
+	"github.com/dbunt1tled/fiber-go-api/pkg/audit"
+	"github.com/dbunt1tled/fiber-go-api/pkg/breach"
+	"github.com/dbunt1tled/fiber-go-api/pkg/event"
+	"github.com/dbunt1tled/fiber-go-api/pkg/filestore"
 	"github.com/dbunt1tled/fiber-go-api/pkg/hasher"
 	"github.com/dbunt1tled/fiber-go-api/pkg/http/er"
 	"github.com/dbunt1tled/fiber-go-api/pkg/http/middlewares"
 	"github.com/dbunt1tled/fiber-go-api/pkg/log"
+	"github.com/dbunt1tled/fiber-go-api/pkg/mailer"
+	"github.com/dbunt1tled/fiber-go-api/pkg/oauth"
 	"github.com/dbunt1tled/fiber-go-api/pkg/queue"
+	"github.com/dbunt1tled/fiber-go-api/pkg/sse"
 	"github.com/dbunt1tled/fiber-go-api/pkg/validation"
+	"github.com/dbunt1tled/fiber-go-api/pkg/validation/validators"
 	"github.com/gofiber/fiber/v3"
 	"github.com/gofiber/fiber/v3/middleware/compress"
 	"github.com/gofiber/fiber/v3/middleware/cors"
@@ -31,49 +51,324 @@ import (
 	"github.com/gofiber/fiber/v3/middleware/recover"
 	"github.com/gofiber/fiber/v3/middleware/static"
 	"github.com/gofiber/template/html/v3"
+	"github.com/redis/go-redis/v9"
 )
 
 type Application struct {
-	engine *fiber.App
-	cfg    *config.ServiceConfig
+	engine    *fiber.App
+	cfg       *config.ServiceConfig
+	tasks     *queue.Registry
+	periodic  *queue.PeriodicRegistry
+	scheduler *queue.Scheduler
+	hub       *sse.Hub
+	redis     redis.UniversalClient
 
 	MailService    *email.MailService
 	AuthController *auth.Controller
 	UserController *user.Controller
+	JobsController *jobs.Controller
 
-	AuthMiddleware *middlewares.AuthMiddleware
+	WebhookController  *webhook.Controller
+	RealtimeController *realtime.Controller
+
+	NotificationController *notification.Controller
+	FileController         *file.Controller
+	IdentityController     *identity.Controller
+	APIKeyController       *apikey.Controller
+	ClientController       *client.Controller
+
+	ImpersonationController *impersonation.Controller
+	AuditController         *auditlog.Controller
+
+	DevMailController *dev.MailController
+
+	AuthMiddleware      *middlewares.AuthMiddleware
+	SignedURLMiddleware *middlewares.SignedURLMiddleware
 }
 
 func NewApp(cfg *config.ServiceConfig) *Application {
 	engine := engineSetup()
-	validator, err := validation.Validator(cfg.DB.Pool())
+	validator, err := validation.Validator(cfg.DB.Pool(), passwordPolicy())
+	if err != nil {
+		panic(err)
+	}
+	events := event.NewBus()
+	tasks := queue.NewRegistry(cfg.Producer)
+	webhookService := webhook.NewWebhookService(cfg.DB.Pool(), tasks, webhook.Options{
+		Timeout:     config.Get().Webhook.Timeout,
+		MaxFailures: config.Get().Webhook.MaxFailures,
+		UserAgent:   config.Get().Name,
+	})
+	events.Subscribe(webhookService.Handle)
+	// one client serves the stream hub, magic links and oauth states
+	redisClient := config.Get().Redis.Options().Client()
+	hub := sse.NewHub(redisClient, sse.Options{
+		BufferSize: config.Get().SSE.BufferSize,
+		BufferTTL:  config.Get().SSE.BufferTTL,
+	})
+	signer := urlSigner()
+	view.SetURLSigner(signer)
+	realtimeService := realtime.NewRealtimeService(hub, signer, realtime.Options{
+		BaseURL:       config.Get().URL,
+		LinkExpire:    config.Get().SSE.LinkExpire,
+		LinkUserParam: middlewares.LinkUserParam,
+	})
+	events.Subscribe(realtimeService.Handle)
+	auditService := audit.NewAuditService(cfg.DB.Pool(), tasks, audit.Options{
+		Async: config.Get().Audit.Async,
+	})
+	userService := user.NewUserService(cfg.DB.Pool(), events, auditService)
+	jwtConfig := config.Get().Server.JWT
+	keys, err := hasher.NewKeySet(hasher.KeySetOptions{
+		Algorithm:  jwtConfig.Algorithm,
+		Secret:     jwtConfig.Secret,
+		PublicKey:  jwtConfig.PublicKey,
+		PrivateKey: jwtConfig.PrivateKey,
+		KeyID:      jwtConfig.KeyID,
+		Dir:        jwtConfig.Keys.Dir,
+		Files:      jwtConfig.Keys.Files,
+		Active:     jwtConfig.Keys.Active,
+		Retire:     jwtConfig.Keys.Retire,
+	})
 	if err != nil {
 		panic(err)
 	}
-	userService := user.NewUserService(cfg.DB.Pool())
+	passwordConfig := config.Get().Server.Password
 	hashService, err := hasher.NewHasher(
-		config.Get().Server.JWT.Algorithm,
-		config.Get().Server.JWT.PublicKey,
-		config.Get().Server.JWT.PrivateKey,
+		keys,
+		hasher.WithArgon(hasher.ArgonConfig{
+			Memory:      passwordConfig.Argon.Memory,
+			Iterations:  passwordConfig.Argon.Iterations,
+			Parallelism: passwordConfig.Argon.Parallelism,
+			SaltLength:  passwordConfig.Argon.SaltLength,
+			KeyLength:   passwordConfig.Argon.KeyLength,
+		}),
+		hasher.WithPepper(passwordConfig.Pepper),
 	)
 	if err != nil {
 		panic(err)
 	}
-	authService := auth.NewAuthService(hashService)
-	mailService := email.NewMailService(cfg.Mailer, config.Get().Mailer.Address)
-	mailServiceAsync := aemail.NewMailServiceAsync(cfg.Producer)
-	return &Application{
-		engine:         engine,
-		cfg:            cfg,
-		MailService:    mailService,
-		AuthController: auth.NewController(authService, userService, mailServiceAsync, validator),
-		UserController: user.NewUserController(userService, validator),
-		AuthMiddleware: middlewares.NewAuthMiddleware(authService, userService),
+	magicLinkConfig := config.Get().Server.Auth.MagicLink
+	magicLinks := auth.NewMagicLinks(redisClient, auth.MagicLinkOptions{
+		Expire: magicLinkConfig.Expire,
+		Limit:  magicLinkConfig.Limit,
+		Window: magicLinkConfig.Window,
+	})
+	authService := auth.NewAuthService(hashService, events, magicLinks, auditService)
+	var mailService *email.MailService
+	if cfg.Mailer != nil {
+		mailService = email.NewMailService(cfg.Mailer, config.Get().Mailer.Address)
+	}
+	mailServiceAsync := aemail.NewMailServiceAsync(queue.NewEmailTask(tasks, queue.NewEmailHandler(mailService)))
+	notificationService := notification.NewNotificationService(cfg.DB.Pool(), userService, mailServiceAsync, realtimeService)
+	events.Subscribe(notificationService.Handle)
+	fileService := file.NewFileService(cfg.DB.Pool(), userService, fileDriver(), signer, file.Options{
+		BaseURL:      config.Get().URL,
+		MaxSize:      int64(min(config.Get().Filestore.MaxSize, config.Get().Server.HTTP.BodyLimit)),
+		AllowedTypes: file.Types(config.Get().Filestore.AllowedTypes),
+		URLExpire:    config.Get().Filestore.URLExpire,
+	})
+	apiKeyService := apikey.NewAPIKeyService(cfg.DB.Pool(), hashService, apikey.Options{
+		Prefix: config.Get().Server.APIKey.Prefix,
+	})
+	clientService := client.NewClientService(cfg.DB.Pool(), hashService)
+	impersonationService := impersonation.NewImpersonationService(
+		cfg.DB.Pool(),
+		authService,
+		userService,
+		auditService,
+		config.Get().Server.Auth.ImpersonationExpire,
+	)
+	application := &Application{
+		engine: engine,
+		cfg:    cfg,
+		tasks:  tasks,
+		hub:    hub,
+		redis:  redisClient,
+		periodic: queue.NewPeriodicRegistry(
+			user.NewPurgeUnconfirmedTask(userService, config.Get().Server.JWT.Expire.Confirm),
+		),
+		MailService:       mailService,
+		AuthController:    auth.NewController(authService, userService, mailServiceAsync, validator),
+		UserController:    user.NewUserController(userService, validator),
+		WebhookController: webhook.NewController(webhookService, validator),
+		RealtimeController: realtime.NewController(realtimeService, userService, sse.StreamOptions{
+			Heartbeat: config.Get().SSE.Heartbeat,
+			Retry:     config.Get().SSE.Retry,
+		}, validator),
+		NotificationController: notification.NewController(notificationService, userService, validator),
+		FileController:         file.NewController(fileService, userService, validator),
+		APIKeyController:       apikey.NewController(apiKeyService, validator),
+		ClientController:       client.NewController(clientService, impersonationService, authService, userService, validator),
+
+		ImpersonationController: impersonation.NewController(impersonationService, userService, validator),
+		AuditController:         auditlog.NewController(auditService, validator),
+		AuthMiddleware: middlewares.NewAuthMiddleware(
+			authService,
+			userService,
+			apiKeyService,
+			clientService,
+			impersonationService,
+		),
+		SignedURLMiddleware: middlewares.NewSignedURLMiddleware(signer),
+	}
+
+	// sign in providers are only reached over HTTP, a worker neither builds nor depends on them
+	if cfg.Mode.ServesHTTP() {
+		identityService := identity.NewIdentityService(
+			cfg.DB.Pool(),
+			userService,
+			identity.NewStates(redisClient, config.Get().OAuth.StateExpire),
+			oauthProviders()...,
+		)
+		application.IdentityController = identity.NewController(identityService, authService, userService, validator)
+	}
+
+	if cfg.Inspector != nil {
+		application.JobsController = jobs.NewController(cfg.Inspector, validator)
+	}
+
+	if config.Get().IsDevelop() {
+		application.DevMailController = dev.NewMailController(
+			queue.NewEmailHandler(email.NewMailService(devMailSender(), config.Get().Mailer.Address)),
+			validator,
+		)
+	}
+
+	return application
+}
+
+// urlSigner falls back to a random key, so links signed by one process
+// can't be verified by another until keys are configured.
+func urlSigner() *hasher.URLSigner {
+	keys, err := hasher.ParseSignerKeys(config.Get().Server.URLSigner.Keys)
+	if err != nil {
+		panic(err)
+	}
+	if len(keys) == 0 {
+		// a key per process breaks links across prefork children, instances and restarts
+		if !config.Get().IsDevelop() {
+			panic("url signer: server.urlsigner.keys is required outside develop")
+		}
+		secret := make([]byte, 32) //nolint:mnd // 256 bits
+		if _, err = rand.Read(secret); err != nil {
+			panic(err)
+		}
+		keys = append(keys, hasher.SignerKey{ID: "ephemeral", Secret: secret})
+		log.Logger().Warnf("URL signer: no keys configured, signed links are valid for this process only")
+	}
+	signer, err := hasher.NewURLSigner(keys...)
+	if err != nil {
+		panic(err)
+	}
+	return signer
+}
+
+func passwordPolicy() *validators.PasswordPolicy {
+	cfg := config.Get().Server.Password.Policy
+	classes, err := validators.ParseClasses(cfg.Classes)
+	if err != nil {
+		panic(err)
+	}
+	policy := &validators.PasswordPolicy{
+		MinLength: cfg.MinLength,
+		MaxLength: cfg.MaxLength,
+		Classes:   classes,
+		MinScore:  cfg.MinScore,
+		Personal:  cfg.Personal,
+	}
+	switch {
+	case cfg.Breach.File != "":
+		if policy.Breached, err = breach.NewFileChecker(cfg.Breach.File); err != nil {
+			panic(err)
+		}
+	case cfg.Breach.URL != "":
+		policy.Breached = breach.NewHTTPChecker(cfg.Breach.URL, cfg.Breach.Timeout)
+	}
+	return policy
+}
+
+// oauthProviders builds the configured identity providers, skipping the ones without a client id
+// so the example environment can list them empty.
+func oauthProviders() []oauth.Provider {
+	providers := make([]oauth.Provider, 0, len(config.Get().OAuth.Providers))
+	for name, cfg := range config.Get().OAuth.Providers {
+		if cfg.ClientID == "" {
+			continue
+		}
+		var scopes []string
+		for _, scope := range strings.Split(cfg.Scopes, ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				scopes = append(scopes, scope)
+			}
+		}
+		provider, err := oauth.New(oauth.Options{
+			Name:         name,
+			Driver:       cfg.Driver,
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  fmt.Sprintf("%s/api/auth/oauth/%s/callback", config.Get().URL, name),
+			Issuer:       cfg.Issuer,
+			Scopes:       scopes,
+			AuthURL:      cfg.AuthURL,
+			TokenURL:     cfg.TokenURL,
+			APIURL:       cfg.APIURL,
+		})
+		if err != nil {
+			panic(err)
+		}
+		providers = append(providers, provider)
+	}
+	return providers
+}
+
+func fileDriver() filestore.Driver {
+	cfg := config.Get().Filestore
+	driver, err := filestore.New(filestore.Options{
+		Driver: cfg.Driver,
+		Dir:    cfg.Local.Dir,
+		S3: filestore.S3Options{
+			Endpoint:  cfg.S3.Endpoint,
+			Region:    cfg.S3.Region,
+			Bucket:    cfg.S3.Bucket,
+			AccessKey: cfg.S3.AccessKey,
+			SecretKey: cfg.S3.SecretKey,
+			UseSSL:    cfg.S3.UseSSL,
+			PathStyle: cfg.S3.PathStyle,
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+	return driver
+}
+
+// devMailSender never delivers real mail: previews go to the file transport
+// when a directory is configured and to the log otherwise.
+func devMailSender() mailer.Sender {
+	cfg := config.Get().Mailer
+	if cfg.Dir != "" {
+		cfg.Transport = mailer.TransportFile
+	} else {
+		cfg.Transport = mailer.TransportLog
+	}
+	return NewMailSender(cfg)
+}
+
+func NewMailSender(cfg config.MailerConfig) mailer.Sender {
+	switch cfg.Transport {
+	case mailer.TransportFile:
+		return mailer.NewFileMailer(cfg.Dir)
+	case mailer.TransportLog:
+		return mailer.NewLogMailer()
+	default:
+		return mailer.NewMailer(cfg.Host, cfg.Port, cfg.Username, cfg.Password, cfg.Address)
 	}
 }
 
 func engineSetup() *fiber.App {
 	eng := html.New("./resources/templates", ".gohtml")
+	eng.AddFuncMap(view.Funcs())
 
 	engine := fiber.New(fiber.Config{
 		AppName:       config.Get().Name,
@@ -109,11 +404,18 @@ func engineSetup() *fiber.App {
 
 	// Middleware setup
 	engine.Use(recover.New())
+	engine.Use(middlewares.NewRequestID())
+	engine.Use(middlewares.NewAudit())
 	engine.Use(middlewares.NewLog(func(c fiber.Ctx) bool {
 		return false
 	}))
 	engine.Use(helmet.New())
-	engine.Use(compress.New())
+	engine.Use(compress.New(compress.Config{
+		// event streams must be flushed as they are written
+		Next: func(c fiber.Ctx) bool {
+			return strings.Contains(c.Get(fiber.HeaderAccept), "text/event-stream")
+		},
+	}))
 	if config.Get().Profiling {
 		engine.Use(pprof.New())
 	}
@@ -149,7 +451,6 @@ func (a *Application) Engine() *fiber.App {
 }
 
 func (a *Application) serveWithGraceFullShutdown(ctx context.Context, addr string) error {
-	var wg sync.WaitGroup
 	c, stop := signal.NotifyContext(
 		ctx,
 		syscall.SIGINT,
@@ -159,27 +460,40 @@ func (a *Application) serveWithGraceFullShutdown(ctx context.Context, addr strin
 		os.Interrupt,
 	)
 	defer stop()
-	emailHandler := queue.NewEmailHandler(a.MailService)
-	mux := queue.NewQueueMux(emailHandler)
 
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		if err := a.cfg.Consumer.Run(mux); err != nil {
-			log.Logger().Error("Consumer can't run.", err)
+	if a.cfg.Mode.ProcessesJobs() {
+		mux := queue.NewQueueMux(a.tasks, a.periodic)
+		if err := a.cfg.Consumer.Start(mux); err != nil {
+			return fmt.Errorf("consumer can't run: %w", err)
 		}
-	}()
+		if config.Get().Scheduler.Enabled {
+			opts, err := config.Get().Scheduler.Options()
+			if err != nil {
+				return fmt.Errorf("scheduler can't run: %w", err)
+			}
+			a.scheduler = queue.NewScheduler(config.Get().Redis.Options(), a.periodic, opts)
+			a.scheduler.Start(c)
+		}
+	}
+
+	server := a.engine
+	if a.cfg.Mode.ServesHTTP() {
+		a.hub.Start(c)
+	} else {
+		server = a.healthEngine()
+		addr = config.Get().Worker.Health.Host + ":" + strconv.Itoa(config.Get().Worker.Health.Port)
+	}
 
 	go func() {
-		if err := a.engine.Listen(addr, fiber.ListenConfig{
-			EnablePrefork: config.Get().Server.HTTP.Prefork,
+		if err := server.Listen(addr, fiber.ListenConfig{
+			EnablePrefork: a.cfg.Mode.ServesHTTP() && config.Get().Server.HTTP.Prefork,
 			CertFile:      config.Get().Server.HTTP.TLS.CertFile,
 			CertKeyFile:   config.Get().Server.HTTP.TLS.Keyfile,
 		}); err != nil {
 			panic(err)
 		}
 	}()
-	a.engine.Hooks().OnPostShutdown(func(err error) error {
+	server.Hooks().OnPostShutdown(func(err error) error {
 		if err != nil {
 			log.Logger().Errorf("Shutdown error: %v", err)
 		} else {
@@ -188,22 +502,49 @@ func (a *Application) serveWithGraceFullShutdown(ctx context.Context, addr strin
 		return nil
 	})
 	<-c.Done()
-	var cancel context.CancelFunc
-	c, cancel = context.WithTimeout(context.Background(), 10*time.Second) //nolint:mnd // 10 seconds timeout
-	defer cancel()
 
 	log.Logger().Warn("Quit: shutting down ...")
 	defer log.Logger().Warn("｡◕‿‿◕｡ Quit: shutdown completed")
-	log.Logger().Warn("㋡ Quit: closing database connection")
-	a.cfg.DB.Close()
-	log.Logger().Warn("㋡ Quit: closing mailer connection")
-	_ = a.cfg.Mailer.Close()
-	log.Logger().Warn("㋡ Quit: closing producer")
-	_ = a.cfg.Producer.Close()
-	log.Logger().Warn("㋡ Quit: closing consumer")
-	a.cfg.Consumer.Close()
-	wg.Wait()
+	// open event streams never finish by themselves, close them before the server waits for requests
+	log.Logger().Warn("㋡ Quit: closing event streams")
+	_ = a.hub.Close()
+	err := server.ShutdownWithTimeout(10 * time.Second) //nolint:mnd // 10 seconds timeout
+	a.close()
+
+	return err
+}
+
+// close releases only the resources opened for the current mode.
+func (a *Application) close() {
+	if a.scheduler != nil {
+		log.Logger().Warn("㋡ Quit: closing scheduler")
+		_ = a.scheduler.Close()
+	}
+	if a.cfg.Consumer != nil {
+		log.Logger().Warn("㋡ Quit: closing consumer")
+		a.cfg.Consumer.Close()
+	}
+	if a.cfg.Inspector != nil {
+		log.Logger().Warn("㋡ Quit: closing queue inspector")
+		_ = a.cfg.Inspector.Close()
+	}
+	if a.cfg.Producer != nil {
+		log.Logger().Warn("㋡ Quit: closing producer")
+		_ = a.cfg.Producer.Close()
+	}
+	// running tasks publish events and store links through the shared client until the consumer drains
+	if a.redis != nil {
+		log.Logger().Warn("㋡ Quit: closing redis connection")
+		_ = a.redis.Close()
+	}
+	if a.cfg.Mailer != nil {
+		log.Logger().Warn("㋡ Quit: closing mailer connection")
+		_ = a.cfg.Mailer.Close()
+	}
+	if a.cfg.DB != nil {
+		log.Logger().Warn("㋡ Quit: closing database connection")
+		a.cfg.DB.Close()
+	}
 	log.Logger().Warn("㋡ Quit: closing logger")
 	_ = log.Close()
-	return a.engine.Shutdown()
 }
@@ -0,0 +1,33 @@
+package app
+
+import (
+	"context"
+
+	"github.com/dbunt1tled/fiber-go-api/internal/config"
+	"github.com/dbunt1tled/fiber-go-api/pkg/db"
+	"github.com/dbunt1tled/fiber-go-api/pkg/queue"
+)
+
+// Bootstrap opens only the resources the given mode needs:
+// the API side never starts a consumer and never dials the mailer,
+// the worker side never starts the HTTP server.
+func Bootstrap(ctx context.Context, mode config.Mode) *config.ServiceConfig {
+	cfg := config.NewServiceConfig(
+		mode,
+		db.New(ctx, config.Get().DB.Main.DSN),
+		nil,
+		queue.NewQueueProducer(config.Get().Redis.Options(), config.Get().Queue.TaskOptions()),
+		nil,
+	)
+
+	if mode.ServesHTTP() {
+		cfg.Inspector = queue.NewInspector(config.Get().Redis.Options())
+	}
+
+	if mode.ProcessesJobs() {
+		cfg.Mailer = NewMailSender(config.Get().Mailer)
+		cfg.Consumer = queue.NewQueueConsumer(config.Get().Redis.Options(), config.Get().Queue.ConsumerOptions())
+	}
+
+	return cfg
+}
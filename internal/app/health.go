@@ -0,0 +1,38 @@
+package app
+
+import (
+	"github.com/dbunt1tled/fiber-go-api/internal/config"
+	"github.com/gofiber/fiber/v3"
+)
+
+// healthEngine is the only HTTP surface of a worker-only process.
+func (a *Application) healthEngine() *fiber.App {
+	engine := fiber.New(fiber.Config{
+		AppName:      config.Get().Name + " worker",
+		ServerHeader: config.Get().Name,
+	})
+	engine.Get("/health", a.Health)
+
+	return engine
+}
+
+func (a *Application) Health(c fiber.Ctx) error {
+	status := map[string]string{
+		"mode": string(a.cfg.Mode),
+		"db":   "ok",
+	}
+	code := fiber.StatusOK
+	if err := a.cfg.DB.Pool().Ping(c.Context()); err != nil {
+		status["db"] = err.Error()
+		code = fiber.StatusServiceUnavailable
+	}
+	if a.cfg.Consumer != nil {
+		status["queue"] = "ok"
+		if err := a.cfg.Consumer.Ping(); err != nil {
+			status["queue"] = err.Error()
+			code = fiber.StatusServiceUnavailable
+		}
+	}
+
+	return c.Status(code).JSON(status)
+}
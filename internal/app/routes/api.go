@@ -5,6 +5,7 @@ import (
 
 	"github.com/dbunt1tled/fiber-go-api/internal/app"
 	"github.com/dbunt1tled/fiber-go-api/internal/config"
+	"github.com/dbunt1tled/fiber-go-api/internal/modules/auth"
 	"github.com/gofiber/fiber/v3"
 )
 
@@ -21,17 +22,118 @@ func apiRoutes(api fiber.Router, a *app.Application) {
 	authGroup := api.Group("auth")
 	apiAuthRoutes(authGroup, a)
 
+	// browsers can't set headers on an EventSource, they connect with a signed link instead
+	api.Get("/events", a.AuthMiddleware.LinkOrAuth(a.SignedURLMiddleware), a.RealtimeController.Stream)
+	api.Post("/events/link", a.AuthMiddleware.Auth, a.RealtimeController.Link)
+	api.Delete("/impersonation", a.AuthMiddleware.Auth, a.ImpersonationController.Stop)
+
+	// signed links stand in for authentication
+	api.Get("/files/:id/download", a.SignedURLMiddleware.Verify, a.FileController.Download)
+	api.Get("/notifications/unsubscribe/:id/:type", a.SignedURLMiddleware.Verify, a.NotificationController.Unsubscribe)
+	fileGroup := api.Group("files", a.AuthMiddleware.Auth)
+	apiFileRoutes(fileGroup, a)
+
 	userGroup := api.Group("users", a.AuthMiddleware.Auth)
 	apiUserRoutes(userGroup, a)
+
+	notificationGroup := api.Group("notifications", a.AuthMiddleware.Auth)
+	apiNotificationRoutes(notificationGroup, a)
+
+	adminGroup := api.Group("admin", a.AuthMiddleware.Auth, a.AuthMiddleware.Admin, a.AuthMiddleware.Scope(auth.ScopeAdmin))
+	apiAdminRoutes(adminGroup, a)
+}
+
+func apiAdminRoutes(adminGroup fiber.Router, a *app.Application) {
+	if a.JobsController != nil {
+		queueGroup := adminGroup.Group("queues")
+		queueGroup.Get("/", a.JobsController.Queues)
+		queueGroup.Post("/tasks/:id/cancel", a.JobsController.Cancel)
+		queueGroup.Get("/:queue", a.JobsController.Queue)
+		queueGroup.Post("/:queue/pause", a.JobsController.Pause)
+		queueGroup.Post("/:queue/unpause", a.JobsController.Unpause)
+		queueGroup.Get("/:queue/tasks", a.JobsController.Tasks)
+		queueGroup.Get("/:queue/tasks/:id", a.JobsController.Task)
+		queueGroup.Post("/:queue/tasks/:id/run", a.JobsController.RunTask)
+		queueGroup.Delete("/:queue/tasks/:id", a.JobsController.DeleteTask)
+		queueGroup.Post("/:queue/archived/run", a.JobsController.RunArchived)
+		queueGroup.Delete("/:queue/archived", a.JobsController.DeleteArchived)
+	}
+
+	adminGroup.Post("/events", a.RealtimeController.Message)
+	adminGroup.Post("/notifications", a.NotificationController.Send)
+
+	webhookGroup := adminGroup.Group("webhooks")
+	webhookGroup.Get("/", a.WebhookController.List)
+	webhookGroup.Post("/", a.WebhookController.Create)
+	webhookGroup.Get("/:id", a.WebhookController.Show)
+	webhookGroup.Patch("/:id", a.WebhookController.Update)
+	webhookGroup.Delete("/:id", a.WebhookController.Delete)
+	webhookGroup.Get("/:id/deliveries", a.WebhookController.Deliveries)
+	webhookGroup.Post("/:id/deliveries/:deliveryId/redeliver", a.WebhookController.Redeliver)
+
+	adminGroup.Get("/audit", a.AuditController.List)
+	adminGroup.Post("/users/:id/impersonate", a.ImpersonationController.Start)
+	adminGroup.Get("/impersonations", a.ImpersonationController.List)
+	adminGroup.Delete("/impersonations/:id", a.ImpersonationController.StopByID)
+
+	clientGroup := adminGroup.Group("clients")
+	clientGroup.Get("/", a.ClientController.List)
+	clientGroup.Post("/", a.ClientController.Create)
+	clientGroup.Delete("/:id", a.ClientController.Deactivate)
+}
+
+func apiNotificationRoutes(notificationGroup fiber.Router, a *app.Application) {
+	notificationGroup.Get("/", a.NotificationController.List)
+	notificationGroup.Post("/read-all", a.NotificationController.ReadAll)
+	notificationGroup.Get("/preferences", a.NotificationController.Preferences)
+	notificationGroup.Put("/preferences", a.NotificationController.UpdatePreferences)
+	notificationGroup.Post("/:id/read", a.NotificationController.Read)
+	notificationGroup.Post("/:id/unread", a.NotificationController.Unread)
+	notificationGroup.Delete("/:id", a.NotificationController.Delete)
+}
+
+func apiFileRoutes(fileGroup fiber.Router, a *app.Application) {
+	fileGroup.Get("/", a.FileController.List)
+	fileGroup.Post("/", a.FileController.Upload)
+	fileGroup.Get("/:id", a.FileController.Show)
+	fileGroup.Delete("/:id", a.FileController.Delete)
 }
 
 func apiUserRoutes(userGroup fiber.Router, a *app.Application) {
 	userGroup.Get("/", a.UserController.List)
+	// sensitive actions stay with the account owner, not an admin impersonating them
+	userGroup.Post("/me/email", a.AuthMiddleware.NotImpersonating, a.AuthController.ChangeEmail)
+	userGroup.Get("/me/identities", a.IdentityController.List)
+	userGroup.Post("/me/identities/:provider", a.AuthMiddleware.NotImpersonating, a.IdentityController.Link)
+	userGroup.Delete("/me/identities/:id", a.AuthMiddleware.NotImpersonating, a.IdentityController.Unlink)
+	userGroup.Get("/me/api-keys", a.APIKeyController.List)
+	userGroup.Post("/me/api-keys", a.AuthMiddleware.NotImpersonating, a.APIKeyController.Create)
+	userGroup.Delete("/me/api-keys/:id", a.AuthMiddleware.NotImpersonating, a.APIKeyController.Revoke)
+	userGroup.Put("/me/avatar", a.FileController.SetAvatar)
+	userGroup.Delete("/me/avatar", a.FileController.RemoveAvatar)
+	userGroup.Get("/:id/avatar", a.FileController.Avatar)
 }
 
 func apiAuthRoutes(authGroup fiber.Router, a *app.Application) {
 	authGroup.Post("/login", a.AuthController.Login)
 	authGroup.Post("/refresh", a.AuthController.Refresh)
+	authGroup.Post("/logout", a.AuthMiddleware.Auth, a.AuthController.Logout)
 	authGroup.Post("/register", a.AuthController.Register)
-	authGroup.Get("/confirm/:token", a.AuthController.Confirm)
+	authGroup.Post("/token", a.ClientController.Token)
+	authGroup.Post("/introspect", a.AuthMiddleware.Client, a.AuthMiddleware.Scope(auth.ScopeIntrospect), a.ClientController.Introspect)
+	if config.Get().Server.Auth.MagicLink.Enabled {
+		authGroup.Post("/magic-link", a.AuthController.MagicLink)
+		authGroup.Get("/magic-link/:token", a.AuthController.MagicLinkForm)
+		authGroup.Post("/magic-link/:token", a.AuthController.MagicLinkLogin)
+	}
+	authGroup.Post("/confirm/resend", a.AuthController.ResendConfirm)
+	authGroup.Get("/confirm/:id/:fingerprint", a.SignedURLMiddleware.Verify, a.AuthController.Confirm)
+	authGroup.Get("/oauth", a.IdentityController.Providers)
+	authGroup.Get("/oauth/:provider", a.IdentityController.Login)
+	authGroup.Get("/oauth/:provider/callback", a.IdentityController.Callback)
+	authGroup.Post("/password/forgot", a.AuthController.ForgotPassword)
+	authGroup.Get("/password/reset/:id/:fingerprint", a.SignedURLMiddleware.Verify, a.AuthController.ResetPasswordForm)
+	authGroup.Post("/password/reset/:id/:fingerprint", a.SignedURLMiddleware.Verify, a.AuthController.ResetPassword)
+	authGroup.Get("/email/confirm/:id/:fingerprint", a.SignedURLMiddleware.Verify, a.AuthController.ConfirmEmail)
+	authGroup.Get("/email/cancel/:id/:fingerprint", a.SignedURLMiddleware.Verify, a.AuthController.CancelEmail)
 }
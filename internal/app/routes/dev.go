@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/dbunt1tled/fiber-go-api/internal/app"
+	"github.com/gofiber/fiber/v3"
+)
+
+func DevRoutes(application *app.Application) {
+	if application.DevMailController == nil {
+		return
+	}
+	app := application.Engine()
+	dev := app.Group("dev")
+	devMailRoutes(dev.Group("mail"), application)
+}
+
+func devMailRoutes(mailGroup fiber.Router, a *app.Application) {
+	mailGroup.Get("/", a.DevMailController.List)
+	mailGroup.Get("/:name", a.DevMailController.Show)
+	mailGroup.Get("/:name/html", a.DevMailController.HTML)
+	mailGroup.Get("/:name/text", a.DevMailController.Text)
+	mailGroup.Post("/:name/send", a.DevMailController.Send)
+}
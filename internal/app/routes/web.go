@@ -15,6 +15,8 @@ func WebRoutes(application *app.Application) {
 	app.Get("/", func(c fiber.Ctx) error {
 		return c.Render("general/home.gohtml", view.MakeTemplateData(map[string]any{}))
 	})
+	app.Get("/health", application.Health)
+	app.Get("/.well-known/jwks.json", application.AuthController.JWKS)
 	app.Use(favicon.New(favicon.Config{
 		URL:  "/favicon.ico",
 		File: fmt.Sprintf("%s/images/favicon.ico", config.Get().Static.Directory),
@@ -1,28 +1,104 @@
 package config
 
 import (
+	"time"
+
 	"github.com/dbunt1tled/fiber-go-api/pkg/db"
+	"github.com/dbunt1tled/fiber-go-api/pkg/f"
 	"github.com/dbunt1tled/fiber-go-api/pkg/mailer"
 	"github.com/dbunt1tled/fiber-go-api/pkg/queue"
 )
 
 type ServiceConfig struct {
+	Mode     Mode
 	DB       *db.DB
-	Mailer   *mailer.Mailer
+	Mailer   mailer.Sender
 	Producer *queue.Producer
 	Consumer *queue.Consumer
+
+	Inspector *queue.Inspector
 }
 
 func NewServiceConfig(
+	mode Mode,
 	db *db.DB,
-	mail *mailer.Mailer,
+	mail mailer.Sender,
 	producer *queue.Producer,
 	consumer *queue.Consumer,
 ) *ServiceConfig {
 	return &ServiceConfig{
+		Mode:     mode,
 		DB:       db,
 		Mailer:   mail,
 		Producer: producer,
 		Consumer: consumer,
 	}
 }
+
+func (c RedisConfig) Options() queue.RedisOptions {
+	return queue.RedisOptions{
+		Addr:          c.Addr,
+		Username:      c.Username,
+		Password:      c.Password,
+		DB:            c.DB,
+		TLS:           c.TLS.Enabled,
+		TLSServerName: c.TLS.ServerName,
+		TLSInsecure:   c.TLS.Insecure,
+	}
+}
+
+func (c QueueConfig) ConsumerOptions() queue.ConsumerOptions {
+	return queue.ConsumerOptions{
+		Concurrency:     c.Concurrency,
+		Queues:          c.Queues,
+		StrictPriority:  c.Strict,
+		ShutdownTimeout: c.ShutdownTimeout,
+		Retry:           c.Retry.Policy(),
+		Tasks:           c.TaskOptions(),
+	}
+}
+
+func (c QueueConfig) TaskOptions() map[string]queue.TaskOptions {
+	tasks := make(map[string]queue.TaskOptions, len(c.Tasks))
+	for name, task := range c.Tasks {
+		opts := queue.TaskOptions{
+			Queue:    task.Queue,
+			MaxRetry: task.MaxRetry,
+			Timeout:  task.Timeout,
+			Unique:   task.Unique,
+		}
+		if task.Retry != nil {
+			opts.Retry = f.Pointer(task.Retry.Policy())
+		}
+		tasks[name] = opts
+	}
+	return tasks
+}
+
+func (c QueueRetryConfig) Policy() queue.RetryPolicy {
+	return queue.RetryPolicy{
+		Base:   c.Base,
+		Max:    c.Max,
+		Jitter: c.Jitter,
+	}
+}
+
+func (c SchedulerConfig) Options() (queue.SchedulerOptions, error) {
+	location, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return queue.SchedulerOptions{}, err
+	}
+	overrides := make(map[string]queue.PeriodicOverride, len(c.Tasks))
+	for name, task := range c.Tasks {
+		overrides[name] = queue.PeriodicOverride{
+			Cron:     task.Cron,
+			Disabled: task.Disabled,
+		}
+	}
+
+	return queue.SchedulerOptions{
+		Location:  location,
+		LeaderTTL: c.LeaderTTL,
+		Overrides: overrides,
+	}, nil
+}
@@ -39,10 +39,67 @@ func load() error {
 		"name":                  "fiber-api",
 		"env":                   "develop",
 		"debug":                 false,
+		"mode":                  "all",
 		"server.http.host":      "localhost",
 		"server.http.port":      8080, //nolint:mnd // default port
 		"server.http.timeout":   "5s",
 		"server.http.bodylimit": 4 * 1024 * 1024, //nolint:mnd // 4MB
+		"mailer.transport":      "smtp",
+		"worker.health.host":    "localhost",
+		"worker.health.port":    8081, //nolint:mnd // default health port
+		"scheduler.enabled":     true,
+		"scheduler.leaderttl":   "30s",
+		"scheduler.timezone":    "UTC",
+		"queue.concurrency":     3, //nolint:mnd // default workers
+		"queue.queues.emails":   2, //nolint:mnd // 2 + 1 = 3
+		"queue.queues.default":  1,
+		"queue.queues.webhooks": 1,
+		"queue.shutdowntimeout": "8s",
+		"queue.retry.base":      "10s",
+		"queue.retry.max":       "10m",
+		"queue.retry.jitter":    0.2, //nolint:mnd // ±20%
+		"mailer.dir":            "./storage/mail",
+		"webhook.timeout":       "10s",
+		"webhook.maxfailures":   10, //nolint:mnd // failed deliveries before disabling
+		"sse.heartbeat":         "15s",
+		"sse.retry":             "3s",
+		"sse.buffersize":        100, //nolint:mnd // messages kept per user for resume
+		"sse.bufferttl":         "5m",
+		"sse.linkexpire":        "1m",
+		"filestore.driver":      "local",
+		"filestore.local.dir":   "./storage/files",
+		"filestore.maxsize":     2 * 1024 * 1024, //nolint:mnd // 2MB
+		"filestore.urlexpire":   "15m",
+		"filestore.s3.usessl":   true,
+		"oauth.stateexpire":     "10m",
+		"audit.async":           true,
+
+		"filestore.allowedtypes":    "image/png,image/jpeg,image/gif,image/webp,application/pdf,text/plain",
+		"server.urlsigner.expire":   "1h",
+		"server.auth.confirmresend": "2m",
+		"server.apikey.prefix":      "fga",
+
+		"server.auth.magiclink.expire": "15m",
+		"server.auth.magiclink.limit":  5, //nolint:mnd // requests per window
+		"server.auth.magiclink.window": "1h",
+
+		"server.auth.impersonationexpire": "15m",
+
+		"server.password.argon.memory":      64 * 1024, //nolint:mnd // 64MB
+		"server.password.argon.iterations":  3,         //nolint:mnd // argon defaults
+		"server.password.argon.parallelism": 2,         //nolint:mnd // argon defaults
+		"server.password.argon.saltlength":  16,        //nolint:mnd // argon defaults
+		"server.password.argon.keylength":   32,        //nolint:mnd // argon defaults
+
+		"server.password.policy.minlength":      8,   //nolint:mnd // NIST SP 800-63B minimum
+		"server.password.policy.maxlength":      128, //nolint:mnd // room for passphrases
+		"server.password.policy.classes":        "upper,lower,number,special",
+		"server.password.policy.personal":       true,
+		"server.password.policy.breach.timeout": "2s",
+
+		"queue.tasks.webhookdeliver.retry.base":   "30s",
+		"queue.tasks.webhookdeliver.retry.max":    "6h",
+		"queue.tasks.webhookdeliver.retry.jitter": 0.2, //nolint:mnd // ±20%
 	}, "."), nil); err != nil {
 		return fmt.Errorf("error loading confmap: %w", err)
 	}
@@ -66,6 +123,11 @@ func load() error {
 		return err
 	}
 
+	// an unknown mode would start a process that neither serves HTTP nor processes jobs
+	if !c.Mode.Valid() {
+		return fmt.Errorf("unknown mode %q, expected %s, %s or %s", c.Mode, ModeAll, ModeAPI, ModeWorker)
+	}
+
 	cfg.Store(&c)
 
 	return nil
@@ -5,22 +5,125 @@ import (
 	"time"
 )
 
+type Mode string
+
+const (
+	ModeAll    Mode = "all"
+	ModeAPI    Mode = "api"
+	ModeWorker Mode = "worker"
+)
+
+func (m Mode) Valid() bool {
+	return m == ModeAll || m == ModeAPI || m == ModeWorker
+}
+
+func (m Mode) ServesHTTP() bool {
+	return m == ModeAll || m == ModeAPI
+}
+
+func (m Mode) ProcessesJobs() bool {
+	return m == ModeAll || m == ModeWorker
+}
+
+const (
+	EnvDevelop = "develop"
+	EnvDev     = "dev"
+	EnvProd    = "prod"
+)
+
 type Config struct {
-	Name      string       `koanf:"name"`
-	URL       string       `koanf:"url"`
-	Env       string       `koanf:"env"`
-	Debug     bool         `koanf:"debug"`
-	Profiling bool         `koanf:"profiling"`
-	Server    ServerConfig `koanf:"server"`
-	DB        DBConfig     `koanf:"db"`
-	Redis     RedisConfig  `koanf:"redis"`
-	Log       LogConfig    `koanf:"log"`
-	Mailer    MailerConfig `koanf:"mailer"`
-	Static    StaticConfig `koanf:"static"`
+	Name      string          `koanf:"name"`
+	URL       string          `koanf:"url"`
+	Env       string          `koanf:"env"`
+	Mode      Mode            `koanf:"mode"`
+	Debug     bool            `koanf:"debug"`
+	Profiling bool            `koanf:"profiling"`
+	Server    ServerConfig    `koanf:"server"`
+	DB        DBConfig        `koanf:"db"`
+	Redis     RedisConfig     `koanf:"redis"`
+	Queue     QueueConfig     `koanf:"queue"`
+	Worker    WorkerConfig    `koanf:"worker"`
+	Scheduler SchedulerConfig `koanf:"scheduler"`
+	Webhook   WebhookConfig   `koanf:"webhook"`
+	SSE       SSEConfig       `koanf:"sse"`
+	Filestore FilestoreConfig `koanf:"filestore"`
+	OAuth     OAuthConfig     `koanf:"oauth"`
+	Audit     AuditConfig     `koanf:"audit"`
+	Log       LogConfig       `koanf:"log"`
+	Mailer    MailerConfig    `koanf:"mailer"`
+	Static    StaticConfig    `koanf:"static"`
+}
+
+func (c *Config) IsDevelop() bool {
+	return c.Env == EnvDevelop || c.Env == EnvDev
 }
+
 type ServerConfig struct {
-	HTTP HTTPConfig `koanf:"http"`
-	JWT  JWTConfig  `koanf:"jwt"`
+	HTTP      HTTPConfig      `koanf:"http"`
+	JWT       JWTConfig       `koanf:"jwt"`
+	URLSigner URLSignerConfig `koanf:"urlsigner"`
+	Password  PasswordConfig  `koanf:"password"`
+	Auth      AuthConfig      `koanf:"auth"`
+	APIKey    APIKeyConfig    `koanf:"apikey"`
+}
+
+type APIKeyConfig struct {
+	// Prefix starts every generated key, e.g. `<prefix>_<lookup>_<secret>`.
+	Prefix string `koanf:"prefix"`
+}
+
+type AuthConfig struct {
+	// ConfirmResend is the least time between two confirmation mails to the same address.
+	ConfirmResend time.Duration   `koanf:"confirmresend"`
+	MagicLink     MagicLinkConfig `koanf:"magiclink"`
+	// ImpersonationExpire is how long the token of an admin impersonating a user lives.
+	ImpersonationExpire time.Duration `koanf:"impersonationexpire"`
+}
+
+// MagicLinkConfig controls passwordless login, Limit requests per Window are allowed per address and per IP.
+type MagicLinkConfig struct {
+	Enabled bool          `koanf:"enabled"`
+	Expire  time.Duration `koanf:"expire"`
+	Limit   int           `koanf:"limit"`
+	Window  time.Duration `koanf:"window"`
+}
+
+type PasswordConfig struct {
+	Argon ArgonConfig `koanf:"argon"`
+	// Pepper is mixed into new hashes; unpeppered hashes upgrade on login, but hashes of another pepper stop verifying.
+	Pepper string               `koanf:"pepper"`
+	Policy PasswordPolicyConfig `koanf:"policy"`
+}
+
+type PasswordPolicyConfig struct {
+	MinLength int `koanf:"minlength"`
+	MaxLength int `koanf:"maxlength"`
+	// Classes is a comma separated subset of upper, lower, number and special.
+	Classes  string       `koanf:"classes"`
+	MinScore int          `koanf:"minscore"`
+	Personal bool         `koanf:"personal"`
+	Breach   BreachConfig `koanf:"breach"`
+}
+
+// BreachConfig enables the breached password check through a local sorted hash file or a range endpoint.
+type BreachConfig struct {
+	File    string        `koanf:"file"`
+	URL     string        `koanf:"url"`
+	Timeout time.Duration `koanf:"timeout"`
+}
+
+type ArgonConfig struct {
+	Memory      uint32 `koanf:"memory"`
+	Iterations  uint32 `koanf:"iterations"`
+	Parallelism uint8  `koanf:"parallelism"`
+	SaltLength  uint32 `koanf:"saltlength"`
+	KeyLength   uint32 `koanf:"keylength"`
+}
+
+type URLSignerConfig struct {
+	// Keys are `kid:secret` pairs separated by commas, the first one signs.
+	Keys   string        `koanf:"keys"`
+	Expire time.Duration `koanf:"expire"`
 }
 
 type HTTPConfig struct {
@@ -42,9 +145,20 @@ type JWTConfig struct {
 	PublicKey  string     `koanf:"public"`
 	PrivateKey string     `koanf:"private"`
 	Algorithm  string     `koanf:"algorithm"`
+	Secret     string     `koanf:"secret"`
+	Audience   string     `koanf:"audience"`
+	KeyID      string     `koanf:"kid"`
+	Keys       JWTKeys    `koanf:"keys"`
 	Expire     ExpireConf `koanf:"expire"`
 }
 
+type JWTKeys struct {
+	Dir    string `koanf:"dir"`
+	Files  string `koanf:"files"`
+	Active string `koanf:"active"`
+	Retire string `koanf:"retire"`
+}
+
 type ExpireConf struct {
 	Access  time.Duration `koanf:"access"`
 	Refresh time.Duration `koanf:"refresh"`
@@ -67,7 +181,124 @@ type DBConfig struct {
 }
 
 type RedisConfig struct {
-	Addr string `koanf:"addr"`
+	Addr     string         `koanf:"addr"`
+	Username string         `koanf:"username"`
+	Password string         `koanf:"password"`
+	DB       int            `koanf:"db"`
+	TLS      RedisTLSConfig `koanf:"tls"`
+}
+
+type RedisTLSConfig struct {
+	Enabled    bool   `koanf:"enabled"`
+	ServerName string `koanf:"servername"`
+	Insecure   bool   `koanf:"insecure"`
+}
+
+type QueueConfig struct {
+	Concurrency     int                        `koanf:"concurrency"`
+	Queues          map[string]int             `koanf:"queues"`
+	Strict          bool                       `koanf:"strict"`
+	ShutdownTimeout time.Duration              `koanf:"shutdowntimeout"`
+	Retry           QueueRetryConfig           `koanf:"retry"`
+	Tasks           map[string]QueueTaskConfig `koanf:"tasks"`
+}
+
+type SchedulerConfig struct {
+	Enabled   bool                           `koanf:"enabled"`
+	LeaderTTL time.Duration                  `koanf:"leaderttl"`
+	Timezone  string                         `koanf:"timezone"`
+	Tasks     map[string]SchedulerTaskConfig `koanf:"tasks"`
+}
+
+// SchedulerTaskConfig is keyed by the periodic task name.
+type SchedulerTaskConfig struct {
+	Cron     string `koanf:"cron"`
+	Disabled bool   `koanf:"disabled"`
+}
+
+type WebhookConfig struct {
+	Timeout     time.Duration `koanf:"timeout"`
+	MaxFailures int           `koanf:"maxfailures"`
+}
+
+type SSEConfig struct {
+	Heartbeat  time.Duration `koanf:"heartbeat"`
+	Retry      time.Duration `koanf:"retry"`
+	BufferSize int           `koanf:"buffersize"`
+	BufferTTL  time.Duration `koanf:"bufferttl"`
+	LinkExpire time.Duration `koanf:"linkexpire"`
+}
+
+type FilestoreConfig struct {
+	Driver       string           `koanf:"driver"`
+	Local        LocalStoreConfig `koanf:"local"`
+	S3           S3Config         `koanf:"s3"`
+	MaxSize      int              `koanf:"maxsize"`
+	AllowedTypes string           `koanf:"allowedtypes"`
+	URLExpire    time.Duration    `koanf:"urlexpire"`
+}
+
+type AuditConfig struct {
+	// Async writes audit entries from the queue instead of during the request.
+	Async bool `koanf:"async"`
+}
+
+type OAuthConfig struct {
+	// StateExpire is how long a user has to finish signing in at the provider.
+	StateExpire time.Duration                  `koanf:"stateexpire"`
+	Providers   map[string]OAuthProviderConfig `koanf:"providers"`
+}
+
+// OAuthProviderConfig is keyed by the provider name used in the routes.
+type OAuthProviderConfig struct {
+	// Driver is google, github or oidc, the provider name when empty.
+	Driver       string `koanf:"driver"`
+	ClientID     string `koanf:"clientid"`
+	ClientSecret string `koanf:"clientsecret"`
+	Issuer       string `koanf:"issuer"`
+	// Scopes is a comma separated list replacing the driver defaults.
+	Scopes   string `koanf:"scopes"`
+	AuthURL  string `koanf:"authurl"`
+	TokenURL string `koanf:"tokenurl"`
+	APIURL   string `koanf:"apiurl"`
+}
+
+type LocalStoreConfig struct {
+	Dir string `koanf:"dir"`
+}
+
+type S3Config struct {
+	Endpoint  string `koanf:"endpoint"`
+	Region    string `koanf:"region"`
+	Bucket    string `koanf:"bucket"`
+	AccessKey string `koanf:"accesskey"`
+	SecretKey string `koanf:"secretkey"`
+	UseSSL    bool   `koanf:"usessl"`
+	PathStyle bool   `koanf:"pathstyle"`
+}
+
+type WorkerConfig struct {
+	Health HealthConfig `koanf:"health"`
+}
+
+type HealthConfig struct {
+	Host string `koanf:"host"`
+	Port int    `koanf:"port"`
+}
+
+type QueueRetryConfig struct {
+	Base   time.Duration `koanf:"base"`
+	Max    time.Duration `koanf:"max"`
+	Jitter float64       `koanf:"jitter"`
+}
+
+// QueueTaskConfig is keyed by queue.TaskConfigKey of the task type.
+type QueueTaskConfig struct {
+	Queue    string            `koanf:"queue"`
+	MaxRetry *int              `koanf:"maxretry"`
+	Timeout  time.Duration     `koanf:"timeout"`
+	Unique   time.Duration     `koanf:"unique"`
+	Retry    *QueueRetryConfig `koanf:"retry"`
 }
 
 type MainDBConfig struct {
@@ -75,11 +306,13 @@ type MainDBConfig struct {
 }
 
 type MailerConfig struct {
-	Address  string `koanf:"address"`
-	Host     string `koanf:"host"`
-	Port     int    `koanf:"port"`
-	Username string `koanf:"username"`
-	Password string `koanf:"password"`
+	Transport string `koanf:"transport"`
+	Dir       string `koanf:"dir"`
+	Address   string `koanf:"address"`
+	Host      string `koanf:"host"`
+	Port      int    `koanf:"port"`
+	Username  string `koanf:"username"`
+	Password  string `koanf:"password"`
 }
 
 type StaticConfig struct {
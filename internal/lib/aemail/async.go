@@ -1,48 +1,97 @@
 package aemail
 
 import (
-	"bytes"
+	"context"
 	"errors"
-	"fmt"
+	"time"
 
 	"github.com/dbunt1tled/fiber-go-api/internal/config"
-	"github.com/dbunt1tled/fiber-go-api/internal/lib/view"
 	"github.com/dbunt1tled/fiber-go-api/internal/modules/user"
 	"github.com/dbunt1tled/fiber-go-api/pkg/queue"
 )
 
 type MailServiceAsync struct {
-	producer *queue.Producer
+	task *queue.Task[queue.EmailPayload]
 }
 
-func NewMailServiceAsync(producer *queue.Producer) *MailServiceAsync {
+func NewMailServiceAsync(task *queue.Task[queue.EmailPayload]) *MailServiceAsync {
 	return &MailServiceAsync{
-		producer: producer,
+		task: task,
 	}
 }
 
-func (m *MailServiceAsync) SendConfirmMail(user *user.User, token string) error {
-	data := view.MakeTemplateData(map[string]any{
-		"User":  *user,
-		"Token": token,
+func (m *MailServiceAsync) SendConfirmMail(user *user.User, fingerprint string) error {
+	return m.send(MailConfirm, user.Email, map[string]any{
+		"User":        *user,
+		"Fingerprint": fingerprint,
+		"Expire":      config.Get().Server.JWT.Expire.Confirm,
 	})
-	templ, err := view.GetTemplate("auth/register.gohtml")
-	if err != nil {
-		return err
+}
+
+func (m *MailServiceAsync) SendMagicLinkMail(user *user.User, token string, expire time.Duration) error {
+	return m.send(MailMagicLink, user.Email, map[string]any{
+		"User":   *user,
+		"Token":  token,
+		"Expire": expire,
+	})
+}
+
+func (m *MailServiceAsync) SendPasswordResetMail(user *user.User, fingerprint string) error {
+	return m.send(MailPasswordReset, user.Email, map[string]any{
+		"User":        *user,
+		"Fingerprint": fingerprint,
+		"Expire":      config.Get().Server.URLSigner.Expire,
+	})
+}
+
+// SendEmailChangeMail asks the new address to confirm the change.
+func (m *MailServiceAsync) SendEmailChangeMail(user *user.User, fingerprint string) error {
+	if user.PendingEmail == nil {
+		return errors.New("no pending email")
 	}
-	if templ == nil {
-		return errors.New("template not found")
+	return m.send(MailEmailChange, *user.PendingEmail, map[string]any{
+		"User":        *user,
+		"Fingerprint": fingerprint,
+		"Expire":      config.Get().Server.URLSigner.Expire,
+	})
+}
+
+// SendEmailChangeNoticeMail warns the current address and lets it cancel the change.
+func (m *MailServiceAsync) SendEmailChangeNoticeMail(user *user.User, fingerprint string) error {
+	return m.send(MailEmailChangeNotice, user.Email, map[string]any{
+		"User":        *user,
+		"Fingerprint": fingerprint,
+		"Expire":      config.Get().Server.URLSigner.Expire,
+	})
+}
+
+func (m *MailServiceAsync) SendNotificationMail(
+	user *user.User,
+	typ string,
+	title string,
+	body string,
+	link string,
+) error {
+	return m.send(MailNotification, user.Email, map[string]any{
+		"User":              *user,
+		"Type":              typ,
+		"Title":             title,
+		"Body":              body,
+		"Link":              link,
+		"UnsubscribeExpire": UnsubscribeExpire,
+	})
+}
+
+func (m *MailServiceAsync) send(name string, to string, data map[string]any) error {
+	mail, ok := Find(name)
+	if !ok {
+		return errors.New("mail type not found")
 	}
-	var html bytes.Buffer
-	err = templ.Execute(&html, data)
+	payload, err := mail.Build(to, data)
 	if err != nil {
 		return err
 	}
 
-	payload := queue.EmailPayload{
-		To:      user.Email,
-		Subject: fmt.Sprintf("Welcome to %s", config.Get().Name),
-		Body:    html.String(),
-	}
-	return m.producer.SendEmail(&payload)
+	_, err = m.task.Enqueue(context.Background(), *payload)
+	return err
 }
@@ -0,0 +1,168 @@
+package aemail
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dbunt1tled/fiber-go-api/internal/config"
+	"github.com/dbunt1tled/fiber-go-api/internal/lib/view"
+	"github.com/dbunt1tled/fiber-go-api/internal/modules/user"
+	"github.com/dbunt1tled/fiber-go-api/pkg/queue"
+)
+
+const (
+	MailConfirm       = "confirm"
+	MailPasswordReset = "password_reset"
+	MailMagicLink     = "magic_link"
+	MailNotification  = "notification"
+
+	MailEmailChange       = "email_change"
+	MailEmailChangeNotice = "email_change_notice"
+)
+
+// UnsubscribeExpire is how long the unsubscribe link of a notification mail stays valid.
+const UnsubscribeExpire = 30 * 24 * time.Hour
+
+// Mail describes one type of outgoing email: its template, subject and
+// the fixture data used to preview it without touching real users.
+type Mail struct {
+	Name     string
+	Template string
+	Subject  func(data map[string]any) string
+	Fixture  func() map[string]any
+}
+
+func Catalog() []Mail {
+	return []Mail{
+		{
+			Name:     MailConfirm,
+			Template: "auth/register.gohtml",
+			Subject: func(_ map[string]any) string {
+				return fmt.Sprintf("Welcome to %s", config.Get().Name)
+			},
+			Fixture: func() map[string]any {
+				return map[string]any{
+					"User":        fixtureUser(),
+					"Fingerprint": "preview",
+					"Expire":      config.Get().Server.JWT.Expire.Confirm,
+				}
+			},
+		},
+		{
+			Name:     MailPasswordReset,
+			Template: "auth/password_reset_mail.gohtml",
+			Subject: func(_ map[string]any) string {
+				return fmt.Sprintf("%s: reset your password", config.Get().Name)
+			},
+			Fixture: func() map[string]any {
+				return map[string]any{
+					"User":        fixtureUser(),
+					"Fingerprint": "preview",
+					"Expire":      config.Get().Server.URLSigner.Expire,
+				}
+			},
+		},
+		{
+			Name:     MailMagicLink,
+			Template: "auth/magic_link_mail.gohtml",
+			Subject: func(_ map[string]any) string {
+				return fmt.Sprintf("%s: your sign-in link", config.Get().Name)
+			},
+			Fixture: func() map[string]any {
+				return map[string]any{
+					"User":   fixtureUser(),
+					"Token":  "preview",
+					"Expire": config.Get().Server.Auth.MagicLink.Expire,
+				}
+			},
+		},
+		{
+			Name:     MailEmailChange,
+			Template: "auth/email_change_mail.gohtml",
+			Subject: func(_ map[string]any) string {
+				return fmt.Sprintf("%s: confirm your new email", config.Get().Name)
+			},
+			Fixture: func() map[string]any {
+				return emailChangeFixture()
+			},
+		},
+		{
+			Name:     MailEmailChangeNotice,
+			Template: "auth/email_change_notice.gohtml",
+			Subject: func(_ map[string]any) string {
+				return fmt.Sprintf("%s: your email is being changed", config.Get().Name)
+			},
+			Fixture: func() map[string]any {
+				return emailChangeFixture()
+			},
+		},
+		{
+			Name:     MailNotification,
+			Template: "notification/mail.gohtml",
+			Subject: func(data map[string]any) string {
+				return fmt.Sprintf("%s: %s", config.Get().Name, data["Title"])
+			},
+			Fixture: func() map[string]any {
+				return map[string]any{
+					"User":              fixtureUser(),
+					"Type":              "account.confirmed",
+					"Title":             "Your account has been confirmed",
+					"Body":              "You can now sign in and use all features.",
+					"Link":              config.Get().URL,
+					"UnsubscribeExpire": UnsubscribeExpire,
+				}
+			},
+		},
+	}
+}
+
+func Find(name string) (Mail, bool) {
+	for _, m := range Catalog() {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return Mail{}, false
+}
+
+// Build renders the mail into the payload the queue worker delivers.
+func (m Mail) Build(to string, data map[string]any) (*queue.EmailPayload, error) {
+	body, err := view.Render(m.Template, data)
+	if err != nil {
+		return nil, err
+	}
+	text, err := view.HTMLToText(body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &queue.EmailPayload{
+		To:      to,
+		Subject: m.Subject(data),
+		Body:    body,
+		Text:    text,
+	}, nil
+}
+
+func fixtureUser() user.User {
+	return user.User{
+		FirstName:   "John",
+		SecondName:  "Dou",
+		Email:       "john.dou@example.com",
+		PhoneNumber: "+1234567890",
+		Status:      user.Pending,
+		Roles:       user.Roles{user.Person},
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+}
+
+func emailChangeFixture() map[string]any {
+	u := fixtureUser()
+	u.RequestEmailChange("john.new@example.com")
+	return map[string]any{
+		"User":        u,
+		"Fingerprint": "preview",
+		"Expire":      config.Get().Server.URLSigner.Expire,
+	}
+}
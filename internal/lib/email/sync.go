@@ -8,11 +8,11 @@ import (
 )
 
 type MailService struct {
-	mailer      *mailer.Mailer
+	mailer      mailer.Sender
 	fromAddress string
 }
 
-func NewMailService(mailer *mailer.Mailer, fromAddress string) *MailService {
+func NewMailService(mailer mailer.Sender, fromAddress string) *MailService {
 	return &MailService{
 		mailer:      mailer,
 		fromAddress: fromAddress,
@@ -24,6 +24,7 @@ func (m *MailService) SendEmail(
 	to string,
 	subject string,
 	body string,
+	text string,
 ) error {
 	e := mail.NewMsg()
 
@@ -37,7 +38,12 @@ func (m *MailService) SendEmail(
 	}
 
 	e.Subject(subject)
-	e.SetBodyString(mail.TypeTextHTML, body)
+	if text != "" {
+		e.SetBodyString(mail.TypeTextPlain, text)
+		e.AddAlternativeString(mail.TypeTextHTML, body)
+	} else {
+		e.SetBodyString(mail.TypeTextHTML, body)
+	}
 
 	if err = m.mailer.SendCtx(c, e); err != nil {
 		return err
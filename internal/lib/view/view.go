@@ -1,14 +1,48 @@
 package view
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"html/template"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/dbunt1tled/fiber-go-api/internal/config"
+	"github.com/dbunt1tled/fiber-go-api/pkg/hasher"
+	"golang.org/x/net/html"
 	"golang.org/x/text/language"
 )
 
+var signer atomic.Pointer[hasher.URLSigner]
+
+// SetURLSigner enables the signedURL template helper.
+func SetURLSigner(s *hasher.URLSigner) {
+	signer.Store(s)
+}
+
+// Funcs are the helpers available in every template.
+func Funcs() template.FuncMap {
+	return template.FuncMap{
+		"signedURL": SignedURL,
+	}
+}
+
+// SignedURL signs an application path, e.g. {{signedURL (printf "/api/files/%s/download" .ID) .Expire}}.
+// Without a ttl the link lives for the configured url signer expiry.
+func SignedURL(path string, ttl ...time.Duration) (string, error) {
+	s := signer.Load()
+	if s == nil {
+		return "", errors.New("url signer is not configured")
+	}
+	expire := config.Get().Server.URLSigner.Expire
+	if len(ttl) > 0 {
+		expire = ttl[0]
+	}
+	return s.Sign(config.Get().URL+path, expire)
+}
+
 func MakeTemplateData(data map[string]any) map[string]any {
 	_, ok := data["Locale"]
 	if !ok {
@@ -24,7 +58,7 @@ func MakeTemplateData(data map[string]any) map[string]any {
 
 func GetTemplate(templ string) (*template.Template, error) {
 	basePath := "./resources/templates/"
-	return template.New(templ).ParseFiles([]string{
+	return template.New(templ).Funcs(Funcs()).ParseFiles([]string{
 		basePath + "base/header.gohtml",
 		basePath + "base/footer.gohtml",
 		basePath + "base/layout/l_header.gohtml",
@@ -32,3 +66,73 @@ func GetTemplate(templ string) (*template.Template, error) {
 		basePath + templ,
 	}...)
 }
+
+func Render(templ string, data map[string]any) (string, error) {
+	t, err := GetTemplate(templ)
+	if err != nil {
+		return "", err
+	}
+	if t == nil {
+		return "", errors.New("template not found")
+	}
+	var out bytes.Buffer
+	if err = t.Execute(&out, MakeTemplateData(data)); err != nil {
+		return "", err
+	}
+
+	return out.String(), nil
+}
+
+// HTMLToText converts rendered mail HTML into its plain-text alternative.
+// Links keep their target in brackets so they stay usable in text clients.
+func HTMLToText(source string) (string, error) {
+	doc, err := html.Parse(strings.NewReader(source))
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			if text := strings.Join(strings.Fields(n.Data), " "); text != "" {
+				b.WriteString(text)
+				b.WriteString(" ")
+			}
+			return
+		case html.ElementNode:
+			switch n.Data {
+			case "head", "script", "style", "title", "img":
+				return
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+		if n.Type != html.ElementNode {
+			return
+		}
+		switch n.Data {
+		case "a":
+			for _, attr := range n.Attr {
+				if attr.Key == "href" && attr.Val != "" {
+					b.WriteString("[" + attr.Val + "] ")
+				}
+			}
+		case "p", "div", "tr", "br", "h1", "h2", "h3", "h4", "li":
+			b.WriteString("\n")
+		}
+	}
+	walk(doc)
+
+	lines := strings.Split(b.String(), "\n")
+	result := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			result = append(result, line)
+		}
+	}
+
+	return strings.Join(result, "\n\n"), nil
+}
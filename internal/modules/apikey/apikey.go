@@ -0,0 +1,56 @@
+package apikey
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/dbunt1tled/fiber-go-api/internal/modules/auth"
+	"github.com/google/uuid"
+)
+
+type APIKey struct {
+	ID     uuid.UUID `db:"id"      json:"id"`
+	UserID uuid.UUID `db:"user_id" json:"userId"`
+	Name   string    `db:"name"    json:"name"`
+	// Prefix is the public part of the key, it finds the key and tells keys apart in listings.
+	Prefix     string     `db:"prefix"       json:"prefix"`
+	Hash       string     `db:"hash"         json:"-"`
+	Scopes     []string   `db:"scopes"       json:"scopes"`
+	ExpiresAt  *time.Time `db:"expires_at"   json:"expiresAt"`
+	LastUsedAt *time.Time `db:"last_used_at" json:"lastUsedAt"`
+	RevokedAt  *time.Time `db:"revoked_at"   json:"revokedAt"`
+	CreatedAt  time.Time  `db:"created_at"   json:"createdAt"`
+	UpdatedAt  time.Time  `db:"updated_at"   json:"updatedAt"`
+}
+
+func (k *APIKey) TableName() string  { return "api_keys" }
+func (k *APIKey) GetID() uuid.UUID   { return k.ID }
+func (k *APIKey) SetID(id uuid.UUID) { k.ID = id }
+func (k *APIKey) NextID() *APIKey {
+	var err error
+	k.ID, err = uuid.NewV7()
+	if err != nil {
+		panic(fmt.Errorf("failed to generate uuid: %w", err))
+	}
+	return k
+}
+
+func (k *APIKey) Usable(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+func (k *APIKey) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+// Allows tells whether the scopes of the key cover a request with the method.
+func (k *APIKey) Allows(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return k.HasScope(auth.ScopeRead) || k.HasScope(auth.ScopeWrite)
+	default:
+		return k.HasScope(auth.ScopeWrite)
+	}
+}
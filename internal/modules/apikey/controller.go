@@ -0,0 +1,116 @@
+package apikey
+
+import (
+	"errors"
+
+	"github.com/dbunt1tled/fiber-go-api/internal/modules/auth"
+	"github.com/dbunt1tled/fiber-go-api/internal/modules/user"
+	"github.com/dbunt1tled/fiber-go-api/pkg/e"
+	"github.com/dbunt1tled/fiber-go-api/pkg/http"
+	"github.com/dbunt1tled/fiber-go-api/pkg/storage"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+)
+
+type Controller struct {
+	http.BaseController
+
+	apiKeyService *Service
+}
+
+func NewController(
+	apiKeyService *Service,
+	validation *validator.Validate,
+) *Controller {
+	return &Controller{
+		BaseController: http.NewBaseController(validation),
+		apiKeyService:  apiKeyService,
+	}
+}
+
+func (kc *Controller) List(c fiber.Ctx) error {
+	u, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+	req := new(ListRequest)
+	if err = kc.BindAndValidate(c, req, e.Err422APIKeyValidateError); err != nil {
+		return err
+	}
+
+	keys, err := kc.apiKeyService.Paginate(
+		c.Context(),
+		u.ID,
+		req.Page.Page,
+		req.Page.Limit,
+		storage.WithSort(req.Sort.Field, req.Sort.Order),
+	)
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("API key list error.", e.Err422APIKeyListError, err)
+	}
+
+	return kc.JSON200(c, NewAPIKeyListResponse(keys))
+}
+
+// Create needs a signed in user, a key can't mint further keys.
+func (kc *Controller) Create(c fiber.Ctx) error {
+	u, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+	if Current(c) != nil {
+		return e.NewForbiddenError("API keys can't manage API keys.", e.Err403APIKeyNotAllowedError)
+	}
+	req := new(Create)
+	if err = kc.BindAndValidate(c, req, e.Err422APIKeyValidateError); err != nil {
+		return err
+	}
+	for _, scope := range req.Scopes {
+		if scope == auth.ScopeAdmin && !u.HasRole(user.Admin) {
+			return e.NewUnprocessableEntityError("Only admins can create keys with the admin scope.", e.Err422APIKeyAdminScopeError)
+		}
+	}
+
+	k, plain, err := kc.apiKeyService.Create(c.Context(), u.ID, req.Name, req.Scopes, req.ExpiresAt)
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("API key create error.", e.Err422APIKeyCreateError, err)
+	}
+
+	return kc.JSON201(c, NewCreatedAPIKeyResponse(k, plain))
+}
+
+func (kc *Controller) Revoke(c fiber.Ctx) error {
+	u, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+	req := new(Revoke)
+	if err = kc.BindAndValidate(c, req, e.Err422APIKeyValidateError); err != nil {
+		return err
+	}
+
+	k, err := kc.apiKeyService.Revoke(c.Context(), u.ID, uuid.MustParse(req.ID))
+	if errors.Is(err, ErrNotFound) {
+		return e.NewNotFoundError(err.Error(), e.Err404APIKeyNotFound)
+	}
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("API key revoke error.", e.Err422APIKeyRevokeError, err)
+	}
+
+	return kc.JSON200(c, NewAPIKeyResponse(k))
+}
+
+// Current is the key the request was authenticated with, nil for token authentication.
+func Current(c fiber.Ctx) *APIKey {
+	k, _ := c.Locals("apiKey").(*APIKey)
+	return k
+}
+
+func currentUser(c fiber.Ctx) (*user.User, error) {
+	u, ok := c.Locals("user").(*user.User)
+	if !ok || u == nil {
+		return nil, e.NewUnauthorizedError("Unauthorized", e.Err401UserNotFoundError)
+	}
+	return u, nil
+}
@@ -0,0 +1,67 @@
+package apikey
+
+import (
+	"github.com/dbunt1tled/fiber-go-api/pkg/storage"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	*storage.Repository[*APIKey]
+}
+
+func NewAPIKeyRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{
+		Repository: storage.NewRepository[*APIKey](
+			pool,
+			"api_keys",
+			scanAPIKey,
+			scanAPIKeys,
+			buildAPIKeyRecord,
+		),
+	}
+}
+
+func scanAPIKey(row pgx.Row) (*APIKey, error) {
+	var k APIKey
+
+	err := row.Scan(
+		&k.ID,
+		&k.UserID,
+		&k.Name,
+		&k.Prefix,
+		&k.Hash,
+		&k.Scopes,
+		&k.ExpiresAt,
+		&k.LastUsedAt,
+		&k.RevokedAt,
+		&k.CreatedAt,
+		&k.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &k, nil
+}
+
+func scanAPIKeys(rows pgx.Rows) ([]*APIKey, error) {
+	return storage.ScanRowsWithScanner(rows, scanAPIKey)
+}
+
+func buildAPIKeyRecord(k *APIKey) goqu.Record {
+	return goqu.Record{
+		"id":           k.ID,
+		"user_id":      k.UserID,
+		"name":         k.Name,
+		"prefix":       k.Prefix,
+		"hash":         k.Hash,
+		"scopes":       storage.ToPgArray(k.Scopes),
+		"expires_at":   k.ExpiresAt,
+		"last_used_at": k.LastUsedAt,
+		"revoked_at":   k.RevokedAt,
+		"updated_at":   goqu.L("NOW()"),
+		"created_at":   k.CreatedAt,
+	}
+}
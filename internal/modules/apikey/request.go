@@ -0,0 +1,21 @@
+package apikey
+
+import (
+	"time"
+
+	"github.com/dbunt1tled/fiber-go-api/pkg/http/dto"
+)
+
+type ListRequest struct {
+	dto.PaginationQuery
+}
+
+type Create struct {
+	Name      string     `json:"name"      validate:"required,max=100"                                  example:"deploy bot"`
+	Scopes    []string   `json:"scopes"    validate:"required,min=1,unique,dive,oneof=read write admin" example:"read"`
+	ExpiresAt *time.Time `json:"expiresAt" validate:"omitempty,gt"                                      example:"2027-01-01T00:00:00Z"`
+}
+
+type Revoke struct {
+	ID string `params:"id" json:"id" validate:"required,uuid" example:"0e4f8d4c-6c1f-4a44-9c59-6f1f3e2b7a10"`
+}
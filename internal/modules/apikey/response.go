@@ -0,0 +1,32 @@
+package apikey
+
+import (
+	"github.com/dbunt1tled/fiber-go-api/pkg/http/dto"
+	"github.com/dbunt1tled/fiber-go-api/pkg/storage"
+)
+
+func NewAPIKeyResource(k *APIKey) *dto.Resource {
+	resource := dto.NewResource("apiKey", k.ID.String())
+	resource.MarshalAttributes(k)
+	resource.SetRelationship("user", "user", k.UserID.String())
+	return resource
+}
+
+func NewAPIKeyResponse(k *APIKey) *dto.Document {
+	return dto.NewResponse().SetData(NewAPIKeyResource(k)).Build()
+}
+
+// NewCreatedAPIKeyResponse is the only response carrying the plaintext key.
+func NewCreatedAPIKeyResponse(k *APIKey, plain string) *dto.Document {
+	resource := NewAPIKeyResource(k)
+	resource.SetAttribute("key", plain)
+	return dto.NewResponse().SetData(resource).Build()
+}
+
+func NewAPIKeyListResponse(k *storage.Paginator[*APIKey]) *dto.Document {
+	resources := make([]*dto.Resource, len(k.Items))
+	for i, key := range k.Items {
+		resources[i] = NewAPIKeyResource(key)
+	}
+	return dto.NewResponse().SetData(resources).SetMetaPagination(k).Build()
+}
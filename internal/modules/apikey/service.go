@@ -0,0 +1,144 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/dbunt1tled/fiber-go-api/pkg/f"
+	"github.com/dbunt1tled/fiber-go-api/pkg/hasher"
+	"github.com/dbunt1tled/fiber-go-api/pkg/storage"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	lookupLength = 12
+	secretLength = 40
+	// touchInterval limits last used writes to one per key and interval.
+	touchInterval = time.Minute
+)
+
+var (
+	ErrInvalidKey = errors.New("api key is invalid, expired or revoked")
+	ErrNotFound   = errors.New("api key not found")
+)
+
+type Options struct {
+	// Prefix starts every key, so leaked keys are easy to recognise by secret scanners.
+	Prefix string
+}
+
+type Service struct {
+	apiKeyRepository *Repository
+	hasher           *hasher.Hasher
+	opts             Options
+}
+
+func NewAPIKeyService(pool *pgxpool.Pool, hasher *hasher.Hasher, opts Options) *Service {
+	if opts.Prefix == "" {
+		opts.Prefix = "key"
+	}
+
+	return &Service{
+		apiKeyRepository: NewAPIKeyRepository(pool),
+		hasher:           hasher,
+		opts:             opts,
+	}
+}
+
+// Create stores a new key of the user and returns it with the plaintext key, which is not kept and can't be shown again.
+func (s *Service) Create(
+	ctx context.Context,
+	userID uuid.UUID,
+	name string,
+	scopes []string,
+	expiresAt *time.Time,
+) (*APIKey, string, error) {
+	lookup, err := s.hasher.RandomString(lookupLength)
+	if err != nil {
+		return nil, "", err
+	}
+	secret, err := s.hasher.RandomString(secretLength)
+	if err != nil {
+		return nil, "", err
+	}
+	prefix := s.opts.Prefix + "_" + lookup
+	plain := prefix + "_" + secret
+
+	k, err := s.apiKeyRepository.Insert(ctx, (&APIKey{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    prefix,
+		Hash:      s.hasher.HashToken(plain),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}).NextID())
+	if err != nil {
+		return nil, "", err
+	}
+	return k, plain, nil
+}
+
+// Authenticate finds the usable key matching the plaintext key and records its use.
+func (s *Service) Authenticate(ctx context.Context, plain string) (*APIKey, error) {
+	idx := strings.LastIndexByte(plain, '_')
+	if idx <= 0 {
+		return nil, ErrInvalidKey
+	}
+	k, err := s.apiKeyRepository.One(
+		ctx,
+		storage.WithFilter(storage.NewRule("prefix", storage.OpEqual, plain[:idx])),
+	)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if k == nil || !s.hasher.CompareToken(plain, k.Hash) || !k.Usable(now) {
+		return nil, ErrInvalidKey
+	}
+
+	if k.LastUsedAt == nil || now.Sub(*k.LastUsedAt) >= touchInterval {
+		_, err = s.apiKeyRepository.UpdateWhere(
+			ctx,
+			goqu.Record{"last_used_at": now},
+			storage.NewRule("id", storage.OpEqual, k.ID),
+		)
+		if err != nil {
+			return nil, err
+		}
+		k.LastUsedAt = &now
+	}
+	return k, nil
+}
+
+func (s *Service) Paginate(
+	ctx context.Context,
+	userID uuid.UUID,
+	page int,
+	perPage int,
+	opts ...storage.QueryOption,
+) (*storage.Paginator[*APIKey], error) {
+	opts = append(opts, storage.WithFilter(storage.NewRule("user_id", storage.OpEqual, userID)))
+	return s.apiKeyRepository.Paginate(ctx, page, perPage, opts...)
+}
+
+// Revoke keeps the key for the listing, it just stops authenticating.
+func (s *Service) Revoke(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*APIKey, error) {
+	k, err := s.apiKeyRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if k == nil || k.UserID != userID {
+		return nil, ErrNotFound
+	}
+	if k.RevokedAt != nil {
+		return k, nil
+	}
+	k.RevokedAt = f.Pointer(time.Now())
+	return s.apiKeyRepository.Update(ctx, k)
+}
@@ -0,0 +1,54 @@
+package auditlog
+
+import (
+	"github.com/dbunt1tled/fiber-go-api/pkg/audit"
+	"github.com/dbunt1tled/fiber-go-api/pkg/e"
+	"github.com/dbunt1tled/fiber-go-api/pkg/http"
+	"github.com/dbunt1tled/fiber-go-api/pkg/storage"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+)
+
+type Controller struct {
+	http.BaseController
+
+	auditService *audit.Service
+}
+
+func NewController(
+	auditService *audit.Service,
+	validation *validator.Validate,
+) *Controller {
+	return &Controller{
+		BaseController: http.NewBaseController(validation),
+		auditService:   auditService,
+	}
+}
+
+// List pages through the audit log, newest entries first unless sorted otherwise.
+func (ac *Controller) List(c fiber.Ctx) error {
+	req := new(ListRequest)
+	if err := ac.BindAndValidate(c, req, e.Err422AuditValidateError); err != nil {
+		return err
+	}
+
+	entries, err := ac.auditService.Paginate(
+		c.Context(),
+		req.Page.Page,
+		req.Page.Limit,
+		storage.WithFilter(
+			storage.NewRule("actor_id", storage.OpEqual, req.ActorID),
+			storage.NewRule("action", storage.OpEqual, req.Action),
+			storage.NewRule("target_type", storage.OpEqual, req.TargetType),
+			storage.NewRule("target_id", storage.OpEqual, req.TargetID),
+			storage.NewRule("created_at", storage.OpGreaterThanOrEqual, req.From),
+			storage.NewRule("created_at", storage.OpLessThan, req.To),
+		),
+		storage.WithSort(req.Sort.Field, req.Sort.Order),
+	)
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Audit log error.", e.Err422AuditListError, err)
+	}
+
+	return ac.JSON200(c, NewEntryListResponse(entries))
+}
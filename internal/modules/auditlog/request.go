@@ -0,0 +1,18 @@
+package auditlog
+
+import (
+	"time"
+
+	"github.com/dbunt1tled/fiber-go-api/pkg/http/dto"
+)
+
+type ListRequest struct {
+	dto.PaginationQuery
+
+	ActorID    *string    `query:"actorId"    json:"actorId"    validate:"omitempty,uuid"    example:"0e4f8d4c-6c1f-4a44-9c59-6f1f3e2b7a10"`
+	Action     *string    `query:"action"     json:"action"     validate:"omitempty,max=100" example:"user.status_changed"`
+	TargetType *string    `query:"targetType" json:"targetType" validate:"omitempty,max=50"  example:"user"`
+	TargetID   *string    `query:"targetId"   json:"targetId"   validate:"omitempty,max=100" example:"0e4f8d4c-6c1f-4a44-9c59-6f1f3e2b7a10"`
+	From       *time.Time `query:"from"       json:"from"       validate:"omitempty"         example:"2026-01-01T00:00:00Z"`
+	To         *time.Time `query:"to"         json:"to"         validate:"omitempty"         example:"2026-02-01T00:00:00Z"`
+}
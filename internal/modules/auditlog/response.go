@@ -0,0 +1,27 @@
+package auditlog
+
+import (
+	"github.com/dbunt1tled/fiber-go-api/pkg/audit"
+	"github.com/dbunt1tled/fiber-go-api/pkg/http/dto"
+	"github.com/dbunt1tled/fiber-go-api/pkg/storage"
+)
+
+func NewEntryResource(e *audit.Entry) *dto.Resource {
+	resource := dto.NewResource("auditEntry", e.ID.String())
+	resource.MarshalAttributes(e)
+	if e.ActorID != nil {
+		resource.SetRelationship("actor", "user", e.ActorID.String())
+	}
+	if e.ImpersonatorID != nil {
+		resource.SetRelationship("impersonator", "user", e.ImpersonatorID.String())
+	}
+	return resource
+}
+
+func NewEntryListResponse(e *storage.Paginator[*audit.Entry]) *dto.Document {
+	resources := make([]*dto.Resource, len(e.Items))
+	for i, entry := range e.Items {
+		resources[i] = NewEntryResource(entry)
+	}
+	return dto.NewResponse().SetData(resources).SetMetaPagination(e).Build()
+}
@@ -1,14 +1,21 @@
 package auth
 
 import (
+	"context"
+	"errors"
+	"strings"
 	"time"
 
+	"github.com/dbunt1tled/fiber-go-api/internal/config"
 	"github.com/dbunt1tled/fiber-go-api/internal/lib/aemail"
+	"github.com/dbunt1tled/fiber-go-api/internal/lib/view"
 	"github.com/dbunt1tled/fiber-go-api/internal/modules/user"
 	"github.com/dbunt1tled/fiber-go-api/pkg/e"
 	"github.com/dbunt1tled/fiber-go-api/pkg/f"
 	"github.com/dbunt1tled/fiber-go-api/pkg/hasher"
 	"github.com/dbunt1tled/fiber-go-api/pkg/http"
+	"github.com/dbunt1tled/fiber-go-api/pkg/http/dto"
+	"github.com/dbunt1tled/fiber-go-api/pkg/log"
 	"github.com/dbunt1tled/fiber-go-api/pkg/storage"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v3"
@@ -39,9 +46,9 @@ func NewController(
 
 func (a *Controller) Register(c fiber.Ctx) error {
 	var (
-		err                    error
-		password, confirmToken string
-		u                      *user.User
+		err      error
+		password string
+		u        *user.User
 	)
 	req := new(Register)
 
@@ -66,16 +73,7 @@ func (a *Controller) Register(c fiber.Ctx) error {
 		)
 	}
 
-	confirmToken, err = a.authService.GenerateConfirmToken(u)
-	if err != nil {
-		return e.NewUnprocessableEntityErrorWrap(
-			"Generate token error.",
-			e.Err422CreateConfirmTokenError,
-			err,
-		)
-	}
-
-	err = a.mailService.SendConfirmMail(u, confirmToken)
+	err = a.mailService.SendConfirmMail(u, a.authService.ConfirmFingerprint(u))
 	if err != nil {
 		return e.NewUnprocessableEntityErrorWrap(
 			"Send email error.",
@@ -84,57 +82,95 @@ func (a *Controller) Register(c fiber.Ctx) error {
 		)
 	}
 
+	a.authService.Registered(c.Context(), u)
+
 	return a.JSON200(c, user.NewUserResponse(u))
 }
 
+// Confirm is reached through the signed link of the confirmation mail; browsers get a result page instead of JSON.
 func (a *Controller) Confirm(c fiber.Ctx) error {
-	var (
-		id    uuid.UUID
-		err   error
-		token map[string]interface{}
-		u     *user.User
-	)
-	req := new(Confirm)
-
-	if err = a.BindAndValidate(c, req, e.Err422ConfirmValidateError); err != nil {
-		return err
+	u, err := a.confirm(c)
+	if !wantsHTML(c) {
+		if err != nil {
+			return err
+		}
+		return a.JSON200(c, user.NewUserResponse(u))
 	}
 
-	token, err = a.authService.DecodeConfirmToken(req.Token)
-
 	if err != nil {
-		return e.NewUnprocessableEntityErrorWrap(
-			"confirm user error",
-			e.Err422ConfirmTokenError,
-			err,
-		)
+		return renderError(c, "Confirmation failed", err)
 	}
-	id, err = uuid.Parse(token["iss"].(string))
-	if err != nil {
-		return e.NewUnprocessableEntityError("confirm user error", e.Err422TokenConfirmUserIdError)
+	return c.Render("general/message.gohtml", view.MakeTemplateData(map[string]any{
+		"Title":   "Account confirmed",
+		"Message": "Your email has been confirmed, you can sign in now.",
+	}))
+}
+
+func (a *Controller) confirm(c fiber.Ctx) (*user.User, error) {
+	req := new(Confirm)
+	if err := a.BindAndValidate(c, req, e.Err422ConfirmValidateError); err != nil {
+		return nil, err
 	}
 
-	u, err = a.userService.FindByID(c.Context(), id)
+	u, err := a.userService.FindByID(c.Context(), uuid.MustParse(req.ID))
 	if err != nil {
-		return e.NewUnprocessableEntityError("confirm user error", e.Err422TokenConfirmUserError)
+		return nil, e.NewUnprocessableEntityError("confirm user error", e.Err422TokenConfirmUserError)
 	}
 
 	if u == nil {
-		return e.NewUnprocessableEntityError("confirm user error", e.Err422TokenConfirmUserNotFoundError)
+		return nil, e.NewUnprocessableEntityError("confirm user error", e.Err422TokenConfirmUserNotFoundError)
 	}
 
 	if u.Status != user.Pending {
-		return e.NewUnprocessableEntityError("confirm user error", e.Err422TokenConfirmUserNotPendingError)
+		return nil, e.NewUnprocessableEntityError("Account is already confirmed.", e.Err422TokenConfirmUserNotPendingError)
+	}
+	if a.authService.ConfirmFingerprint(u) != req.Fingerprint {
+		return nil, e.NewNotFoundError("Link has expired, request a new confirmation email.", e.Err404URLExpired)
 	}
 	u.Status = user.Active
 	u.ConfirmedAt = f.Pointer(time.Now())
 	u, err = a.userService.Update(c.Context(), u)
 
+	// another request confirmed or changed the account since it was read
+	if errors.Is(err, storage.ErrConflict) {
+		return nil, e.NewConflictError("Account was changed meanwhile, open the link again.", e.Err409ConflictError)
+	}
 	if err != nil {
-		return e.NewUnprocessableEntityError("confirm user error", e.Err422TokenConfirmUserUpdateError)
+		return nil, e.NewUnprocessableEntityError("confirm user error", e.Err422TokenConfirmUserUpdateError)
 	}
+	a.authService.Confirmed(c.Context(), u)
 
-	return a.JSON200(c, user.NewUserResponse(u))
+	return u, nil
+}
+
+// ResendConfirm answers the same way whether the email belongs to a pending account, another account or none.
+func (a *Controller) ResendConfirm(c fiber.Ctx) error {
+	req := new(ResendConfirm)
+	if err := a.BindAndValidate(c, req, e.Err422ConfirmResendValidateError); err != nil {
+		return err
+	}
+
+	u, err := a.userService.One(
+		c.Context(),
+		storage.WithFilter(
+			storage.NewRule("email", storage.OpEqual, req.Email),
+			storage.NewRule("status", storage.OpEqual, user.Pending),
+		),
+	)
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Confirmation resend error.", e.Err422ConfirmResendError, err)
+	}
+	if u != nil && a.authService.CanResendConfirm(u) {
+		u.ConfirmationSentAt = f.Pointer(time.Now())
+		if u, err = a.userService.Update(c.Context(), u); err != nil {
+			return e.NewUnprocessableEntityErrorWrap("Confirmation resend error.", e.Err422ConfirmResendError, err)
+		}
+		if err = a.mailService.SendConfirmMail(u, a.authService.ConfirmFingerprint(u)); err != nil {
+			return e.NewUnprocessableEntityErrorWrap("Send email error.", e.Err422SendConfirmEmailError, err)
+		}
+	}
+
+	return a.JSON200(c, dto.NewResponse().SetMeta("sent", true).Build())
 }
 
 func (a *Controller) Login(c fiber.Ctx) error {
@@ -151,9 +187,12 @@ func (a *Controller) Login(c fiber.Ctx) error {
 
 	u, err = a.userService.One(
 		c.Context(),
-		storage.WithFilter(storage.NewRule("status", storage.OpEqual, user.Active)),
+		storage.WithFilter(
+			storage.NewRule("email", storage.OpEqual, req.Email),
+			storage.NewRule("status", storage.OpEqual, user.Active),
+		),
 	)
-	if err != nil {
+	if err != nil || u == nil {
 		return e.NewUnprocessableEntityError(
 			"Authorization error, password or login is incorrect.",
 			e.Err422LoginUserNotFoundError,
@@ -169,12 +208,17 @@ func (a *Controller) Login(c fiber.Ctx) error {
 	}
 
 	if !ch {
+		a.authService.LoginFailed(c.Context(), u)
 		return e.NewUnprocessableEntityError(
 			"Authorization error, password or login is incorrect.",
 			e.Err422LoginUserPasswordWrongError,
 		)
 	}
 
+	if a.authService.NeedsRehash(u.Password) {
+		a.rehashPassword(c.Context(), u, req.Password)
+	}
+
 	access, refresh, err := a.authService.GenerateAuthTokens(u)
 	if err != nil {
 		return e.NewUnprocessableEntityError(
@@ -182,6 +226,7 @@ func (a *Controller) Login(c fiber.Ctx) error {
 			e.Err422LoginAccessTokenError,
 		)
 	}
+	a.authService.LoggedIn(c.Context(), u, LoginPassword)
 
 	return a.JSON200(c, NewLoginResponse(map[string]interface{}{
 		"accessToken":  access,
@@ -231,3 +276,343 @@ func (a *Controller) Refresh(c fiber.Ctx) error {
 		"refreshToken": refresh,
 	}))
 }
+
+// MagicLink mails a sign-in link to active accounts and answers the same whether the email is known or not.
+func (a *Controller) MagicLink(c fiber.Ctx) error {
+	req := new(MagicLink)
+	if err := a.BindAndValidate(c, req, e.Err422MagicLinkValidateError); err != nil {
+		return err
+	}
+
+	for _, key := range []string{"ip:" + c.IP(), "email:" + strings.ToLower(req.Email)} {
+		ok, err := a.authService.AllowMagicLink(c.Context(), key)
+		if err != nil {
+			return e.NewUnprocessableEntityErrorWrap("Magic link error.", e.Err422MagicLinkError, err)
+		}
+		if !ok {
+			return e.NewTooManyRequestsError("Too many sign-in links requested, try again later.", e.Err429MagicLinkRateLimit)
+		}
+	}
+
+	u, err := a.userService.One(
+		c.Context(),
+		storage.WithFilter(
+			storage.NewRule("email", storage.OpEqual, req.Email),
+			storage.NewRule("status", storage.OpEqual, user.Active),
+		),
+	)
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Magic link error.", e.Err422MagicLinkError, err)
+	}
+	if u != nil {
+		token, err := a.authService.MagicLinkToken(c.Context(), u)
+		if err != nil {
+			return e.NewUnprocessableEntityErrorWrap("Magic link error.", e.Err422MagicLinkError, err)
+		}
+		if err = a.mailService.SendMagicLinkMail(u, token, a.authService.MagicLinkExpire()); err != nil {
+			return e.NewUnprocessableEntityErrorWrap("Send email error.", e.Err422SendMagicLinkEmailError, err)
+		}
+	}
+
+	return a.JSON200(c, dto.NewResponse().SetMeta("sent", true).Build())
+}
+
+// MagicLinkForm is where the mailed link lands; spending the token takes a POST, so link scanners can't use it up.
+func (a *Controller) MagicLinkForm(c fiber.Ctx) error {
+	req := new(MagicLinkLogin)
+	if err := a.BindAndValidate(c, req, e.Err422MagicLinkValidateError); err != nil {
+		return err
+	}
+
+	return c.Render("auth/magic_link.gohtml", view.MakeTemplateData(map[string]any{
+		"Action": c.OriginalURL(),
+	}))
+}
+
+// MagicLinkLogin exchanges a magic link token for the same token pair as Login.
+func (a *Controller) MagicLinkLogin(c fiber.Ctx) error {
+	req := new(MagicLinkLogin)
+	if err := a.BindAndValidate(c, req, e.Err422MagicLinkValidateError); err != nil {
+		return err
+	}
+
+	id, err := a.authService.ConsumeMagicLink(c.Context(), req.Token)
+	if err != nil {
+		return err
+	}
+
+	u, err := a.userService.FindByID(c.Context(), id)
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Magic link error.", e.Err422MagicLinkError, err)
+	}
+	if u == nil || u.Status != user.Active {
+		return e.NewUnauthorizedError("Unauthorized", e.Err401MagicLinkUserNotActiveError)
+	}
+
+	access, refresh, err := a.authService.GenerateAuthTokens(u)
+	if err != nil {
+		return e.NewUnprocessableEntityError(
+			err.Error(),
+			e.Err422LoginAccessTokenError,
+		)
+	}
+	a.authService.LoggedIn(c.Context(), u, LoginMagicLink)
+
+	return a.JSON200(c, NewLoginResponse(map[string]interface{}{
+		"accessToken":  access,
+		"refreshToken": refresh,
+	}))
+}
+
+// Logout records the end of the session in the audit log. Tokens are stateless and stay valid until they
+// expire, the client drops them.
+func (a *Controller) Logout(c fiber.Ctx) error {
+	u, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	a.authService.LoggedOut(c.Context(), u)
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// JWKS publishes the public keys tokens are verified with, in the standard format rather than a JSON:API document.
+func (a *Controller) JWKS(c fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(a.authService.JWKS(), "application/jwk-set+json")
+}
+
+// ForgotPassword answers the same way whether the email is known or not.
+func (a *Controller) ForgotPassword(c fiber.Ctx) error {
+	req := new(ForgotPassword)
+	if err := a.BindAndValidate(c, req, e.Err422PasswordForgotValidateError); err != nil {
+		return err
+	}
+
+	u, err := a.userService.One(
+		c.Context(),
+		storage.WithFilter(
+			storage.NewRule("email", storage.OpEqual, req.Email),
+			storage.NewRule("status", storage.OpEqual, user.Active),
+		),
+	)
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Password reset error.", e.Err422PasswordResetError, err)
+	}
+	if u != nil {
+		if err = a.mailService.SendPasswordResetMail(u, a.authService.PasswordFingerprint(u)); err != nil {
+			return e.NewUnprocessableEntityErrorWrap(
+				"Send email error.",
+				e.Err422SendPasswordResetEmailError,
+				err,
+			)
+		}
+	}
+
+	return a.JSON200(c, dto.NewResponse().SetMeta("sent", true).Build())
+}
+
+// ResetPasswordForm is the page behind the signed link of the password reset mail.
+func (a *Controller) ResetPasswordForm(c fiber.Ctx) error {
+	req := new(ResetPasswordLink)
+	if err := a.BindAndValidate(c, req, e.Err422PasswordResetValidateError); err != nil {
+		return err
+	}
+
+	u, err := a.resetUser(c, req)
+	if err != nil {
+		return err
+	}
+
+	policy := config.Get().Server.Password.Policy
+	return c.Render("auth/password_reset.gohtml", view.MakeTemplateData(map[string]any{
+		"User":      u,
+		"Action":    c.OriginalURL(),
+		"MinLength": policy.MinLength,
+		"MaxLength": policy.MaxLength,
+	}))
+}
+
+func (a *Controller) ResetPassword(c fiber.Ctx) error {
+	req := new(ResetPassword)
+	if err := a.Bind(c, req, e.Err422PasswordResetValidateError); err != nil {
+		return err
+	}
+	if err := a.Validate(c, &req.ResetPasswordLink); err != nil {
+		return err
+	}
+
+	u, err := a.resetUser(c, &req.ResetPasswordLink)
+	if err != nil {
+		return err
+	}
+	// the password policy compares the password with the email and name of the user
+	req.user = u
+	if err = a.Validate(c, req); err != nil {
+		return err
+	}
+	u.Password, err = a.authService.GeneratePasswordHash(req.Password)
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Password hash error.", e.Err422PasswordResetError, err)
+	}
+	u, err = a.userService.Update(c.Context(), u)
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Password reset error.", e.Err422PasswordResetError, err)
+	}
+
+	if wantsHTML(c) {
+		return c.Render("general/message.gohtml", view.MakeTemplateData(map[string]any{
+			"Title":   "Password changed",
+			"Message": "Your password has been changed, you can sign in with the new one.",
+		}))
+	}
+	return a.JSON200(c, user.NewUserResponse(u))
+}
+
+// resetUser treats links issued before the last password change as expired, which makes them single use.
+func (a *Controller) resetUser(c fiber.Ctx, req *ResetPasswordLink) (*user.User, error) {
+	u, err := a.userService.FindByID(c.Context(), uuid.MustParse(req.ID))
+	if err != nil {
+		return nil, e.NewUnprocessableEntityErrorWrap("Password reset error.", e.Err422PasswordResetError, err)
+	}
+	if u == nil || u.Status != user.Active || a.authService.PasswordFingerprint(u) != req.Fingerprint {
+		return nil, e.NewNotFoundError("Link has expired.", e.Err404URLExpired)
+	}
+	return u, nil
+}
+
+// rehashPassword upgrades the stored hash while the password is known; a failure only postpones it to the next login.
+func (a *Controller) rehashPassword(ctx context.Context, u *user.User, password string) {
+	hash, err := a.authService.GeneratePasswordHash(password)
+	if err == nil {
+		u.Password = hash
+		_, err = a.userService.Update(ctx, u)
+	}
+	if err != nil {
+		log.Logger().ErrorContext(ctx, "Password rehash error", err, "user", u.ID)
+	}
+}
+
+// ChangeEmail keeps the current address until the new one is confirmed and warns the current one.
+func (a *Controller) ChangeEmail(c fiber.Ctx) error {
+	u, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+	req := new(ChangeEmail)
+	if err = a.BindAndValidate(c, req, e.Err422EmailChangeValidateError); err != nil {
+		return err
+	}
+
+	valid, err := a.authService.ValidatePassword(req.Password, u.Password)
+	if err != nil || !valid {
+		return e.NewUnprocessableEntityError("Password is incorrect.", e.Err422EmailChangePasswordWrongError)
+	}
+	u.RequestEmailChange(req.Email)
+	u, err = a.userService.Update(c.Context(), u)
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Email change error.", e.Err422EmailChangeError, err)
+	}
+
+	fingerprint := a.authService.EmailFingerprint(u)
+	if err = a.mailService.SendEmailChangeMail(u, fingerprint); err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Send email error.", e.Err422SendEmailChangeEmailError, err)
+	}
+	if err = a.mailService.SendEmailChangeNoticeMail(u, fingerprint); err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Send email error.", e.Err422SendEmailChangeEmailError, err)
+	}
+
+	return a.JSON200(c, user.NewUserResponse(u))
+}
+
+// ConfirmEmail is reached through the signed link sent to the new address.
+func (a *Controller) ConfirmEmail(c fiber.Ctx) error {
+	req := new(ConfirmEmail)
+	if err := a.Bind(c, req, e.Err422EmailConfirmValidateError); err != nil {
+		return err
+	}
+	if err := a.Validate(c, &req.EmailLink); err != nil {
+		return err
+	}
+
+	u, err := a.emailChangeUser(c, &req.EmailLink)
+	if err != nil {
+		return err
+	}
+	// the address may have been taken since the change was requested
+	req.Email = *u.PendingEmail
+	if err = a.Validate(c, req); err != nil {
+		return err
+	}
+
+	u.Email = *u.PendingEmail
+	u.ClearPendingEmail()
+	if _, err = a.userService.Update(c.Context(), u); err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Email confirm error.", e.Err422EmailConfirmError, err)
+	}
+
+	return c.Render("general/message.gohtml", view.MakeTemplateData(map[string]any{
+		"Title":   "Email changed",
+		"Message": "Your email has been changed, sign in with the new address from now on.",
+	}))
+}
+
+// CancelEmail is reached through the signed link sent to the current address.
+func (a *Controller) CancelEmail(c fiber.Ctx) error {
+	req := new(EmailLink)
+	if err := a.BindAndValidate(c, req, e.Err422EmailConfirmValidateError); err != nil {
+		return err
+	}
+
+	u, err := a.emailChangeUser(c, req)
+	if err != nil {
+		return err
+	}
+	u.ClearPendingEmail()
+	if _, err = a.userService.Update(c.Context(), u); err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Email change error.", e.Err422EmailChangeError, err)
+	}
+
+	return c.Render("general/message.gohtml", view.MakeTemplateData(map[string]any{
+		"Title":   "Email change cancelled",
+		"Message": "Your email stays the same. If you did not ask for the change, reset your password.",
+	}))
+}
+
+// emailChangeUser treats links of a replaced, cancelled or completed change as expired.
+func (a *Controller) emailChangeUser(c fiber.Ctx, req *EmailLink) (*user.User, error) {
+	u, err := a.userService.FindByID(c.Context(), uuid.MustParse(req.ID))
+	if err != nil {
+		return nil, e.NewUnprocessableEntityErrorWrap("Email change error.", e.Err422EmailChangeError, err)
+	}
+	if u == nil || u.PendingEmail == nil || a.authService.EmailFingerprint(u) != req.Fingerprint {
+		return nil, e.NewNotFoundError("Link has expired.", e.Err404URLExpired)
+	}
+	return u, nil
+}
+
+func currentUser(c fiber.Ctx) (*user.User, error) {
+	u, ok := c.Locals("user").(*user.User)
+	if !ok || u == nil {
+		return nil, e.NewUnauthorizedError("Unauthorized", e.Err401UserNotFoundError)
+	}
+	return u, nil
+}
+
+// wantsHTML tells browsers following a mailed link apart from API clients.
+func wantsHTML(c fiber.Ctx) bool {
+	return c.Accepts(fiber.MIMEApplicationJSON, fiber.MIMETextHTML) == fiber.MIMETextHTML
+}
+
+// renderError shows an API error on the message page, validation errors keep their JSON form.
+func renderError(c fiber.Ctx, title string, err error) error {
+	var errNo *e.ErrNo
+	if !errors.As(err, &errNo) {
+		return err
+	}
+	return c.Status(errNo.Status).Render("general/message.gohtml", view.MakeTemplateData(map[string]any{
+		"Title":   title,
+		"Message": errNo.Msg,
+	}))
+}
@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	magicLinkKey  = "auth:magic:"
+	magicLimitKey = "auth:magic:limit:"
+)
+
+// magicLimitScript starts the window with the first request, so a counter never outlives it.
+var magicLimitScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return count
+`)
+
+type MagicLinkOptions struct {
+	Expire time.Duration
+	// Limit is how many links may be requested per Window for the same rate key.
+	Limit  int
+	Window time.Duration
+}
+
+// MagicLinks keeps issued login links in Redis, so each works once whichever instance serves it.
+type MagicLinks struct {
+	client redis.UniversalClient
+	opts   MagicLinkOptions
+}
+
+func NewMagicLinks(client redis.UniversalClient, opts MagicLinkOptions) *MagicLinks {
+	if opts.Expire <= 0 {
+		opts.Expire = 15 * time.Minute //nolint:mnd // default link lifetime
+	}
+	if opts.Window <= 0 {
+		opts.Window = time.Hour
+	}
+
+	return &MagicLinks{
+		client: client,
+		opts:   opts,
+	}
+}
+
+func (m *MagicLinks) Expire() time.Duration {
+	return m.opts.Expire
+}
+
+// Issue stores a new link id for the user until the link expires.
+func (m *MagicLinks) Issue(ctx context.Context, userID string) (string, error) {
+	raw := make([]byte, 16) //nolint:mnd // 128 bits
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	id := hex.EncodeToString(raw)
+	if err := m.client.Set(ctx, magicLinkKey+id, userID, m.opts.Expire).Err(); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// Consume removes the link id and returns the user it was issued for, empty when it was used or expired.
+func (m *MagicLinks) Consume(ctx context.Context, id string) (string, error) {
+	pipe := m.client.TxPipeline()
+	get := pipe.Get(ctx, magicLinkKey+id)
+	pipe.Del(ctx, magicLinkKey+id)
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return "", err
+	}
+	if errors.Is(get.Err(), redis.Nil) {
+		return "", nil
+	}
+	return get.Val(), get.Err()
+}
+
+// Allow counts a request under key within a fixed window and tells whether it is within the limit.
+func (m *MagicLinks) Allow(ctx context.Context, key string) (bool, error) {
+	if m.opts.Limit <= 0 {
+		return true, nil
+	}
+	count, err := magicLimitScript.Run(ctx, m.client, []string{magicLimitKey + key}, m.opts.Window.Milliseconds()).Int64()
+	if err != nil {
+		return false, err
+	}
+	return count <= int64(m.opts.Limit), nil
+}
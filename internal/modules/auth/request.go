@@ -4,11 +4,13 @@ import (
 	"time"
 
 	"github.com/dbunt1tled/fiber-go-api/internal/modules/user"
+	"github.com/dbunt1tled/fiber-go-api/pkg/f"
 )
 
+// Login leaves the password policy out, passwords set under an older policy still sign in.
 type Login struct {
-	Email    string `json:"email"    validate:"required,email,max=70"        example:"fake@example.com"`
-	Password string `json:"password" validate:"required,min=8,max=20,passwd" example:"pas$word1A"`
+	Email    string `json:"email"    validate:"required,email,max=70" example:"fake@example.com"`
+	Password string `json:"password" validate:"required,max=1024"     example:"pas$word1A"`
 }
 
 type Register struct {
@@ -16,23 +18,85 @@ type Register struct {
 	SecondName      string `json:"secondName"      validate:"required,min=2"                                       example:"Dou"`
 	Email           string `json:"email"           validate:"required,email,unique_db=users.email"                 example:"example@example.com"`
 	PhoneNumber     string `json:"phoneNumber"     validate:"required,unique_db=users.phone_number"                example:"+1234567890"`
-	Password        string `json:"password"        validate:"required,min=8,max=20,passwd,eqfield=PasswordConfirm" example:"pas$word1A"`
+	Password        string `json:"password"        validate:"required,passwd,eqfield=PasswordConfirm"              example:"pas$word1A"`
 	PasswordConfirm string `json:"passwordConfirm" validate:"required"                                             example:"pas$word1A"`
 }
 
+func (r Register) PersonalData() []string {
+	return []string{r.Email, r.FirstName, r.SecondName}
+}
+
 func (r Register) ToUser() *user.User {
 	return (&user.User{
-		FirstName:   r.FirstName,
-		SecondName:  r.SecondName,
-		Email:       r.Email,
-		PhoneNumber: r.PhoneNumber,
-		Status:      user.Pending,
-		Roles:       user.Roles{user.Person},
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		FirstName:          r.FirstName,
+		SecondName:         r.SecondName,
+		Email:              r.Email,
+		PhoneNumber:        r.PhoneNumber,
+		Status:             user.Pending,
+		Roles:              user.Roles{user.Person},
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+		ConfirmationSentAt: f.Pointer(time.Now()),
 	}).NextID()
 }
 
 type Confirm struct {
-	Token string `params:"token" json:"token" validate:"required,min=8" example:"random string"`
+	ID          string `params:"id"          json:"id"          validate:"required,uuid"        example:"0e4f8d4c-6c1f-4a44-9c59-6f1f3e2b7a10"`
+	Fingerprint string `params:"fingerprint" json:"fingerprint" validate:"required,hexadecimal" example:"9f86d081884c7d65"`
+}
+
+type ResendConfirm struct {
+	Email string `json:"email" validate:"required,email,max=70" example:"fake@example.com"`
+}
+
+type MagicLink struct {
+	Email string `json:"email" validate:"required,email,max=70" example:"fake@example.com"`
+}
+
+type MagicLinkLogin struct {
+	Token string `params:"token" validate:"required,max=2048" example:"eyJhbGciOiJSUzI1NiJ9..."`
+}
+
+type ForgotPassword struct {
+	Email string `json:"email" validate:"required,email,max=70" example:"fake@example.com"`
+}
+
+type ResetPasswordLink struct {
+	ID          string `params:"id"          json:"id"          validate:"required,uuid"        example:"0e4f8d4c-6c1f-4a44-9c59-6f1f3e2b7a10"`
+	Fingerprint string `params:"fingerprint" json:"fingerprint" validate:"required,hexadecimal" example:"9f86d081884c7d65"`
+}
+
+type ResetPassword struct {
+	ResetPasswordLink
+
+	Password        string `json:"password"        form:"password"        validate:"required,passwd,eqfield=PasswordConfirm" example:"pas$word1A"`
+	PasswordConfirm string `json:"passwordConfirm" form:"passwordConfirm" validate:"required"                                example:"pas$word1A"`
+
+	// user is the owner of the link, set before the password is validated.
+	user *user.User
+}
+
+func (r ResetPassword) PersonalData() []string {
+	if r.user == nil {
+		return nil
+	}
+	return []string{r.user.Email, r.user.FirstName, r.user.SecondName}
+}
+
+type ChangeEmail struct {
+	Email    string `json:"email"    validate:"required,email,max=70,unique_db=users.email" example:"new@example.com"`
+	Password string `json:"password" validate:"required,max=1024"                           example:"pas$word1A"`
+}
+
+// EmailLink identifies a pending email change in the confirm and cancel links.
+type EmailLink struct {
+	ID          string `params:"id"          json:"id"          validate:"required,uuid"        example:"0e4f8d4c-6c1f-4a44-9c59-6f1f3e2b7a10"`
+	Fingerprint string `params:"fingerprint" json:"fingerprint" validate:"required,hexadecimal" example:"9f86d081884c7d65"`
+}
+
+type ConfirmEmail struct {
+	EmailLink
+
+	// Email is the pending address, set from the user to check it is still free.
+	Email string `json:"-" validate:"required,email,unique_db=users.email"`
 }
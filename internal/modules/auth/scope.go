@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/dbunt1tled/fiber-go-api/internal/modules/user"
+)
+
+const (
+	// ScopeRead allows safe methods, ScopeWrite the others.
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+	ScopeAdmin = "admin"
+	// ScopeIntrospect lets a client introspect tokens.
+	ScopeIntrospect = "introspect"
+)
+
+// RoleScopes are the scopes the tokens of a user carry for each of their roles.
+var RoleScopes = map[user.Role][]string{
+	user.Person: {ScopeRead, ScopeWrite},
+	user.Admin:  {ScopeRead, ScopeWrite, ScopeAdmin},
+}
+
+func UserScopes(u *user.User) []string {
+	scopes := make([]string, 0)
+	for _, role := range u.Roles {
+		for _, scope := range RoleScopes[role] {
+			if !slices.Contains(scopes, scope) {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+	return scopes
+}
+
+// HasScopes tells whether the granted scopes include every required one.
+func HasScopes(granted []string, required ...string) bool {
+	for _, scope := range required {
+		if !slices.Contains(granted, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseScope splits a space separated scope claim or parameter.
+func ParseScope(scope string) []string {
+	return strings.Fields(scope)
+}
+
+func FormatScope(scopes []string) string {
+	return strings.Join(scopes, " ")
+}
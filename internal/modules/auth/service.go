@@ -1,36 +1,106 @@
 package auth
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"strings"
 	"time"
 
 	"github.com/dbunt1tled/fiber-go-api/internal/config"
 	"github.com/dbunt1tled/fiber-go-api/internal/modules/user"
+	"github.com/dbunt1tled/fiber-go-api/pkg/audit"
 	"github.com/dbunt1tled/fiber-go-api/pkg/e"
+	"github.com/dbunt1tled/fiber-go-api/pkg/event"
 	"github.com/dbunt1tled/fiber-go-api/pkg/hasher"
+	"github.com/google/uuid"
 )
 
 const BearerSchema = "Bearer "
 const ErrorTokenMsg = "error token"
 
+const (
+	AuditLogin       = "auth.login"
+	AuditLoginFailed = "auth.login_failed"
+	AuditLogout      = "auth.logout"
+)
+
+// Login methods recorded with AuditLogin, identity providers record their name.
+const (
+	LoginPassword  = "password"
+	LoginMagicLink = "magic_link"
+)
+
 type Service struct {
-	hasher *hasher.Hasher
+	hasher   *hasher.Hasher
+	events   event.Publisher
+	magic    *MagicLinks
+	recorder audit.Recorder
 }
 
 func NewAuthService(
 	hasher *hasher.Hasher,
+	events event.Publisher,
+	magic *MagicLinks,
+	recorder audit.Recorder,
 ) *Service {
 	return &Service{
-		hasher: hasher,
+		hasher:   hasher,
+		events:   events,
+		magic:    magic,
+		recorder: recorder,
 	}
 }
 
+func (s *Service) Registered(ctx context.Context, u *user.User) {
+	s.events.Publish(ctx, user.EventRegistered, user.NewEventPayload(u))
+}
+
+func (s *Service) Confirmed(ctx context.Context, u *user.User) {
+	s.events.Publish(ctx, user.EventConfirmed, user.NewEventPayload(u))
+}
+
+// LoggedIn records a sign in of the user by the method.
+func (s *Service) LoggedIn(ctx context.Context, u *user.User, method string) {
+	s.recorder.Record(ctx, audit.Action{
+		Name:       AuditLogin,
+		TargetType: user.AuditTarget,
+		TargetID:   u.ID.String(),
+		Details:    map[string]any{"method": method},
+		ActorID:    &u.ID,
+	})
+}
+
+// LoginFailed records a wrong password for an existing account.
+func (s *Service) LoginFailed(ctx context.Context, u *user.User) {
+	s.recorder.Record(ctx, audit.Action{
+		Name:       AuditLoginFailed,
+		TargetType: user.AuditTarget,
+		TargetID:   u.ID.String(),
+		Details:    map[string]any{"method": LoginPassword},
+	})
+}
+
+func (s *Service) LoggedOut(ctx context.Context, u *user.User) {
+	s.recorder.Record(ctx, audit.Action{
+		Name:       AuditLogout,
+		TargetType: user.AuditTarget,
+		TargetID:   u.ID.String(),
+	})
+}
+
 func (s *Service) GeneratePasswordHash(password string) (string, error) {
 	return s.hasher.HashArgon(password)
 }
 
 func (s *Service) ValidatePassword(password string, encodedHash string) (bool, error) {
-	return s.hasher.CompareArgon(password, encodedHash)
+	return s.hasher.ComparePassword(password, encodedHash)
+}
+
+// NeedsRehash is true for bcrypt hashes and argon2 hashes made with other parameters or pepper.
+func (s *Service) NeedsRehash(encodedHash string) bool {
+	return s.hasher.NeedsRehash(encodedHash)
 }
 
 func (s *Service) GenerateAuthTokens(user *user.User) (string, string, error) {
@@ -38,22 +108,23 @@ func (s *Service) GenerateAuthTokens(user *user.User) (string, string, error) {
 		err             error
 		access, refresh string
 	)
-	access, err = s.hasher.EncodeJWT(map[string]interface{}{
-		"iss": user.ID,
-		"sub": hasher.AccessTokenSubject,
-		"iat": time.Now().Unix(),
-		"exp": time.Now().Add(config.Get().Server.JWT.Expire.Access).Unix(),
-	})
+	access, err = s.hasher.EncodeJWT(withAudience(map[string]interface{}{
+		"iss":   user.ID,
+		"sub":   hasher.AccessTokenSubject,
+		"scope": FormatScope(UserScopes(user)),
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(config.Get().Server.JWT.Expire.Access).Unix(),
+	}))
 	if err != nil {
 		return "", "", err
 	}
 
-	refresh, err = s.hasher.EncodeJWT(map[string]interface{}{
+	refresh, err = s.hasher.EncodeJWT(withAudience(map[string]interface{}{
 		"iss": user.ID,
 		"sub": hasher.RefreshTokenSubject,
 		"iat": time.Now().Unix(),
 		"exp": time.Now().Add(config.Get().Server.JWT.Expire.Refresh).Unix(),
-	})
+	}))
 	if err != nil {
 		return "", "", err
 	}
@@ -61,6 +132,87 @@ func (s *Service) GenerateAuthTokens(user *user.User) (string, string, error) {
 	return access, refresh, nil
 }
 
+// GenerateClientToken issues the access token of the client credentials grant and returns how long it lives.
+func (s *Service) GenerateClientToken(id uuid.UUID, clientID string, scopes []string) (string, time.Duration, error) {
+	expire := config.Get().Server.JWT.Expire.Access
+	token, err := s.hasher.EncodeJWT(withAudience(map[string]interface{}{
+		"iss":       id,
+		"sub":       hasher.ClientTokenSubject,
+		"client_id": clientID,
+		"scope":     FormatScope(scopes),
+		"iat":       time.Now().Unix(),
+		"exp":       time.Now().Add(expire).Unix(),
+	}))
+	return token, expire, err
+}
+
+// GenerateImpersonationToken issues an access token of the user for the admin in act (RFC 8693), sid names
+// the impersonation it belongs to and has to be running for the token to authenticate.
+func (s *Service) GenerateImpersonationToken(
+	u *user.User,
+	actorID uuid.UUID,
+	sessionID uuid.UUID,
+	expiresAt time.Time,
+) (string, error) {
+	return s.hasher.EncodeJWT(withAudience(map[string]interface{}{
+		"iss":   u.ID,
+		"sub":   hasher.AccessTokenSubject,
+		"scope": FormatScope(UserScopes(u)),
+		"act":   map[string]interface{}{"sub": actorID.String()},
+		"sid":   sessionID.String(),
+		"iat":   time.Now().Unix(),
+		"exp":   expiresAt.Unix(),
+	}))
+}
+
+// MagicLinkToken issues a single-use login token, its id is kept server-side until it is exchanged or expires.
+func (s *Service) MagicLinkToken(ctx context.Context, u *user.User) (string, error) {
+	id, err := s.magic.Issue(ctx, u.ID.String())
+	if err != nil {
+		return "", err
+	}
+
+	return s.hasher.EncodeJWT(map[string]interface{}{
+		"iss": u.ID,
+		"sub": hasher.MagicLinkSubject,
+		"jti": id,
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(s.magic.Expire()).Unix(),
+	})
+}
+
+// ConsumeMagicLink checks a magic link token and spends it, returning the id of the user it was issued for.
+func (s *Service) ConsumeMagicLink(ctx context.Context, token string) (uuid.UUID, error) {
+	claims, err := s.hasher.DecodeJWT(token, hasher.WithSubject(hasher.MagicLinkSubject))
+	if err != nil {
+		return uuid.Nil, e.NewUnauthorizedError("Link is invalid or has expired.", e.Err401MagicLinkError)
+	}
+	id, _ := claims["jti"].(string)
+	iss, _ := claims["iss"].(string)
+	if id == "" || iss == "" {
+		return uuid.Nil, e.NewUnauthorizedError("Link is invalid or has expired.", e.Err401MagicLinkError)
+	}
+
+	userID, err := s.magic.Consume(ctx, id)
+	if err != nil {
+		return uuid.Nil, e.NewUnprocessableEntityErrorWrap("Magic link error.", e.Err422MagicLinkError, err)
+	}
+	if userID == "" || userID != iss {
+		return uuid.Nil, e.NewUnauthorizedError("Link has already been used or has expired.", e.Err401MagicLinkError)
+	}
+
+	return uuid.Parse(userID)
+}
+
+// AllowMagicLink rate limits link requests by a caller attribute such as the address or IP.
+func (s *Service) AllowMagicLink(ctx context.Context, key string) (bool, error) {
+	return s.magic.Allow(ctx, key)
+}
+
+func (s *Service) MagicLinkExpire() time.Duration {
+	return s.magic.Expire()
+}
+
 func (s *Service) DecodeBearerToken(authorization string, opts ...hasher.DecodeOpt) (map[string]interface{}, error) {
 	if !strings.HasPrefix(authorization, BearerSchema) {
 		return nil, e.NewUnauthorizedError("Unauthorized", e.Err401TokenNotFoundError)
@@ -80,6 +232,9 @@ func (s *Service) DecodeToken(token string, opts ...hasher.DecodeOpt) (map[strin
 	if token == "" {
 		return nil, e.NewUnprocessableEntityError(ErrorTokenMsg, e.Err422TokenEmptyError)
 	}
+	if audience := config.Get().Server.JWT.Audience; audience != "" {
+		opts = append(opts, hasher.WithAudience(audience))
+	}
 	t, err := s.hasher.DecodeJWT(token, opts...)
 	if err != nil {
 		return nil, e.NewUnprocessableEntityError(ErrorTokenMsg, e.Err422TokenError)
@@ -88,15 +243,45 @@ func (s *Service) DecodeToken(token string, opts ...hasher.DecodeOpt) (map[strin
 	return t, err
 }
 
-func (s *Service) GenerateConfirmToken(user *user.User) (string, error) {
-	return s.hasher.EncodeJWT(map[string]interface{}{
-		"iss": user.ID,
-		"sub": hasher.ConfirmTokenSubject,
-		"iat": time.Now().Unix(),
-		"exp": time.Now().Add(config.Get().Server.JWT.Expire.Confirm).Unix(),
-	})
+// withAudience adds the configured audience to the claims of access and refresh tokens.
+func withAudience(claims map[string]interface{}) map[string]interface{} {
+	if audience := config.Get().Server.JWT.Audience; audience != "" {
+		claims["aud"] = audience
+	}
+	return claims
+}
+
+func (s *Service) JWKS() hasher.JWKS {
+	return s.hasher.JWKS()
+}
+
+// ConfirmFingerprint goes into confirmation links, sending a new confirmation mail invalidates the earlier ones.
+func (s *Service) ConfirmFingerprint(u *user.User) string {
+	var sentAt int64
+	if u.ConfirmationSentAt != nil {
+		sentAt = u.ConfirmationSentAt.UnixMicro()
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%d", u.ID, sentAt)))
+	return hex.EncodeToString(sum[:8]) //nolint:mnd // 64 bits are enough to tell mails apart
+}
+
+// CanResendConfirm throttles confirmation mails per address.
+func (s *Service) CanResendConfirm(u *user.User) bool {
+	return u.ConfirmationSentAt == nil ||
+		time.Since(*u.ConfirmationSentAt) >= config.Get().Server.Auth.ConfirmResend
+}
+
+// EmailFingerprint goes into email change links, a newer request or a completed change invalidates them.
+func (s *Service) EmailFingerprint(u *user.User) string {
+	if u.PendingEmail == nil || u.PendingEmailAt == nil {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%d", *u.PendingEmail, u.PendingEmailAt.UnixMicro())))
+	return hex.EncodeToString(sum[:8]) //nolint:mnd // 64 bits are enough to tell requests apart
 }
 
-func (s *Service) DecodeConfirmToken(tokenConfirm string) (map[string]interface{}, error) {
-	return s.DecodeToken(tokenConfirm, hasher.WithSubject(hasher.ConfirmTokenSubject))
+// PasswordFingerprint goes into password reset links so they stop working once the password changes.
+func (s *Service) PasswordFingerprint(u *user.User) string {
+	sum := sha256.Sum256([]byte(u.Password))
+	return hex.EncodeToString(sum[:8]) //nolint:mnd // 64 bits are enough to tell passwords apart
 }
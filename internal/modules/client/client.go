@@ -0,0 +1,33 @@
+package client
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Client is a registered machine client of the client credentials grant.
+type Client struct {
+	ID         uuid.UUID `db:"id"          json:"id"`
+	Name       string    `db:"name"        json:"name"`
+	ClientID   string    `db:"client_id"   json:"clientId"`
+	SecretHash string    `db:"secret_hash" json:"-"`
+	// Scopes are the most a token of the client can carry.
+	Scopes    []string  `db:"scopes"     json:"scopes"`
+	Active    bool      `db:"active"     json:"active"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+func (c *Client) TableName() string  { return "oauth_clients" }
+func (c *Client) GetID() uuid.UUID   { return c.ID }
+func (c *Client) SetID(id uuid.UUID) { c.ID = id }
+func (c *Client) NextID() *Client {
+	var err error
+	c.ID, err = uuid.NewV7()
+	if err != nil {
+		panic(fmt.Errorf("failed to generate uuid: %w", err))
+	}
+	return c
+}
@@ -0,0 +1,293 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/dbunt1tled/fiber-go-api/internal/modules/auth"
+	"github.com/dbunt1tled/fiber-go-api/internal/modules/impersonation"
+	"github.com/dbunt1tled/fiber-go-api/internal/modules/user"
+	"github.com/dbunt1tled/fiber-go-api/pkg/e"
+	"github.com/dbunt1tled/fiber-go-api/pkg/hasher"
+	"github.com/dbunt1tled/fiber-go-api/pkg/http"
+	"github.com/dbunt1tled/fiber-go-api/pkg/storage"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+)
+
+const GrantClientCredentials = "client_credentials"
+
+type Controller struct {
+	http.BaseController
+
+	clientService        *Service
+	impersonationService *impersonation.Service
+	authService          *auth.Service
+	userService          *user.Service
+}
+
+func NewController(
+	clientService *Service,
+	impersonationService *impersonation.Service,
+	authService *auth.Service,
+	userService *user.Service,
+	validation *validator.Validate,
+) *Controller {
+	return &Controller{
+		BaseController:       http.NewBaseController(validation),
+		clientService:        clientService,
+		impersonationService: impersonationService,
+		authService:          authService,
+		userService:          userService,
+	}
+}
+
+func (cc *Controller) List(c fiber.Ctx) error {
+	req := new(ListRequest)
+	if err := cc.BindAndValidate(c, req, e.Err422ClientValidateError); err != nil {
+		return err
+	}
+
+	clients, err := cc.clientService.Paginate(
+		c.Context(),
+		req.Page.Page,
+		req.Page.Limit,
+		storage.WithSort(req.Sort.Field, req.Sort.Order),
+	)
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Client list error.", e.Err422ClientListError, err)
+	}
+
+	return cc.JSON200(c, NewClientListResponse(clients))
+}
+
+func (cc *Controller) Create(c fiber.Ctx) error {
+	req := new(Create)
+	if err := cc.BindAndValidate(c, req, e.Err422ClientValidateError); err != nil {
+		return err
+	}
+
+	cl, secret, err := cc.clientService.Create(c.Context(), req.Name, req.Scopes)
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Client create error.", e.Err422ClientCreateError, err)
+	}
+
+	return cc.JSON201(c, NewCreatedClientResponse(cl, secret))
+}
+
+func (cc *Controller) Deactivate(c fiber.Ctx) error {
+	req := new(Deactivate)
+	if err := cc.BindAndValidate(c, req, e.Err422ClientValidateError); err != nil {
+		return err
+	}
+
+	cl, err := cc.clientService.Deactivate(c.Context(), uuid.MustParse(req.ID))
+	if errors.Is(err, ErrNotFound) {
+		return e.NewNotFoundError(err.Error(), e.Err404ClientNotFound)
+	}
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Client deactivate error.", e.Err422ClientDeactivateError, err)
+	}
+
+	return cc.JSON200(c, NewClientResponse(cl))
+}
+
+// Token is the token endpoint of the client credentials grant, RFC 6749 section 4.4.
+// Its errors follow section 5.2 instead of the JSON:API error documents of the other endpoints.
+func (cc *Controller) Token(c fiber.Ctx) error {
+	req := new(Token)
+	if err := c.Bind().Body(req); err != nil {
+		return tokenError(c, fiber.StatusBadRequest, "invalid_request", "The request body is malformed.")
+	}
+
+	clientID, secret, basic := Credentials(c)
+	if basic && (req.ClientID != "" || req.ClientSecret != "") {
+		return tokenError(c, fiber.StatusBadRequest, "invalid_request", "Use a single client authentication method.")
+	}
+	if !basic {
+		clientID, secret = req.ClientID, req.ClientSecret
+	}
+	if req.GrantType == "" {
+		return tokenError(c, fiber.StatusBadRequest, "invalid_request", "The grant_type parameter is missing.")
+	}
+
+	cl, err := cc.clientService.Authenticate(c.Context(), clientID, secret)
+	if errors.Is(err, ErrInvalidClient) {
+		c.Set(fiber.HeaderWWWAuthenticate, `Basic realm="token"`)
+		return tokenError(c, fiber.StatusUnauthorized, "invalid_client", "Client authentication failed.")
+	}
+	if err != nil {
+		return err
+	}
+
+	if req.GrantType != GrantClientCredentials {
+		return tokenError(c, fiber.StatusBadRequest, "unsupported_grant_type", "Only the client_credentials grant is supported.")
+	}
+
+	scopes := cl.Scopes
+	if req.Scope != "" {
+		scopes = auth.ParseScope(req.Scope)
+		if !auth.HasScopes(cl.Scopes, scopes...) {
+			return tokenError(c, fiber.StatusBadRequest, "invalid_scope", "The requested scope exceeds the scope granted to the client.")
+		}
+	}
+
+	token, expire, err := cc.authService.GenerateClientToken(cl.ID, cl.ClientID, scopes)
+	if err != nil {
+		return err
+	}
+
+	noStore(c)
+	return cc.JSON200(c, &TokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(expire.Seconds()),
+		Scope:       auth.FormatScope(scopes),
+	})
+}
+
+// Introspect tells a client with the introspect scope whether a token is active, RFC 7662.
+// Tokens of users who are no longer active and of deactivated clients are reported inactive.
+func (cc *Controller) Introspect(c fiber.Ctx) error {
+	req := new(Introspect)
+	if err := cc.BindAndValidate(c, req, e.Err422IntrospectValidateError); err != nil {
+		return err
+	}
+
+	res, err := cc.introspect(c.Context(), req.Token)
+	if err != nil {
+		return err
+	}
+
+	noStore(c)
+	return cc.JSON200(c, res)
+}
+
+func (cc *Controller) introspect(ctx context.Context, token string) (*Introspection, error) {
+	inactive := &Introspection{Active: false}
+
+	claims, err := cc.authService.DecodeToken(token)
+	if err != nil {
+		return inactive, nil //nolint:nilerr // an invalid token is an inactive one
+	}
+	iss, _ := claims["iss"].(string)
+	id, err := uuid.Parse(iss)
+	if err != nil {
+		return inactive, nil //nolint:nilerr // an invalid token is an inactive one
+	}
+	scope, _ := claims["scope"].(string)
+
+	res := &Introspection{
+		Active:    true,
+		TokenType: "Bearer",
+		Exp:       unix(claims["exp"]),
+		Iat:       unix(claims["iat"]),
+		Sub:       iss,
+		Aud:       claims["aud"],
+	}
+
+	switch claims["sub"] {
+	case hasher.AccessTokenSubject:
+		u, err := cc.userService.FindByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if u == nil || u.Status != user.Active {
+			return inactive, nil
+		}
+		if scope == "" {
+			scope = auth.FormatScope(auth.UserScopes(u))
+		}
+		if act, ok := claims["act"]; ok {
+			active, err := cc.impersonated(ctx, claims)
+			if err != nil {
+				return nil, err
+			}
+			if !active {
+				return inactive, nil
+			}
+			res.Act = act
+		}
+		res.Username = u.Email
+	case hasher.ClientTokenSubject:
+		cl, err := cc.clientService.FindByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if cl == nil || !cl.Active {
+			return inactive, nil
+		}
+		res.ClientID = cl.ClientID
+	default:
+		return inactive, nil
+	}
+	res.Scope = scope
+
+	return res, nil
+}
+
+// impersonated tells whether the impersonation of the token still runs.
+func (cc *Controller) impersonated(ctx context.Context, claims map[string]interface{}) (bool, error) {
+	sid, _ := claims["sid"].(string)
+	id, err := uuid.Parse(sid)
+	if err != nil {
+		return false, nil //nolint:nilerr // an invalid token is an inactive one
+	}
+	i, _, err := cc.impersonationService.Active(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	return i != nil, nil
+}
+
+// Credentials reads client credentials from HTTP Basic authentication, RFC 6749 section 2.3.1
+// form-encodes both parts before they are joined.
+func Credentials(c fiber.Ctx) (string, string, bool) {
+	scheme, encoded, found := strings.Cut(c.Get(fiber.HeaderAuthorization), " ")
+	if !found || !strings.EqualFold(scheme, "Basic") {
+		return "", "", false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return "", "", false
+	}
+	rawID, rawSecret, found := strings.Cut(string(decoded), ":")
+	if !found {
+		return "", "", false
+	}
+	clientID, err := url.QueryUnescape(rawID)
+	if err != nil {
+		return "", "", false
+	}
+	secret, err := url.QueryUnescape(rawSecret)
+	if err != nil {
+		return "", "", false
+	}
+	return clientID, secret, true
+}
+
+func tokenError(c fiber.Ctx, status int, code string, description string) error {
+	noStore(c)
+	return c.Status(status).JSON(&TokenError{Error: code, ErrorDescription: description})
+}
+
+func noStore(c fiber.Ctx) {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Set(fiber.HeaderPragma, "no-cache")
+}
+
+func unix(claim any) int64 {
+	switch v := claim.(type) {
+	case float64:
+		return int64(v)
+	case int64:
+		return v
+	case int:
+		return int64(v)
+	}
+	return 0
+}
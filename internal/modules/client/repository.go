@@ -0,0 +1,61 @@
+package client
+
+import (
+	"github.com/dbunt1tled/fiber-go-api/pkg/storage"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	*storage.Repository[*Client]
+}
+
+func NewClientRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{
+		Repository: storage.NewRepository[*Client](
+			pool,
+			"oauth_clients",
+			scanClient,
+			scanClients,
+			buildClientRecord,
+		),
+	}
+}
+
+func scanClient(row pgx.Row) (*Client, error) {
+	var c Client
+
+	err := row.Scan(
+		&c.ID,
+		&c.Name,
+		&c.ClientID,
+		&c.SecretHash,
+		&c.Scopes,
+		&c.Active,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+func scanClients(rows pgx.Rows) ([]*Client, error) {
+	return storage.ScanRowsWithScanner(rows, scanClient)
+}
+
+func buildClientRecord(c *Client) goqu.Record {
+	return goqu.Record{
+		"id":          c.ID,
+		"name":        c.Name,
+		"client_id":   c.ClientID,
+		"secret_hash": c.SecretHash,
+		"scopes":      storage.ToPgArray(c.Scopes),
+		"active":      c.Active,
+		"updated_at":  goqu.L("NOW()"),
+		"created_at":  c.CreatedAt,
+	}
+}
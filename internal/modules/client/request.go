@@ -0,0 +1,31 @@
+package client
+
+import (
+	"github.com/dbunt1tled/fiber-go-api/pkg/http/dto"
+)
+
+type ListRequest struct {
+	dto.PaginationQuery
+}
+
+type Create struct {
+	Name   string   `json:"name"   validate:"required,max=100"                                             example:"billing service"`
+	Scopes []string `json:"scopes" validate:"required,min=1,unique,dive,oneof=read write admin introspect" example:"read"`
+}
+
+type Deactivate struct {
+	ID string `params:"id" json:"id" validate:"required,uuid" example:"0e4f8d4c-6c1f-4a44-9c59-6f1f3e2b7a10"`
+}
+
+// Token is the form of the token endpoint, the client may authenticate with HTTP Basic instead of the body credentials.
+type Token struct {
+	GrantType    string `form:"grant_type"    json:"grant_type"    example:"client_credentials"`
+	Scope        string `form:"scope"         json:"scope"         example:"read"`
+	ClientID     string `form:"client_id"     json:"client_id"     example:"3kTMd8bG0qf1Xx2LmP9aZr4c"`
+	ClientSecret string `form:"client_secret" json:"client_secret" example:"..."`
+}
+
+type Introspect struct {
+	Token         string `form:"token"           json:"token"           validate:"required,max=4096" example:"eyJhbGciOiJSUzI1NiJ9..."`
+	TokenTypeHint string `form:"token_type_hint" json:"token_type_hint" validate:"omitempty,max=50"  example:"access_token"`
+}
@@ -0,0 +1,60 @@
+package client
+
+import (
+	"github.com/dbunt1tled/fiber-go-api/pkg/http/dto"
+	"github.com/dbunt1tled/fiber-go-api/pkg/storage"
+)
+
+func NewClientResource(cl *Client) *dto.Resource {
+	resource := dto.NewResource("client", cl.ID.String())
+	resource.MarshalAttributes(cl)
+	return resource
+}
+
+func NewClientResponse(cl *Client) *dto.Document {
+	return dto.NewResponse().SetData(NewClientResource(cl)).Build()
+}
+
+// NewCreatedClientResponse is the only response carrying the client secret.
+func NewCreatedClientResponse(cl *Client, secret string) *dto.Document {
+	resource := NewClientResource(cl)
+	resource.SetAttribute("clientSecret", secret)
+	return dto.NewResponse().SetData(resource).Build()
+}
+
+func NewClientListResponse(cl *storage.Paginator[*Client]) *dto.Document {
+	resources := make([]*dto.Resource, len(cl.Items))
+	for i, item := range cl.Items {
+		resources[i] = NewClientResource(item)
+	}
+	return dto.NewResponse().SetData(resources).SetMetaPagination(cl).Build()
+}
+
+// TokenResponse is the access token response of RFC 6749, section 5.1.
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	Scope       string `json:"scope"`
+}
+
+// TokenError is the error response of RFC 6749, section 5.2.
+type TokenError struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// Introspection is the response of RFC 7662, inactive tokens carry nothing but active.
+type Introspection struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	Exp       int64  `json:"exp,omitempty"`
+	Iat       int64  `json:"iat,omitempty"`
+	Sub       string `json:"sub,omitempty"`
+	Aud       any    `json:"aud,omitempty"`
+	// Act is the admin impersonating the user, RFC 8693 section 4.1.
+	Act any `json:"act,omitempty"`
+}
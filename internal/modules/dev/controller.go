@@ -0,0 +1,130 @@
+package dev
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/dbunt1tled/fiber-go-api/internal/lib/aemail"
+	"github.com/dbunt1tled/fiber-go-api/internal/lib/view"
+	"github.com/dbunt1tled/fiber-go-api/internal/modules/user"
+	"github.com/dbunt1tled/fiber-go-api/pkg/e"
+	"github.com/dbunt1tled/fiber-go-api/pkg/http"
+	"github.com/dbunt1tled/fiber-go-api/pkg/queue"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+)
+
+// MailController renders the mail catalog for template development.
+// It is only wired in develop environments.
+type MailController struct {
+	http.BaseController
+
+	emailHandler *queue.EmailHandler
+}
+
+func NewMailController(
+	emailHandler *queue.EmailHandler,
+	validation *validator.Validate,
+) *MailController {
+	return &MailController{
+		BaseController: http.NewBaseController(validation),
+		emailHandler:   emailHandler,
+	}
+}
+
+func (mc *MailController) List(c fiber.Ctx) error {
+	return c.Render("dev/mail/list.gohtml", view.MakeTemplateData(map[string]any{
+		"Mails": aemail.Catalog(),
+	}))
+}
+
+func (mc *MailController) Show(c fiber.Ctx) error {
+	req := new(MailPreview)
+	if err := mc.BindAndValidate(c, req, e.Err422MailPreviewValidateError); err != nil {
+		return err
+	}
+
+	mail, payload, err := mc.build(req.Name, "")
+	if err != nil {
+		return err
+	}
+
+	return c.Render("dev/mail/show.gohtml", view.MakeTemplateData(map[string]any{
+		"Mail":    mail,
+		"Payload": payload,
+		"Sent":    c.Query("sent"),
+	}))
+}
+
+func (mc *MailController) HTML(c fiber.Ctx) error {
+	req := new(MailPreview)
+	if err := mc.BindAndValidate(c, req, e.Err422MailPreviewValidateError); err != nil {
+		return err
+	}
+
+	_, payload, err := mc.build(req.Name, "")
+	if err != nil {
+		return err
+	}
+
+	c.Type("html", "utf-8")
+	return c.SendString(payload.Body)
+}
+
+func (mc *MailController) Text(c fiber.Ctx) error {
+	req := new(MailPreview)
+	if err := mc.BindAndValidate(c, req, e.Err422MailPreviewValidateError); err != nil {
+		return err
+	}
+
+	_, payload, err := mc.build(req.Name, "")
+	if err != nil {
+		return err
+	}
+
+	c.Type("txt", "utf-8")
+	return c.SendString(payload.Text)
+}
+
+func (mc *MailController) Send(c fiber.Ctx) error {
+	req := new(MailSend)
+	if err := mc.BindAndValidate(c, req, e.Err422MailPreviewValidateError); err != nil {
+		return err
+	}
+
+	_, payload, err := mc.build(req.Name, req.To)
+	if err != nil {
+		return err
+	}
+
+	if err = mc.emailHandler.Send(c.Context(), *payload); err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Send email error.", e.Err422MailPreviewSendError, err)
+	}
+
+	return c.Redirect().To(fmt.Sprintf("/dev/mail/%s?sent=%s", req.Name, url.QueryEscape(payload.To)))
+}
+
+func (mc *MailController) build(name string, to string) (aemail.Mail, *queue.EmailPayload, error) {
+	mail, ok := aemail.Find(name)
+	if !ok {
+		return aemail.Mail{}, nil, e.NewNotFoundError("Mail type not found", e.Err404MailPreviewNotFound)
+	}
+
+	data := mail.Fixture()
+	if to == "" {
+		if u, ok := data["User"].(user.User); ok {
+			to = u.Email
+		}
+	}
+
+	payload, err := mail.Build(to, data)
+	if err != nil {
+		return aemail.Mail{}, nil, e.NewUnprocessableEntityErrorWrap(
+			"Render email error.",
+			e.Err422MailPreviewRenderError,
+			err,
+		)
+	}
+
+	return mail, payload, nil
+}
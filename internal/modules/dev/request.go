@@ -0,0 +1,11 @@
+package dev
+
+type MailPreview struct {
+	Name string `params:"name" validate:"required,max=50" example:"confirm"`
+}
+
+type MailSend struct {
+	MailPreview
+
+	To string `form:"to" json:"to" validate:"omitempty,email,max=70" example:"fake@example.com"`
+}
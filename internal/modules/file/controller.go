@@ -0,0 +1,243 @@
+package file
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/dbunt1tled/fiber-go-api/internal/modules/user"
+	"github.com/dbunt1tled/fiber-go-api/pkg/e"
+	"github.com/dbunt1tled/fiber-go-api/pkg/filestore"
+	"github.com/dbunt1tled/fiber-go-api/pkg/http"
+	"github.com/dbunt1tled/fiber-go-api/pkg/storage"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+)
+
+const formField = "file"
+
+type Controller struct {
+	http.BaseController
+
+	fileService *Service
+	userService *user.Service
+}
+
+func NewController(
+	fileService *Service,
+	userService *user.Service,
+	validation *validator.Validate,
+) *Controller {
+	return &Controller{
+		BaseController: http.NewBaseController(validation),
+		fileService:    fileService,
+		userService:    userService,
+	}
+}
+
+func (fc *Controller) List(c fiber.Ctx) error {
+	u, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+	req := new(ListRequest)
+	if err = fc.BindAndValidate(c, req, e.Err422FileValidateError); err != nil {
+		return err
+	}
+
+	files, err := fc.fileService.Paginate(
+		c.Context(),
+		u.ID,
+		req.Page.Page,
+		req.Page.Limit,
+		storage.WithFilter(storage.NewRule("mime", storage.OpEqual, req.Mime)),
+		storage.WithSort(req.Sort.Field, req.Sort.Order),
+	)
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("File list error.", e.Err422FileListError, err)
+	}
+
+	return fc.JSON200(c, NewFileListResponse(files, fc.fileService.URL))
+}
+
+func (fc *Controller) Upload(c fiber.Ctx) error {
+	u, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+	fh, err := c.FormFile(formField)
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("File is required.", e.Err422FileValidateError, err)
+	}
+
+	f, err := fc.fileService.Upload(c.Context(), u.ID, fh)
+	if err != nil {
+		return uploadError(err, e.Err422FileUploadError)
+	}
+
+	return fc.JSON201(c, NewFileResponse(f, fc.fileService.URL(f)))
+}
+
+func (fc *Controller) Show(c fiber.Ctx) error {
+	f, err := fc.find(c)
+	if err != nil {
+		return err
+	}
+
+	return fc.JSON200(c, NewFileResponse(f, fc.fileService.URL(f)))
+}
+
+func (fc *Controller) Delete(c fiber.Ctx) error {
+	f, err := fc.find(c)
+	if err != nil {
+		return err
+	}
+	if err = fc.fileService.Delete(c.Context(), f); err != nil {
+		return e.NewUnprocessableEntityErrorWrap("File delete error.", e.Err422FileDeleteError, err)
+	}
+
+	return fc.JSON200(c, NewFileResponse(f, ""))
+}
+
+// Download serves the file behind a signed link, so it needs no authentication.
+func (fc *Controller) Download(c fiber.Ctx) error {
+	req := new(Download)
+	if err := fc.BindAndValidate(c, req, e.Err422FileValidateError); err != nil {
+		return err
+	}
+
+	f, err := fc.fileService.FindByID(c.Context(), uuid.MustParse(req.ID))
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("File download error.", e.Err422FileDownloadError, err)
+	}
+	if f == nil {
+		return e.NewNotFoundError("File not found.", e.Err404FileNotFound)
+	}
+	body, err := fc.fileService.Open(c.Context(), f)
+	switch {
+	case errors.Is(err, filestore.ErrNotFound):
+		return e.NewNotFoundError("File not found.", e.Err404FileNotFound)
+	case err != nil:
+		return e.NewUnprocessableEntityErrorWrap("File download error.", e.Err422FileDownloadError, err)
+	}
+
+	c.Set(fiber.HeaderContentType, f.Mime)
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	c.Set(fiber.HeaderContentDisposition, mime.FormatMediaType(disposition(f.Mime), map[string]string{"filename": f.Name}))
+	c.Set(fiber.HeaderCacheControl, fmt.Sprintf("private, max-age=%d", max(req.Expires-time.Now().Unix(), 0)))
+	c.Set(fiber.HeaderETag, strconv.Quote(f.Checksum))
+
+	return c.SendStream(body, int(f.Size))
+}
+
+func (fc *Controller) SetAvatar(c fiber.Ctx) error {
+	u, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+	fh, err := c.FormFile(formField)
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("File is required.", e.Err422FileValidateError, err)
+	}
+
+	u, f, err := fc.fileService.SetAvatar(c.Context(), u, fh)
+	if err != nil {
+		return uploadError(err, e.Err422AvatarError)
+	}
+
+	return fc.JSON200(c, NewAvatarResponse(u, fc.fileService.URL(f)))
+}
+
+func (fc *Controller) RemoveAvatar(c fiber.Ctx) error {
+	u, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	u, err = fc.fileService.RemoveAvatar(c.Context(), u)
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Avatar delete error.", e.Err422AvatarError, err)
+	}
+
+	return fc.JSON200(c, NewAvatarResponse(u, ""))
+}
+
+// Avatar redirects to a fresh signed link of the avatar of any user.
+func (fc *Controller) Avatar(c fiber.Ctx) error {
+	req := new(UserAvatar)
+	if err := fc.BindAndValidate(c, req, e.Err422FileValidateError); err != nil {
+		return err
+	}
+
+	u, err := fc.userService.FindByID(c.Context(), uuid.MustParse(req.ID))
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Avatar error.", e.Err422AvatarError, err)
+	}
+	if u == nil {
+		return e.NewNotFoundError("User not found.", e.Err404UserNotFound)
+	}
+	if u.AvatarID == nil {
+		return e.NewNotFoundError("Avatar not found.", e.Err404FileNotFound)
+	}
+	f, err := fc.fileService.FindByID(c.Context(), *u.AvatarID)
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Avatar error.", e.Err422AvatarError, err)
+	}
+	if f == nil {
+		return e.NewNotFoundError("Avatar not found.", e.Err404FileNotFound)
+	}
+
+	return c.Redirect().Status(fiber.StatusFound).To(fc.fileService.URL(f))
+}
+
+func (fc *Controller) find(c fiber.Ctx) (*File, error) {
+	u, err := currentUser(c)
+	if err != nil {
+		return nil, err
+	}
+	req := new(Show)
+	if err = fc.BindAndValidate(c, req, e.Err422FileValidateError); err != nil {
+		return nil, err
+	}
+
+	f, err := fc.fileService.FindForUser(c.Context(), u.ID, uuid.MustParse(req.ID))
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return nil, e.NewNotFoundError("File not found.", e.Err404FileNotFound)
+	case err != nil:
+		return nil, e.NewUnprocessableEntityErrorWrap("File error.", e.Err422FileListError, err)
+	}
+	return f, nil
+}
+
+func currentUser(c fiber.Ctx) (*user.User, error) {
+	u, ok := c.Locals("user").(*user.User)
+	if !ok || u == nil {
+		return nil, e.NewUnauthorizedError("Unauthorized", e.Err401UserNotFoundError)
+	}
+	return u, nil
+}
+
+// disposition shows images and PDFs in the browser; anything else is downloaded, so an upload
+// never renders as a page of the API origin.
+func disposition(mimeType string) string {
+	if mimeType == "application/pdf" || slices.Contains(ImageTypes, mimeType) {
+		return "inline"
+	}
+	return "attachment"
+}
+
+func uploadError(err error, code int) error {
+	switch {
+	case errors.Is(err, ErrTooLarge):
+		return e.NewUnprocessableEntityError(err.Error(), e.Err422FileTooLargeError)
+	case errors.Is(err, ErrTypeNotAllowed):
+		return e.NewUnprocessableEntityError(err.Error(), e.Err422FileTypeError)
+	default:
+		return e.NewUnprocessableEntityErrorWrap("File upload error.", code, err)
+	}
+}
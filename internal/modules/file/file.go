@@ -0,0 +1,34 @@
+package file
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type File struct {
+	ID        uuid.UUID `db:"id"         json:"id"`
+	UserID    uuid.UUID `db:"user_id"    json:"userId"`
+	Driver    string    `db:"driver"     json:"-"`
+	Key       string    `db:"key"        json:"-"`
+	Name      string    `db:"name"       json:"name"`
+	Mime      string    `db:"mime"       json:"mime"`
+	Size      int64     `db:"size"       json:"size"`
+	Checksum  string    `db:"checksum"   json:"checksum"`
+	Metadata  []byte    `db:"metadata"   json:"-"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+func (f *File) TableName() string  { return "files" }
+func (f *File) GetID() uuid.UUID   { return f.ID }
+func (f *File) SetID(id uuid.UUID) { f.ID = id }
+func (f *File) NextID() *File {
+	var err error
+	f.ID, err = uuid.NewV7()
+	if err != nil {
+		panic(fmt.Errorf("failed to generate uuid: %w", err))
+	}
+	return f
+}
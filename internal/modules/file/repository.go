@@ -0,0 +1,72 @@
+package file
+
+import (
+	"github.com/dbunt1tled/fiber-go-api/pkg/storage"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	*storage.Repository[*File]
+}
+
+func NewFileRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{
+		Repository: storage.NewRepository[*File](
+			pool,
+			"files",
+			scanFile,
+			scanFiles,
+			buildFileRecord,
+		),
+	}
+}
+
+func scanFile(row pgx.Row) (*File, error) {
+	var f File
+
+	err := row.Scan(
+		&f.ID,
+		&f.UserID,
+		&f.Driver,
+		&f.Key,
+		&f.Name,
+		&f.Mime,
+		&f.Size,
+		&f.Checksum,
+		&f.Metadata,
+		&f.CreatedAt,
+		&f.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &f, nil
+}
+
+func scanFiles(rows pgx.Rows) ([]*File, error) {
+	return storage.ScanRowsWithScanner(rows, scanFile)
+}
+
+func buildFileRecord(f *File) goqu.Record {
+	metadata := "{}"
+	if len(f.Metadata) > 0 {
+		metadata = string(f.Metadata)
+	}
+
+	return goqu.Record{
+		"id":         f.ID,
+		"user_id":    f.UserID,
+		"driver":     f.Driver,
+		"key":        f.Key,
+		"name":       f.Name,
+		"mime":       f.Mime,
+		"size":       f.Size,
+		"checksum":   f.Checksum,
+		"metadata":   metadata,
+		"updated_at": goqu.L("NOW()"),
+		"created_at": f.CreatedAt,
+	}
+}
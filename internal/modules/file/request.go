@@ -0,0 +1,22 @@
+package file
+
+import "github.com/dbunt1tled/fiber-go-api/pkg/http/dto"
+
+type ListRequest struct {
+	dto.PaginationQuery
+
+	Mime *string `query:"mime" json:"mime" validate:"omitempty,max=100" example:"image/png"`
+}
+
+type Show struct {
+	ID string `params:"id" json:"id" validate:"required,uuid" example:"0e4f8d4c-6c1f-4a44-9c59-6f1f3e2b7a10"`
+}
+
+type Download struct {
+	ID      string `params:"id"     json:"id"      validate:"required,uuid" example:"0e4f8d4c-6c1f-4a44-9c59-6f1f3e2b7a10"`
+	Expires int64  `query:"expires" json:"expires" validate:"required,gt=0" example:"1767225600"`
+}
+
+type UserAvatar struct {
+	ID string `params:"id" json:"id" validate:"required,uuid" example:"0e4f8d4c-6c1f-4a44-9c59-6f1f3e2b7a10"`
+}
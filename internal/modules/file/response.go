@@ -0,0 +1,42 @@
+package file
+
+import (
+	"encoding/json"
+
+	"github.com/dbunt1tled/fiber-go-api/internal/modules/user"
+	"github.com/dbunt1tled/fiber-go-api/pkg/http/dto"
+	"github.com/dbunt1tled/fiber-go-api/pkg/storage"
+)
+
+func NewFileResource(f *File, url string) *dto.Resource {
+	resource := dto.NewResource("file", f.ID.String())
+	resource.MarshalAttributes(f)
+	metadata := json.RawMessage("{}")
+	if len(f.Metadata) > 0 {
+		metadata = f.Metadata
+	}
+	resource.SetAttribute("metadata", metadata)
+	resource.SetAttribute("url", url)
+	resource.SetRelationship("user", "user", f.UserID.String())
+	return resource
+}
+
+func NewFileResponse(f *File, url string) *dto.Document {
+	return dto.NewResponse().SetData(NewFileResource(f, url)).Build()
+}
+
+func NewFileListResponse(f *storage.Paginator[*File], url func(*File) string) *dto.Document {
+	resources := make([]*dto.Resource, len(f.Items))
+	for i, file := range f.Items {
+		resources[i] = NewFileResource(file, url(file))
+	}
+	return dto.NewResponse().SetData(resources).SetMetaPagination(f).Build()
+}
+
+func NewAvatarResponse(u *user.User, url string) *dto.Document {
+	resource := user.NewUserResource(u)
+	if url != "" {
+		resource.SetAttribute("avatarUrl", url)
+	}
+	return dto.NewResponse().SetData(resource).Build()
+}
@@ -0,0 +1,287 @@
+package file
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"  // register decoder for image metadata
+	_ "image/jpeg" // register decoder for image metadata
+	_ "image/png"  // register decoder for image metadata
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/dbunt1tled/fiber-go-api/internal/modules/user"
+	"github.com/dbunt1tled/fiber-go-api/pkg/filestore"
+	"github.com/dbunt1tled/fiber-go-api/pkg/hasher"
+	"github.com/dbunt1tled/fiber-go-api/pkg/log"
+	"github.com/dbunt1tled/fiber-go-api/pkg/storage"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const sniffSize = 512
+
+var (
+	ErrTooLarge       = errors.New("file is too large")
+	ErrTypeNotAllowed = errors.New("file type is not allowed")
+	ErrNotFound       = errors.New("file not found")
+)
+
+var ImageTypes = []string{"image/png", "image/jpeg", "image/gif", "image/webp"}
+
+type Options struct {
+	BaseURL string
+	// MaxSize is in bytes; multipart bodies are also capped by the HTTP body limit.
+	MaxSize      int64
+	AllowedTypes []string
+	URLExpire    time.Duration
+}
+
+type Service struct {
+	fileRepository *Repository
+	userService    *user.Service
+	driver         filestore.Driver
+	signer         *hasher.URLSigner
+	opts           Options
+}
+
+func NewFileService(
+	pool *pgxpool.Pool,
+	userService *user.Service,
+	driver filestore.Driver,
+	signer *hasher.URLSigner,
+	opts Options,
+) *Service {
+	if opts.URLExpire <= 0 {
+		opts.URLExpire = 15 * time.Minute //nolint:mnd // default link lifetime
+	}
+
+	return &Service{
+		fileRepository: NewFileRepository(pool),
+		userService:    userService,
+		driver:         driver,
+		signer:         signer,
+		opts:           opts,
+	}
+}
+
+func (s *Service) FindByID(ctx context.Context, id uuid.UUID) (*File, error) {
+	return s.fileRepository.FindByID(ctx, id)
+}
+
+func (s *Service) FindForUser(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*File, error) {
+	f, err := s.fileRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if f == nil || f.UserID != userID {
+		return nil, ErrNotFound
+	}
+	return f, nil
+}
+
+func (s *Service) Paginate(
+	ctx context.Context,
+	userID uuid.UUID,
+	page int,
+	perPage int,
+	opts ...storage.QueryOption,
+) (*storage.Paginator[*File], error) {
+	opts = append(opts, storage.WithFilter(storage.NewRule("user_id", storage.OpEqual, userID)))
+	return s.fileRepository.Paginate(ctx, page, perPage, opts...)
+}
+
+// Upload stores the multipart file of the user. The type is sniffed from the
+// content, never taken from the client, and types narrows the allowed list.
+func (s *Service) Upload(ctx context.Context, userID uuid.UUID, fh *multipart.FileHeader, types ...string) (*File, error) {
+	if s.opts.MaxSize > 0 && fh.Size > s.opts.MaxSize {
+		return nil, fmt.Errorf("%w: max %d bytes", ErrTooLarge, s.opts.MaxSize)
+	}
+	src, err := fh.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	head := make([]byte, sniffSize)
+	n, err := io.ReadFull(src, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	mimeType := detect(head[:n])
+	if !s.allowed(mimeType, types) {
+		return nil, fmt.Errorf("%w: %s", ErrTypeNotAllowed, mimeType)
+	}
+	if _, err = src.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	metadata, err := s.metadata(src, mimeType)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = src.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	f := (&File{
+		UserID:    userID,
+		Driver:    s.driver.Name(),
+		Name:      path.Base(strings.ReplaceAll(fh.Filename, "\\", "/")),
+		Mime:      mimeType,
+		Size:      fh.Size,
+		Metadata:  metadata,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}).NextID()
+	f.Key = f.CreatedAt.Format("2006/01/") + f.ID.String() + extension(f.Name, mimeType)
+
+	hash := sha256.New()
+	if err = s.driver.Put(ctx, f.Key, io.TeeReader(src, hash), f.Size, f.Mime); err != nil {
+		return nil, fmt.Errorf("store file: %w", err)
+	}
+	f.Checksum = hex.EncodeToString(hash.Sum(nil))
+
+	stored, err := s.fileRepository.Insert(ctx, f)
+	if err != nil {
+		s.remove(ctx, f)
+		return nil, err
+	}
+
+	return stored, nil
+}
+
+func (s *Service) Open(ctx context.Context, f *File) (io.ReadCloser, error) {
+	return s.driver.Get(ctx, f.Key)
+}
+
+func (s *Service) Delete(ctx context.Context, f *File) error {
+	if err := s.fileRepository.Delete(ctx, f.ID); err != nil {
+		return err
+	}
+	s.remove(ctx, f)
+	return nil
+}
+
+// URL returns the signed download link of the file, valid for Options.URLExpire.
+func (s *Service) URL(f *File) string {
+	link, err := s.signer.Sign(
+		strings.TrimRight(s.opts.BaseURL, "/")+"/api/files/"+f.ID.String()+"/download",
+		s.opts.URLExpire,
+	)
+	if err != nil {
+		// the base url comes from config, so this is a misconfiguration
+		panic(fmt.Errorf("sign file url: %w", err))
+	}
+	return link
+}
+
+// SetAvatar replaces the avatar of the user, deleting the previous one.
+func (s *Service) SetAvatar(ctx context.Context, u *user.User, fh *multipart.FileHeader) (*user.User, *File, error) {
+	f, err := s.Upload(ctx, u.ID, fh, ImageTypes...)
+	if err != nil {
+		return nil, nil, err
+	}
+	previous := u.AvatarID
+	u.AvatarID = &f.ID
+	u, err = s.userService.Update(ctx, u)
+	if err != nil {
+		_ = s.Delete(ctx, f)
+		return nil, nil, err
+	}
+	s.deleteByID(ctx, previous)
+
+	return u, f, nil
+}
+
+func (s *Service) RemoveAvatar(ctx context.Context, u *user.User) (*user.User, error) {
+	previous := u.AvatarID
+	u.AvatarID = nil
+	u, err := s.userService.Update(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	s.deleteByID(ctx, previous)
+
+	return u, nil
+}
+
+func (s *Service) deleteByID(ctx context.Context, id *uuid.UUID) {
+	if id == nil {
+		return
+	}
+	f, err := s.fileRepository.FindByID(ctx, *id)
+	if err == nil && f != nil {
+		err = s.Delete(ctx, f)
+	}
+	if err != nil {
+		log.Logger().ErrorContext(ctx, "Filestore: delete file error", err, "file", *id)
+	}
+}
+
+// remove drops the stored object; an orphan object is only logged.
+func (s *Service) remove(ctx context.Context, f *File) {
+	if err := s.driver.Delete(ctx, f.Key); err != nil {
+		log.Logger().ErrorContext(ctx, "Filestore: delete object error", err, "key", f.Key)
+	}
+}
+
+func (s *Service) allowed(mimeType string, types []string) bool {
+	if len(types) > 0 && !slices.Contains(types, mimeType) {
+		return false
+	}
+	return len(s.opts.AllowedTypes) == 0 || slices.Contains(s.opts.AllowedTypes, mimeType)
+}
+
+func (s *Service) metadata(src io.Reader, mimeType string) ([]byte, error) {
+	if !strings.HasPrefix(mimeType, "image/") {
+		return nil, nil
+	}
+	cfg, _, err := image.DecodeConfig(src)
+	if err != nil {
+		// formats without a registered decoder carry no dimensions
+		return nil, nil //nolint:nilerr // metadata is best effort
+	}
+	return sonic.ConfigFastest.Marshal(map[string]int{"width": cfg.Width, "height": cfg.Height})
+}
+
+func detect(head []byte) string {
+	mimeType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil {
+		return "application/octet-stream"
+	}
+	return mimeType
+}
+
+// extension keeps the client extension when it matches the sniffed type.
+func extension(name string, mimeType string) string {
+	exts, err := mime.ExtensionsByType(mimeType)
+	if err != nil || len(exts) == 0 {
+		return ""
+	}
+	ext := strings.ToLower(path.Ext(name))
+	if slices.Contains(exts, ext) {
+		return ext
+	}
+	return exts[0]
+}
+
+// Types parses the comma separated list of allowed MIME types.
+func Types(list string) []string {
+	types := make([]string, 0)
+	for _, t := range strings.Split(list, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			types = append(types, t)
+		}
+	}
+	return types
+}
@@ -0,0 +1,256 @@
+package identity
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/dbunt1tled/fiber-go-api/internal/modules/auth"
+	"github.com/dbunt1tled/fiber-go-api/internal/modules/user"
+	"github.com/dbunt1tled/fiber-go-api/pkg/e"
+	"github.com/dbunt1tled/fiber-go-api/pkg/f"
+	"github.com/dbunt1tled/fiber-go-api/pkg/http"
+	"github.com/dbunt1tled/fiber-go-api/pkg/http/dto"
+	"github.com/dbunt1tled/fiber-go-api/pkg/oauth"
+	"github.com/dbunt1tled/fiber-go-api/pkg/storage"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+)
+
+type Controller struct {
+	http.BaseController
+
+	identityService *Service
+	authService     *auth.Service
+	userService     *user.Service
+}
+
+func NewController(
+	identityService *Service,
+	authService *auth.Service,
+	userService *user.Service,
+	validation *validator.Validate,
+) *Controller {
+	return &Controller{
+		BaseController:  http.NewBaseController(validation),
+		identityService: identityService,
+		authService:     authService,
+		userService:     userService,
+	}
+}
+
+func (ic *Controller) Providers(c fiber.Ctx) error {
+	return ic.JSON200(c, dto.NewResponse().SetMeta("providers", ic.identityService.Providers()).Build())
+}
+
+// Login sends the user agent to the provider, the flow comes back through Callback.
+func (ic *Controller) Login(c fiber.Ctx) error {
+	req := new(Provider)
+	if err := ic.BindAndValidate(c, req, e.Err422IdentityValidateError); err != nil {
+		return err
+	}
+
+	url, err := ic.identityService.Start(c.Context(), req.Provider, nil)
+	if err != nil {
+		return startError(err)
+	}
+	return c.Redirect().Status(fiber.StatusFound).To(url)
+}
+
+// Link starts the flow for the signed in user; API clients can't follow a redirect with their token, so the URL is returned.
+func (ic *Controller) Link(c fiber.Ctx) error {
+	u, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+	req := new(Provider)
+	if err = ic.BindAndValidate(c, req, e.Err422IdentityValidateError); err != nil {
+		return err
+	}
+
+	url, err := ic.identityService.Start(c.Context(), req.Provider, &u.ID)
+	if err != nil {
+		return startError(err)
+	}
+	return ic.JSON200(c, dto.NewResponse().SetMeta("url", url).Build())
+}
+
+// Callback links the identity when the flow was started by Link and signs in otherwise. Unknown identities
+// with a verified email get a new active account, unless the email already belongs to one.
+func (ic *Controller) Callback(c fiber.Ctx) error {
+	req := new(Callback)
+	if err := ic.BindAndValidate(c, req, e.Err422IdentityValidateError); err != nil {
+		return err
+	}
+	if req.Error != "" {
+		msg := req.Error
+		if req.ErrorDescription != "" {
+			msg = req.ErrorDescription
+		}
+		return e.NewUnprocessableEntityError("Sign in was not completed: "+msg, e.Err422IdentityProviderError)
+	}
+
+	flow, ext, err := ic.identityService.Finish(c.Context(), req.Provider, req.State, req.Code)
+	switch {
+	case errors.Is(err, ErrUnknownProvider):
+		return e.NewNotFoundError(err.Error(), e.Err404IdentityProviderNotFound)
+	case errors.Is(err, ErrInvalidState):
+		return e.NewUnprocessableEntityError(err.Error(), e.Err422IdentityStateError)
+	case err != nil:
+		return e.NewUnprocessableEntityErrorWrap("Identity provider error.", e.Err422IdentityProviderError, err)
+	}
+
+	if flow.UserID != "" {
+		return ic.link(c, flow, ext)
+	}
+
+	u, err := ic.identityService.SignInUser(c.Context(), ext)
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Sign in error.", e.Err422IdentityLinkError, err)
+	}
+	if u == nil {
+		if u, err = ic.register(c, ext); err != nil {
+			return err
+		}
+	}
+	if u.Status != user.Active {
+		return e.NewUnauthorizedError("Unauthorized", e.Err401IdentityUserNotActiveError)
+	}
+
+	access, refresh, err := ic.authService.GenerateAuthTokens(u)
+	if err != nil {
+		return e.NewUnprocessableEntityError(
+			err.Error(),
+			e.Err422LoginAccessTokenError,
+		)
+	}
+	ic.authService.LoggedIn(c.Context(), u, req.Provider)
+
+	return ic.JSON200(c, auth.NewLoginResponse(map[string]interface{}{
+		"accessToken":  access,
+		"refreshToken": refresh,
+	}))
+}
+
+func (ic *Controller) List(c fiber.Ctx) error {
+	u, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	identities, err := ic.identityService.List(c.Context(), u.ID)
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Identity list error.", e.Err422IdentityListError, err)
+	}
+	return ic.JSON200(c, NewIdentityListResponse(identities))
+}
+
+func (ic *Controller) Unlink(c fiber.Ctx) error {
+	u, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+	req := new(Unlink)
+	if err = ic.BindAndValidate(c, req, e.Err422IdentityValidateError); err != nil {
+		return err
+	}
+
+	err = ic.identityService.Unlink(c.Context(), u.ID, uuid.MustParse(req.ID))
+	if errors.Is(err, ErrNotFound) {
+		return e.NewNotFoundError(err.Error(), e.Err404IdentityNotFound)
+	}
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Identity unlink error.", e.Err422IdentityUnlinkError, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (ic *Controller) link(c fiber.Ctx, flow *Flow, ext *oauth.Identity) error {
+	userID, err := uuid.Parse(flow.UserID)
+	if err != nil {
+		return e.NewUnprocessableEntityError(ErrInvalidState.Error(), e.Err422IdentityStateError)
+	}
+
+	i, err := ic.identityService.Link(c.Context(), userID, ext)
+	switch {
+	case errors.Is(err, ErrLinkedElsewhere), errors.Is(err, ErrAlreadyLinked):
+		return e.NewUnprocessableEntityError(err.Error(), e.Err422IdentityLinkError)
+	case err != nil:
+		return e.NewUnprocessableEntityErrorWrap("Identity link error.", e.Err422IdentityLinkError, err)
+	}
+	return ic.JSON200(c, NewIdentityResponse(i))
+}
+
+// register creates the account of a first sign in. Its password is random, a password can be set through the reset flow.
+func (ic *Controller) register(c fiber.Ctx, ext *oauth.Identity) (*user.User, error) {
+	if ext.Email == "" || !ext.EmailVerified {
+		return nil, e.NewUnprocessableEntityError(
+			"The provider did not confirm your email, sign up first and link the provider from your account.",
+			e.Err422IdentityEmailNotVerifiedError,
+		)
+	}
+	taken, err := ic.userService.One(
+		c.Context(),
+		storage.WithFilter(storage.NewRule("email", storage.OpEqual, ext.Email)),
+	)
+	if err != nil {
+		return nil, e.NewUnprocessableEntityErrorWrap("Sign in error.", e.Err422IdentityUserCreateError, err)
+	}
+	if taken != nil {
+		return nil, e.NewUnprocessableEntityError(
+			"An account with this email exists, sign in and link the provider from your account.",
+			e.Err422IdentityEmailTakenError,
+		)
+	}
+
+	secret := make([]byte, 32) //nolint:mnd // 256 bits
+	if _, err = rand.Read(secret); err != nil {
+		return nil, e.NewUnprocessableEntityErrorWrap("Sign in error.", e.Err422IdentityUserCreateError, err)
+	}
+	password, err := ic.authService.GeneratePasswordHash(hex.EncodeToString(secret))
+	if err != nil {
+		return nil, e.NewUnprocessableEntityErrorWrap("Sign in error.", e.Err422IdentityUserCreateError, err)
+	}
+
+	firstName := ext.FirstName
+	if firstName == "" {
+		firstName, _, _ = strings.Cut(ext.Email, "@")
+	}
+	u := (&user.User{
+		FirstName:   firstName,
+		SecondName:  ext.LastName,
+		Email:       ext.Email,
+		Status:      user.Active,
+		Roles:       user.Roles{user.Person},
+		ConfirmedAt: f.Pointer(time.Now()),
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}).NextID().WithPassword(password)
+	u.Sanitize()
+
+	u, err = ic.identityService.CreateUser(c.Context(), u, ext)
+	if err != nil {
+		return nil, e.NewUnprocessableEntityErrorWrap("Sign in error.", e.Err422IdentityUserCreateError, err)
+	}
+	ic.authService.Registered(c.Context(), u)
+
+	return u, nil
+}
+
+func startError(err error) error {
+	if errors.Is(err, ErrUnknownProvider) {
+		return e.NewNotFoundError(err.Error(), e.Err404IdentityProviderNotFound)
+	}
+	return e.NewUnprocessableEntityErrorWrap("Identity provider error.", e.Err422IdentityStartError, err)
+}
+
+func currentUser(c fiber.Ctx) (*user.User, error) {
+	u, ok := c.Locals("user").(*user.User)
+	if !ok || u == nil {
+		return nil, e.NewUnauthorizedError("Unauthorized", e.Err401UserNotFoundError)
+	}
+	return u, nil
+}
@@ -0,0 +1,31 @@
+package identity
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Identity links the subject of an external identity provider to a user.
+type Identity struct {
+	ID        uuid.UUID `db:"id"         json:"id"`
+	UserID    uuid.UUID `db:"user_id"    json:"userId"`
+	Provider  string    `db:"provider"   json:"provider"`
+	Subject   string    `db:"subject"    json:"-"`
+	Email     *string   `db:"email"      json:"email"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+func (i *Identity) TableName() string  { return "user_identities" }
+func (i *Identity) GetID() uuid.UUID   { return i.ID }
+func (i *Identity) SetID(id uuid.UUID) { i.ID = id }
+func (i *Identity) NextID() *Identity {
+	var err error
+	i.ID, err = uuid.NewV7()
+	if err != nil {
+		panic(fmt.Errorf("failed to generate uuid: %w", err))
+	}
+	return i
+}
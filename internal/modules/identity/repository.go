@@ -0,0 +1,59 @@
+package identity
+
+import (
+	"github.com/dbunt1tled/fiber-go-api/pkg/storage"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	*storage.Repository[*Identity]
+}
+
+func NewIdentityRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{
+		Repository: storage.NewRepository[*Identity](
+			pool,
+			"user_identities",
+			scanIdentity,
+			scanIdentities,
+			buildIdentityRecord,
+		),
+	}
+}
+
+func scanIdentity(row pgx.Row) (*Identity, error) {
+	var i Identity
+
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Provider,
+		&i.Subject,
+		&i.Email,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &i, nil
+}
+
+func scanIdentities(rows pgx.Rows) ([]*Identity, error) {
+	return storage.ScanRowsWithScanner(rows, scanIdentity)
+}
+
+func buildIdentityRecord(i *Identity) goqu.Record {
+	return goqu.Record{
+		"id":         i.ID,
+		"user_id":    i.UserID,
+		"provider":   i.Provider,
+		"subject":    i.Subject,
+		"email":      i.Email,
+		"updated_at": goqu.L("NOW()"),
+		"created_at": i.CreatedAt,
+	}
+}
@@ -0,0 +1,18 @@
+package identity
+
+type Provider struct {
+	Provider string `params:"provider" json:"provider" validate:"required,max=50" example:"google"`
+}
+
+type Callback struct {
+	Provider string `params:"provider" json:"provider" validate:"required,max=50" example:"google"`
+	// Error is set by the provider instead of Code when the user declined or the request was rejected.
+	Error            string `query:"error"             json:"error"            validate:"max=255"                          example:"access_denied"`
+	ErrorDescription string `query:"error_description" json:"errorDescription" validate:"max=1024"                         example:"The user denied access"`
+	Code             string `query:"code"              json:"code"             validate:"required_without=Error,max=2048" example:"4/0AX4XfWh"`
+	State            string `query:"state"             json:"state"            validate:"required,max=128"                 example:"Zm9vYmFy"`
+}
+
+type Unlink struct {
+	ID string `params:"id" json:"id" validate:"required,uuid" example:"0e4f8d4c-6c1f-4a44-9c59-6f1f3e2b7a10"`
+}
@@ -0,0 +1,24 @@
+package identity
+
+import (
+	"github.com/dbunt1tled/fiber-go-api/pkg/http/dto"
+)
+
+func NewIdentityResource(i *Identity) *dto.Resource {
+	resource := dto.NewResource("identity", i.ID.String())
+	resource.MarshalAttributes(i)
+	resource.SetRelationship("user", "user", i.UserID.String())
+	return resource
+}
+
+func NewIdentityResponse(i *Identity) *dto.Document {
+	return dto.NewResponse().SetData(NewIdentityResource(i)).Build()
+}
+
+func NewIdentityListResponse(identities []*Identity) *dto.Document {
+	resources := make([]*dto.Resource, len(identities))
+	for i, identity := range identities {
+		resources[i] = NewIdentityResource(identity)
+	}
+	return dto.NewResponse().SetData(resources).Build()
+}
@@ -0,0 +1,191 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/dbunt1tled/fiber-go-api/internal/modules/user"
+	"github.com/dbunt1tled/fiber-go-api/pkg/f"
+	"github.com/dbunt1tled/fiber-go-api/pkg/oauth"
+	"github.com/dbunt1tled/fiber-go-api/pkg/storage"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrUnknownProvider = errors.New("unknown identity provider")
+	ErrInvalidState    = errors.New("sign in request is invalid or has expired")
+	ErrLinkedElsewhere = errors.New("this identity is linked to another account")
+	ErrAlreadyLinked   = errors.New("another identity of this provider is linked to the account")
+	ErrNotFound        = errors.New("identity not found")
+)
+
+type Service struct {
+	identityRepository *Repository
+	userService        *user.Service
+	states             *States
+	providers          map[string]oauth.Provider
+}
+
+func NewIdentityService(
+	pool *pgxpool.Pool,
+	userService *user.Service,
+	states *States,
+	providers ...oauth.Provider,
+) *Service {
+	byName := make(map[string]oauth.Provider, len(providers))
+	for _, provider := range providers {
+		byName[provider.Name()] = provider
+	}
+
+	return &Service{
+		identityRepository: NewIdentityRepository(pool),
+		userService:        userService,
+		states:             states,
+		providers:          byName,
+	}
+}
+
+// Providers lists the configured provider names.
+func (s *Service) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Start begins the authorization code flow and returns the provider URL to send the user to.
+// A non-nil userID links the provider to that account instead of signing in.
+func (s *Service) Start(ctx context.Context, name string, userID *uuid.UUID) (string, error) {
+	provider, ok := s.providers[name]
+	if !ok {
+		return "", ErrUnknownProvider
+	}
+	nonce, err := randomString()
+	if err != nil {
+		return "", err
+	}
+	flow := Flow{
+		Provider: name,
+		Nonce:    nonce,
+		Verifier: oauth.Verifier(),
+	}
+	if userID != nil {
+		flow.UserID = userID.String()
+	}
+	state, err := s.states.Save(ctx, flow)
+	if err != nil {
+		return "", err
+	}
+	return provider.AuthCodeURL(ctx, state, flow.Nonce, flow.Verifier)
+}
+
+// Finish spends the state of the callback and exchanges the code for the identity it asserts.
+func (s *Service) Finish(ctx context.Context, name string, state string, code string) (*Flow, *oauth.Identity, error) {
+	provider, ok := s.providers[name]
+	if !ok {
+		return nil, nil, ErrUnknownProvider
+	}
+	flow, err := s.states.Take(ctx, state)
+	if err != nil {
+		return nil, nil, err
+	}
+	if flow == nil || flow.Provider != name {
+		return nil, nil, ErrInvalidState
+	}
+	ext, err := provider.Exchange(ctx, code, flow.Nonce, flow.Verifier)
+	if err != nil {
+		return nil, nil, err
+	}
+	return flow, ext, nil
+}
+
+func (s *Service) FindBySubject(ctx context.Context, provider string, subject string) (*Identity, error) {
+	return s.identityRepository.One(
+		ctx,
+		storage.WithFilter(
+			storage.NewRule("provider", storage.OpEqual, provider),
+			storage.NewRule("subject", storage.OpEqual, subject),
+		),
+	)
+}
+
+// Link attaches the external identity to the user, linking it again to the same user is a no-op.
+func (s *Service) Link(ctx context.Context, userID uuid.UUID, ext *oauth.Identity) (*Identity, error) {
+	existing, err := s.FindBySubject(ctx, ext.Provider, ext.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		if existing.UserID != userID {
+			return nil, ErrLinkedElsewhere
+		}
+		return existing, nil
+	}
+	other, err := s.identityRepository.One(
+		ctx,
+		storage.WithFilter(
+			storage.NewRule("user_id", storage.OpEqual, userID),
+			storage.NewRule("provider", storage.OpEqual, ext.Provider),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if other != nil {
+		return nil, ErrAlreadyLinked
+	}
+
+	i := (&Identity{
+		UserID:   userID,
+		Provider: ext.Provider,
+		Subject:  ext.Subject,
+	}).NextID()
+	if ext.Email != "" {
+		i.Email = f.Pointer(ext.Email)
+	}
+	return s.identityRepository.Insert(ctx, i)
+}
+
+// SignInUser returns the user the identity is linked to, nil when it isn't linked yet.
+func (s *Service) SignInUser(ctx context.Context, ext *oauth.Identity) (*user.User, error) {
+	i, err := s.FindBySubject(ctx, ext.Provider, ext.Subject)
+	if err != nil || i == nil {
+		return nil, err
+	}
+	return s.userService.FindByID(ctx, i.UserID)
+}
+
+// CreateUser registers an active user for a verified identity and links it. The user is removed
+// for good again when linking fails, so a retry doesn't run into the taken email.
+func (s *Service) CreateUser(ctx context.Context, u *user.User, ext *oauth.Identity) (*user.User, error) {
+	u, err := s.userService.Create(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = s.Link(ctx, u.ID, ext); err != nil {
+		return nil, errors.Join(err, s.userService.ForceDelete(ctx, u.ID))
+	}
+	return u, nil
+}
+
+func (s *Service) List(ctx context.Context, userID uuid.UUID) ([]*Identity, error) {
+	return s.identityRepository.List(
+		ctx,
+		storage.WithFilter(storage.NewRule("user_id", storage.OpEqual, userID)),
+	)
+}
+
+func (s *Service) Unlink(ctx context.Context, userID uuid.UUID, id uuid.UUID) error {
+	i, err := s.identityRepository.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if i == nil || i.UserID != userID {
+		return ErrNotFound
+	}
+	return s.identityRepository.Delete(ctx, id)
+}
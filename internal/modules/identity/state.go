@@ -0,0 +1,86 @@
+package identity
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/redis/go-redis/v9"
+)
+
+const stateKey = "auth:oauth:"
+
+// Flow is what the callback needs from the request that started the authorization.
+type Flow struct {
+	Provider string `json:"provider"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	// UserID is set when a signed in user links the provider to their account.
+	UserID string `json:"userId,omitempty"`
+}
+
+// States keeps authorization flows in Redis under their state parameter until the callback spends them.
+type States struct {
+	client redis.UniversalClient
+	expire time.Duration
+}
+
+func NewStates(client redis.UniversalClient, expire time.Duration) *States {
+	if expire <= 0 {
+		expire = 10 * time.Minute //nolint:mnd // default time to finish signing in at the provider
+	}
+
+	return &States{
+		client: client,
+		expire: expire,
+	}
+}
+
+func (s *States) Save(ctx context.Context, flow Flow) (string, error) {
+	state, err := randomString()
+	if err != nil {
+		return "", err
+	}
+	data, err := sonic.Marshal(flow)
+	if err != nil {
+		return "", err
+	}
+	if err = s.client.Set(ctx, stateKey+state, data, s.expire).Err(); err != nil {
+		return "", err
+	}
+	return state, nil
+}
+
+// Take removes the flow of the state, nil when it is unknown, used or expired.
+func (s *States) Take(ctx context.Context, state string) (*Flow, error) {
+	pipe := s.client.TxPipeline()
+	get := pipe.Get(ctx, stateKey+state)
+	pipe.Del(ctx, stateKey+state)
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+	data, err := get.Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var flow Flow
+	if err = sonic.Unmarshal(data, &flow); err != nil {
+		return nil, err
+	}
+	return &flow, nil
+}
+
+func randomString() (string, error) {
+	raw := make([]byte, 32) //nolint:mnd // 256 bits
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
@@ -0,0 +1,143 @@
+package impersonation
+
+import (
+	"errors"
+
+	"github.com/dbunt1tled/fiber-go-api/internal/modules/user"
+	"github.com/dbunt1tled/fiber-go-api/pkg/e"
+	"github.com/dbunt1tled/fiber-go-api/pkg/http"
+	"github.com/dbunt1tled/fiber-go-api/pkg/storage"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+)
+
+type Controller struct {
+	http.BaseController
+
+	impersonationService *Service
+	userService          *user.Service
+}
+
+func NewController(
+	impersonationService *Service,
+	userService *user.Service,
+	validation *validator.Validate,
+) *Controller {
+	return &Controller{
+		BaseController:       http.NewBaseController(validation),
+		impersonationService: impersonationService,
+		userService:          userService,
+	}
+}
+
+func (ic *Controller) List(c fiber.Ctx) error {
+	req := new(ListRequest)
+	if err := ic.BindAndValidate(c, req, e.Err422ImpersonationValidateError); err != nil {
+		return err
+	}
+
+	impersonations, err := ic.impersonationService.Paginate(
+		c.Context(),
+		req.Page.Page,
+		req.Page.Limit,
+		storage.WithFilter(
+			storage.NewRule("actor_id", storage.OpEqual, req.ActorID),
+			storage.NewRule("user_id", storage.OpEqual, req.UserID),
+		),
+		storage.WithSort(req.Sort.Field, req.Sort.Order),
+	)
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Impersonation list error.", e.Err422ImpersonationListError, err)
+	}
+
+	return ic.JSON200(c, NewImpersonationListResponse(impersonations))
+}
+
+// Start lets the signed in admin act as the user, the reason is kept with the session.
+func (ic *Controller) Start(c fiber.Ctx) error {
+	actor, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+	req := new(Start)
+	if err = ic.BindAndValidate(c, req, e.Err422ImpersonationValidateError); err != nil {
+		return err
+	}
+
+	target, err := ic.userService.FindByID(c.Context(), uuid.MustParse(req.ID))
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Impersonation start error.", e.Err422ImpersonationStartError, err)
+	}
+	if target == nil {
+		return e.NewNotFoundError("User not found.", e.Err404UserNotFound)
+	}
+
+	i, token, err := ic.impersonationService.Start(c.Context(), actor, target, req.Reason, c.IP())
+	switch {
+	case errors.Is(err, ErrSelf), errors.Is(err, ErrAdminTarget), errors.Is(err, ErrInactiveTarget):
+		return e.NewUnprocessableEntityError(err.Error(), e.Err422ImpersonationTargetError)
+	case err != nil:
+		return e.NewUnprocessableEntityErrorWrap("Impersonation start error.", e.Err422ImpersonationStartError, err)
+	}
+
+	return ic.JSON201(c, NewStartedImpersonationResponse(i, token))
+}
+
+// Stop ends the session the request is authenticated with.
+func (ic *Controller) Stop(c fiber.Ctx) error {
+	i := Current(c)
+	actor := Actor(c)
+	if i == nil || actor == nil {
+		return e.NewUnprocessableEntityError("You are not impersonating anyone.", e.Err422ImpersonationNotActiveError)
+	}
+
+	i, err := ic.impersonationService.Stop(c.Context(), i.ID, actor.ID)
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Impersonation stop error.", e.Err422ImpersonationStopError, err)
+	}
+
+	return ic.JSON200(c, NewImpersonationResponse(i))
+}
+
+// StopByID lets an admin end any running session.
+func (ic *Controller) StopByID(c fiber.Ctx) error {
+	u, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+	req := new(Stop)
+	if err = ic.BindAndValidate(c, req, e.Err422ImpersonationValidateError); err != nil {
+		return err
+	}
+
+	i, err := ic.impersonationService.Stop(c.Context(), uuid.MustParse(req.ID), u.ID)
+	if errors.Is(err, ErrNotFound) {
+		return e.NewNotFoundError(err.Error(), e.Err404ImpersonationNotFound)
+	}
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Impersonation stop error.", e.Err422ImpersonationStopError, err)
+	}
+
+	return ic.JSON200(c, NewImpersonationResponse(i))
+}
+
+// Actor is the admin acting as the signed in user, nil unless the request is impersonated.
+func Actor(c fiber.Ctx) *user.User {
+	u, _ := c.Locals("actor").(*user.User)
+	return u
+}
+
+// Current is the impersonation the request is authenticated with.
+func Current(c fiber.Ctx) *Impersonation {
+	i, _ := c.Locals("impersonation").(*Impersonation)
+	return i
+}
+
+func currentUser(c fiber.Ctx) (*user.User, error) {
+	u, ok := c.Locals("user").(*user.User)
+	if !ok || u == nil {
+		return nil, e.NewUnauthorizedError("Unauthorized", e.Err401UserNotFoundError)
+	}
+	return u, nil
+}
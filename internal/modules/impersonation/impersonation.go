@@ -0,0 +1,38 @@
+package impersonation
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Impersonation is a session of an admin acting as a user, the rows are the audit trail of who did it and why.
+type Impersonation struct {
+	ID        uuid.UUID  `db:"id"         json:"id"`
+	ActorID   uuid.UUID  `db:"actor_id"   json:"actorId"`
+	UserID    uuid.UUID  `db:"user_id"    json:"userId"`
+	Reason    string     `db:"reason"     json:"reason"`
+	IP        string     `db:"ip"         json:"ip"`
+	ExpiresAt time.Time  `db:"expires_at" json:"expiresAt"`
+	StoppedAt *time.Time `db:"stopped_at" json:"stoppedAt"`
+	StoppedBy *uuid.UUID `db:"stopped_by" json:"stoppedBy"`
+	CreatedAt time.Time  `db:"created_at" json:"createdAt"`
+	UpdatedAt time.Time  `db:"updated_at" json:"updatedAt"`
+}
+
+func (i *Impersonation) TableName() string  { return "impersonations" }
+func (i *Impersonation) GetID() uuid.UUID   { return i.ID }
+func (i *Impersonation) SetID(id uuid.UUID) { i.ID = id }
+func (i *Impersonation) NextID() *Impersonation {
+	var err error
+	i.ID, err = uuid.NewV7()
+	if err != nil {
+		panic(fmt.Errorf("failed to generate uuid: %w", err))
+	}
+	return i
+}
+
+func (i *Impersonation) Active(now time.Time) bool {
+	return i.StoppedAt == nil && now.Before(i.ExpiresAt)
+}
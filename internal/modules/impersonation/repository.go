@@ -0,0 +1,65 @@
+package impersonation
+
+import (
+	"github.com/dbunt1tled/fiber-go-api/pkg/storage"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	*storage.Repository[*Impersonation]
+}
+
+func NewImpersonationRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{
+		Repository: storage.NewRepository[*Impersonation](
+			pool,
+			"impersonations",
+			scanImpersonation,
+			scanImpersonations,
+			buildImpersonationRecord,
+		),
+	}
+}
+
+func scanImpersonation(row pgx.Row) (*Impersonation, error) {
+	var i Impersonation
+
+	err := row.Scan(
+		&i.ID,
+		&i.ActorID,
+		&i.UserID,
+		&i.Reason,
+		&i.IP,
+		&i.ExpiresAt,
+		&i.StoppedAt,
+		&i.StoppedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &i, nil
+}
+
+func scanImpersonations(rows pgx.Rows) ([]*Impersonation, error) {
+	return storage.ScanRowsWithScanner(rows, scanImpersonation)
+}
+
+func buildImpersonationRecord(i *Impersonation) goqu.Record {
+	return goqu.Record{
+		"id":         i.ID,
+		"actor_id":   i.ActorID,
+		"user_id":    i.UserID,
+		"reason":     i.Reason,
+		"ip":         i.IP,
+		"expires_at": i.ExpiresAt,
+		"stopped_at": i.StoppedAt,
+		"stopped_by": i.StoppedBy,
+		"updated_at": goqu.L("NOW()"),
+		"created_at": i.CreatedAt,
+	}
+}
@@ -0,0 +1,19 @@
+package impersonation
+
+import "github.com/dbunt1tled/fiber-go-api/pkg/http/dto"
+
+type ListRequest struct {
+	dto.PaginationQuery
+
+	ActorID *string `query:"actorId" json:"actorId" validate:"omitempty,uuid" example:"0e4f8d4c-6c1f-4a44-9c59-6f1f3e2b7a10"`
+	UserID  *string `query:"userId"  json:"userId"  validate:"omitempty,uuid" example:"0e4f8d4c-6c1f-4a44-9c59-6f1f3e2b7a10"`
+}
+
+type Start struct {
+	ID     string `params:"id" json:"id"     validate:"required,uuid"    example:"0e4f8d4c-6c1f-4a44-9c59-6f1f3e2b7a10"`
+	Reason string `json:"reason" validate:"required,max=255" example:"ticket #1234, checkout fails"`
+}
+
+type Stop struct {
+	ID string `params:"id" json:"id" validate:"required,uuid" example:"0e4f8d4c-6c1f-4a44-9c59-6f1f3e2b7a10"`
+}
@@ -0,0 +1,33 @@
+package impersonation
+
+import (
+	"github.com/dbunt1tled/fiber-go-api/pkg/http/dto"
+	"github.com/dbunt1tled/fiber-go-api/pkg/storage"
+)
+
+func NewImpersonationResource(i *Impersonation) *dto.Resource {
+	resource := dto.NewResource("impersonation", i.ID.String())
+	resource.MarshalAttributes(i)
+	resource.SetRelationship("actor", "user", i.ActorID.String())
+	resource.SetRelationship("user", "user", i.UserID.String())
+	return resource
+}
+
+func NewImpersonationResponse(i *Impersonation) *dto.Document {
+	return dto.NewResponse().SetData(NewImpersonationResource(i)).Build()
+}
+
+// NewStartedImpersonationResponse is the only response carrying the access token of the session.
+func NewStartedImpersonationResponse(i *Impersonation, token string) *dto.Document {
+	resource := NewImpersonationResource(i)
+	resource.SetAttribute("accessToken", token)
+	return dto.NewResponse().SetData(resource).Build()
+}
+
+func NewImpersonationListResponse(i *storage.Paginator[*Impersonation]) *dto.Document {
+	resources := make([]*dto.Resource, len(i.Items))
+	for idx, item := range i.Items {
+		resources[idx] = NewImpersonationResource(item)
+	}
+	return dto.NewResponse().SetData(resources).SetMetaPagination(i).Build()
+}
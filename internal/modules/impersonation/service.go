@@ -0,0 +1,161 @@
+package impersonation
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/dbunt1tled/fiber-go-api/internal/modules/auth"
+	"github.com/dbunt1tled/fiber-go-api/internal/modules/user"
+	"github.com/dbunt1tled/fiber-go-api/pkg/audit"
+	"github.com/dbunt1tled/fiber-go-api/pkg/f"
+	"github.com/dbunt1tled/fiber-go-api/pkg/storage"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	AuditStarted = "impersonation.started"
+	AuditStopped = "impersonation.stopped"
+)
+
+var (
+	ErrNotFound       = errors.New("impersonation not found")
+	ErrSelf           = errors.New("you can't impersonate yourself")
+	ErrAdminTarget    = errors.New("admins can't be impersonated")
+	ErrInactiveTarget = errors.New("only active users can be impersonated")
+)
+
+type Service struct {
+	impersonationRepository *Repository
+	authService             *auth.Service
+	userService             *user.Service
+	recorder                audit.Recorder
+	expire                  time.Duration
+}
+
+func NewImpersonationService(
+	pool *pgxpool.Pool,
+	authService *auth.Service,
+	userService *user.Service,
+	recorder audit.Recorder,
+	expire time.Duration,
+) *Service {
+	return &Service{
+		impersonationRepository: NewImpersonationRepository(pool),
+		authService:             authService,
+		userService:             userService,
+		recorder:                recorder,
+		expire:                  expire,
+	}
+}
+
+func (s *Service) FindByID(ctx context.Context, id uuid.UUID) (*Impersonation, error) {
+	return s.impersonationRepository.FindByID(ctx, id)
+}
+
+func (s *Service) Paginate(
+	ctx context.Context,
+	page int,
+	perPage int,
+	opts ...storage.QueryOption,
+) (*storage.Paginator[*Impersonation], error) {
+	return s.impersonationRepository.Paginate(ctx, page, perPage, opts...)
+}
+
+// Start records the session and issues its access token. There is no refresh token, the admin starts
+// a new session once it expires.
+func (s *Service) Start(
+	ctx context.Context,
+	actor *user.User,
+	target *user.User,
+	reason string,
+	ip string,
+) (*Impersonation, string, error) {
+	switch {
+	case actor.ID == target.ID:
+		return nil, "", ErrSelf
+	case target.HasRole(user.Admin):
+		return nil, "", ErrAdminTarget
+	case target.Status != user.Active:
+		return nil, "", ErrInactiveTarget
+	}
+
+	i, err := s.impersonationRepository.Insert(ctx, (&Impersonation{
+		ActorID:   actor.ID,
+		UserID:    target.ID,
+		Reason:    reason,
+		IP:        ip,
+		ExpiresAt: time.Now().Add(s.expire),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}).NextID())
+	if err != nil {
+		return nil, "", err
+	}
+
+	token, err := s.authService.GenerateImpersonationToken(target, actor.ID, i.ID, i.ExpiresAt)
+	if err != nil {
+		return nil, "", err
+	}
+	s.recorder.Record(ctx, audit.Action{
+		Name:       AuditStarted,
+		TargetType: user.AuditTarget,
+		TargetID:   target.ID.String(),
+		Details:    map[string]any{"impersonation": i.ID, "reason": reason, "expiresAt": i.ExpiresAt},
+		ActorID:    &actor.ID,
+	})
+
+	return i, token, nil
+}
+
+// Stop ends the session, its token stops authenticating right away.
+func (s *Service) Stop(ctx context.Context, id uuid.UUID, by uuid.UUID) (*Impersonation, error) {
+	i, err := s.impersonationRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if i == nil {
+		return nil, ErrNotFound
+	}
+	if i.StoppedAt != nil {
+		return i, nil
+	}
+
+	i.StoppedAt = f.Pointer(time.Now())
+	i.StoppedBy = &by
+	if i, err = s.impersonationRepository.Update(ctx, i); err != nil {
+		return nil, err
+	}
+	// the admin stops the session, also when they do it with its token
+	s.recorder.Record(ctx, audit.Action{
+		Name:       AuditStopped,
+		TargetType: user.AuditTarget,
+		TargetID:   i.UserID.String(),
+		Details:    map[string]any{"impersonation": i.ID, "startedBy": i.ActorID},
+		ActorID:    &by,
+	})
+
+	return i, nil
+}
+
+// Active returns the session and its admin while the session runs and the admin still is an active admin, nil otherwise.
+func (s *Service) Active(ctx context.Context, id uuid.UUID) (*Impersonation, *user.User, error) {
+	i, err := s.impersonationRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if i == nil || !i.Active(time.Now()) {
+		return nil, nil, nil
+	}
+
+	actor, err := s.userService.FindByID(ctx, i.ActorID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if actor == nil || actor.Status != user.Active || !actor.HasRole(user.Admin) {
+		return nil, nil, nil
+	}
+
+	return i, actor, nil
+}
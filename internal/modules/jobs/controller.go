@@ -0,0 +1,219 @@
+package jobs
+
+import (
+	"errors"
+
+	"github.com/dbunt1tled/fiber-go-api/pkg/e"
+	"github.com/dbunt1tled/fiber-go-api/pkg/http"
+	"github.com/dbunt1tled/fiber-go-api/pkg/queue"
+	"github.com/dbunt1tled/fiber-go-api/pkg/storage"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+)
+
+type Controller struct {
+	http.BaseController
+
+	inspector *queue.Inspector
+}
+
+func NewController(
+	inspector *queue.Inspector,
+	validation *validator.Validate,
+) *Controller {
+	return &Controller{
+		BaseController: http.NewBaseController(validation),
+		inspector:      inspector,
+	}
+}
+
+func (jc *Controller) Queues(c fiber.Ctx) error {
+	queues, err := jc.inspector.Queues()
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Queue list error.", e.Err422QueueListError, err)
+	}
+
+	return jc.JSON200(c, NewQueueListResponse(queues))
+}
+
+func (jc *Controller) Queue(c fiber.Ctx) error {
+	req := new(Queue)
+	if err := jc.BindAndValidate(c, req, e.Err422QueueValidateError); err != nil {
+		return err
+	}
+
+	q, err := jc.inspector.Queue(req.Queue)
+	if err != nil {
+		return queueError(err, "Queue list error.", e.Err422QueueListError)
+	}
+
+	return jc.JSON200(c, NewQueueResponse(q))
+}
+
+func (jc *Controller) Tasks(c fiber.Ctx) error {
+	req := new(TaskList)
+	if err := jc.BindAndValidate(c, req, e.Err422QueueValidateError); err != nil {
+		return err
+	}
+
+	q, err := jc.inspector.Queue(req.Queue)
+	if err != nil {
+		return queueError(err, "Task list error.", e.Err422QueueTaskListError)
+	}
+	page, perPage := storage.NormalizePagination(req.Page.Page, req.Page.Limit)
+	tasks, err := jc.inspector.Tasks(req.Queue, req.State, page, perPage)
+	if err != nil {
+		return queueError(err, "Task list error.", e.Err422QueueTaskListError)
+	}
+
+	return jc.JSON200(c, NewTaskListResponse(storage.NewPaginator(tasks, stateTotal(q, req.State), page, perPage)))
+}
+
+func (jc *Controller) Task(c fiber.Ctx) error {
+	req := new(Task)
+	if err := jc.BindAndValidate(c, req, e.Err422QueueValidateError); err != nil {
+		return err
+	}
+
+	t, err := jc.inspector.Task(req.Queue, req.ID)
+	if err != nil {
+		return queueError(err, "Task error.", e.Err422QueueTaskListError)
+	}
+
+	return jc.JSON200(c, NewTaskResponse(t))
+}
+
+func (jc *Controller) RunTask(c fiber.Ctx) error {
+	req := new(Task)
+	if err := jc.BindAndValidate(c, req, e.Err422QueueValidateError); err != nil {
+		return err
+	}
+
+	if err := jc.inspector.RunTask(req.Queue, req.ID); err != nil {
+		return queueError(err, "Task retry error.", e.Err422QueueTaskRunError)
+	}
+
+	return jc.JSON200(c, NewAffectedResponse(1))
+}
+
+func (jc *Controller) DeleteTask(c fiber.Ctx) error {
+	req := new(Task)
+	if err := jc.BindAndValidate(c, req, e.Err422QueueValidateError); err != nil {
+		return err
+	}
+
+	if err := jc.inspector.DeleteTask(req.Queue, req.ID); err != nil {
+		return queueError(err, "Task delete error.", e.Err422QueueTaskDeleteError)
+	}
+
+	return jc.JSON200(c, NewAffectedResponse(1))
+}
+
+func (jc *Controller) RunArchived(c fiber.Ctx) error {
+	req := new(Queue)
+	if err := jc.BindAndValidate(c, req, e.Err422QueueValidateError); err != nil {
+		return err
+	}
+
+	n, err := jc.inspector.RunAllArchived(req.Queue)
+	if err != nil {
+		return queueError(err, "Task retry error.", e.Err422QueueTaskRunError)
+	}
+
+	return jc.JSON200(c, NewAffectedResponse(n))
+}
+
+func (jc *Controller) DeleteArchived(c fiber.Ctx) error {
+	req := new(Queue)
+	if err := jc.BindAndValidate(c, req, e.Err422QueueValidateError); err != nil {
+		return err
+	}
+
+	n, err := jc.inspector.DeleteAllArchived(req.Queue)
+	if err != nil {
+		return queueError(err, "Task delete error.", e.Err422QueueTaskDeleteError)
+	}
+
+	return jc.JSON200(c, NewAffectedResponse(n))
+}
+
+func (jc *Controller) Pause(c fiber.Ctx) error {
+	return jc.togglePause(c, true)
+}
+
+func (jc *Controller) Unpause(c fiber.Ctx) error {
+	return jc.togglePause(c, false)
+}
+
+func (jc *Controller) Cancel(c fiber.Ctx) error {
+	req := new(Cancel)
+	if err := jc.BindAndValidate(c, req, e.Err422QueueValidateError); err != nil {
+		return err
+	}
+
+	if err := jc.inspector.Cancel(req.ID); err != nil {
+		return queueError(err, "Task cancel error.", e.Err422QueueTaskCancelError)
+	}
+
+	return jc.JSON200(c, NewAffectedResponse(1))
+}
+
+func (jc *Controller) togglePause(c fiber.Ctx, pause bool) error {
+	var err error
+	req := new(Queue)
+	if err = jc.BindAndValidate(c, req, e.Err422QueueValidateError); err != nil {
+		return err
+	}
+
+	if pause {
+		err = jc.inspector.Pause(req.Queue)
+	} else {
+		err = jc.inspector.Unpause(req.Queue)
+	}
+	if err != nil {
+		return queueError(err, "Queue pause error.", e.Err422QueuePauseError)
+	}
+
+	q, err := jc.inspector.Queue(req.Queue)
+	if err != nil {
+		return queueError(err, "Queue pause error.", e.Err422QueuePauseError)
+	}
+
+	return jc.JSON200(c, NewQueueResponse(q))
+}
+
+func queueError(err error, msg string, code int) error {
+	if queue.IsNotFound(err) {
+		return e.NewNotFoundError(err.Error(), notFoundCode(err))
+	}
+	if errors.Is(err, queue.ErrUnknownState) {
+		return e.NewUnprocessableEntityError(err.Error(), e.Err422QueueValidateError)
+	}
+	return e.NewUnprocessableEntityErrorWrap(msg, code, err)
+}
+
+func notFoundCode(err error) int {
+	if queue.IsQueueNotFound(err) {
+		return e.Err404QueueNotFound
+	}
+	return e.Err404QueueTaskNotFound
+}
+
+func stateTotal(q *queue.QueueStats, state string) int64 {
+	switch state {
+	case queue.StatePending:
+		return int64(q.Pending)
+	case queue.StateActive:
+		return int64(q.Active)
+	case queue.StateScheduled:
+		return int64(q.Scheduled)
+	case queue.StateRetry:
+		return int64(q.Retry)
+	case queue.StateArchived:
+		return int64(q.Archived)
+	case queue.StateCompleted:
+		return int64(q.Completed)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,30 @@
+package jobs
+
+import "github.com/dbunt1tled/fiber-go-api/pkg/http/dto"
+
+type Queue struct {
+	Queue string `params:"queue" json:"queue" validate:"required,max=100" example:"emails"`
+}
+
+type Task struct {
+	Queue string `params:"queue" json:"queue" validate:"required,max=100" example:"emails"`
+	ID    string `params:"id"    json:"id"    validate:"required,max=100" example:"0e4f8d4c-6c1f-4a44-9c59-6f1f3e2b7a10"`
+}
+
+type Cancel struct {
+	ID string `params:"id" json:"id" validate:"required,max=100" example:"0e4f8d4c-6c1f-4a44-9c59-6f1f3e2b7a10"`
+}
+
+type TaskList struct {
+	dto.PaginationQuery
+
+	Queue string `params:"queue" json:"queue" validate:"required,max=100"                                              example:"emails"`
+	State string `query:"state"  json:"state" validate:"required,oneof=pending active scheduled retry archived completed" example:"archived"`
+}
+
+func (r *TaskList) SetDefaults() {
+	r.PaginationQuery.SetDefaults()
+	if r.State == "" {
+		r.State = "archived"
+	}
+}
@@ -0,0 +1,48 @@
+package jobs
+
+import (
+	"github.com/dbunt1tled/fiber-go-api/pkg/http/dto"
+	"github.com/dbunt1tled/fiber-go-api/pkg/queue"
+	"github.com/dbunt1tled/fiber-go-api/pkg/storage"
+)
+
+func NewQueueResource(q *queue.QueueStats) *dto.Resource {
+	resource := dto.NewResource("queue", q.Queue)
+	resource.MarshalAttributes(q)
+	return resource
+}
+
+func NewQueueResponse(q *queue.QueueStats) *dto.Document {
+	return dto.NewResponse().SetData(NewQueueResource(q)).Build()
+}
+
+func NewQueueListResponse(queues []*queue.QueueStats) *dto.Document {
+	resources := make([]*dto.Resource, len(queues))
+	for i, q := range queues {
+		resources[i] = NewQueueResource(q)
+	}
+	return dto.NewResponse().SetData(resources).Build()
+}
+
+func NewTaskResource(t *queue.TaskView) *dto.Resource {
+	resource := dto.NewResource("task", t.ID)
+	resource.MarshalAttributes(t)
+	resource.SetRelationship("queue", "queue", t.Queue)
+	return resource
+}
+
+func NewTaskResponse(t *queue.TaskView) *dto.Document {
+	return dto.NewResponse().SetData(NewTaskResource(t)).Build()
+}
+
+func NewTaskListResponse(tasks *storage.Paginator[*queue.TaskView]) *dto.Document {
+	resources := make([]*dto.Resource, len(tasks.Items))
+	for i, t := range tasks.Items {
+		resources[i] = NewTaskResource(t)
+	}
+	return dto.NewResponse().SetData(resources).SetMetaPagination(tasks).Build()
+}
+
+func NewAffectedResponse(affected int) *dto.Document {
+	return dto.NewResponse().SetMeta("affected", affected).Build()
+}
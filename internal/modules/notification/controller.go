@@ -0,0 +1,230 @@
+package notification
+
+import (
+	"errors"
+
+	"github.com/dbunt1tled/fiber-go-api/internal/lib/view"
+	"github.com/dbunt1tled/fiber-go-api/internal/modules/user"
+	"github.com/dbunt1tled/fiber-go-api/pkg/e"
+	"github.com/dbunt1tled/fiber-go-api/pkg/http"
+	"github.com/dbunt1tled/fiber-go-api/pkg/storage"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+)
+
+type Controller struct {
+	http.BaseController
+
+	notificationService *Service
+	userService         *user.Service
+}
+
+func NewController(
+	notificationService *Service,
+	userService *user.Service,
+	validation *validator.Validate,
+) *Controller {
+	return &Controller{
+		BaseController:      http.NewBaseController(validation),
+		notificationService: notificationService,
+		userService:         userService,
+	}
+}
+
+func (nc *Controller) List(c fiber.Ctx) error {
+	u, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+	req := new(ListRequest)
+	if err = nc.BindAndValidate(c, req, e.Err422NotificationValidateError); err != nil {
+		return err
+	}
+
+	rules := []storage.Rule{storage.NewRule("type", storage.OpEqual, req.Type)}
+	if req.Unread != nil && *req.Unread {
+		rules = append(rules, storage.NewRule("read_at", storage.OpIsNull, nil))
+	}
+	notifications, err := nc.notificationService.Paginate(
+		c.Context(),
+		u.ID,
+		req.Page.Page,
+		req.Page.Limit,
+		storage.WithFilter(rules...),
+		storage.WithSort(req.Sort.Field, req.Sort.Order),
+	)
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Notification list error.", e.Err422NotificationListError, err)
+	}
+	unread, err := nc.notificationService.UnreadCount(c.Context(), u.ID)
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Notification list error.", e.Err422NotificationListError, err)
+	}
+
+	return nc.JSON200(c, NewNotificationListResponse(notifications, unread))
+}
+
+func (nc *Controller) Read(c fiber.Ctx) error {
+	return nc.markRead(c, true)
+}
+
+func (nc *Controller) Unread(c fiber.Ctx) error {
+	return nc.markRead(c, false)
+}
+
+func (nc *Controller) ReadAll(c fiber.Ctx) error {
+	u, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	n, err := nc.notificationService.MarkAllRead(c.Context(), u.ID)
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Notification update error.", e.Err422NotificationUpdateError, err)
+	}
+
+	return nc.JSON200(c, NewAffectedResponse(n))
+}
+
+func (nc *Controller) Delete(c fiber.Ctx) error {
+	n, err := nc.find(c)
+	if err != nil {
+		return err
+	}
+
+	if err = nc.notificationService.Delete(c.Context(), n); err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Notification delete error.", e.Err422NotificationDeleteError, err)
+	}
+
+	return nc.JSON200(c, NewNotificationResponse(n))
+}
+
+func (nc *Controller) Preferences(c fiber.Ctx) error {
+	u, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	return nc.preferences(c, u.ID)
+}
+
+func (nc *Controller) UpdatePreferences(c fiber.Ctx) error {
+	u, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+	req := new(Preferences)
+	if err = nc.BindAndValidate(c, req, e.Err422NotificationValidateError); err != nil {
+		return err
+	}
+
+	for _, p := range req.Preferences {
+		if _, err = nc.notificationService.SetPreference(c.Context(), u.ID, p.Type, p.Channels); err != nil {
+			return preferenceError(err)
+		}
+	}
+
+	return nc.preferences(c, u.ID)
+}
+
+// Unsubscribe is reached through the signed link at the bottom of notification emails.
+func (nc *Controller) Unsubscribe(c fiber.Ctx) error {
+	req := new(Unsubscribe)
+	if err := nc.BindAndValidate(c, req, e.Err422NotificationValidateError); err != nil {
+		return err
+	}
+
+	_, err := nc.notificationService.Unsubscribe(c.Context(), uuid.MustParse(req.UserID), req.Type)
+	switch {
+	case errors.Is(err, ErrUnknownType):
+		return e.NewUnprocessableEntityError(err.Error(), e.Err422NotificationUnknownTypeError)
+	case err != nil:
+		return e.NewUnprocessableEntityErrorWrap("Unsubscribe error.", e.Err422NotificationUnsubscribeError, err)
+	}
+
+	return c.Render("general/message.gohtml", view.MakeTemplateData(map[string]any{
+		"Title":   "Unsubscribed",
+		"Message": "You will no longer receive these notifications by email.",
+	}))
+}
+
+// Send lets an admin notify a user through the channels the user chose for admin messages.
+func (nc *Controller) Send(c fiber.Ctx) error {
+	req := new(Send)
+	if err := nc.BindAndValidate(c, req, e.Err422NotificationValidateError); err != nil {
+		return err
+	}
+
+	u, err := nc.userService.FindByID(c.Context(), uuid.MustParse(req.UserID))
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Notification send error.", e.Err422NotificationSendError, err)
+	}
+	if u == nil {
+		return e.NewNotFoundError("User not found.", e.Err404UserNotFound)
+	}
+	if err = nc.notificationService.Notify(c.Context(), u.ID, req.ToMessage()); err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Notification send error.", e.Err422NotificationSendError, err)
+	}
+
+	return nc.JSON200(c, NewAffectedResponse(1))
+}
+
+func (nc *Controller) markRead(c fiber.Ctx, read bool) error {
+	n, err := nc.find(c)
+	if err != nil {
+		return err
+	}
+
+	n, err = nc.notificationService.MarkRead(c.Context(), n, read)
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Notification update error.", e.Err422NotificationUpdateError, err)
+	}
+
+	return nc.JSON200(c, NewNotificationResponse(n))
+}
+
+func (nc *Controller) preferences(c fiber.Ctx, userID uuid.UUID) error {
+	preferences, err := nc.notificationService.Preferences(c.Context(), userID)
+	if err != nil {
+		return preferenceError(err)
+	}
+
+	return nc.JSON200(c, NewPreferenceListResponse(preferences))
+}
+
+// find loads a notification of the current user; notifications of others are reported as missing.
+func (nc *Controller) find(c fiber.Ctx) (*Notification, error) {
+	u, err := currentUser(c)
+	if err != nil {
+		return nil, err
+	}
+	req := new(Show)
+	if err = nc.BindAndValidate(c, req, e.Err422NotificationValidateError); err != nil {
+		return nil, err
+	}
+
+	n, err := nc.notificationService.FindForUser(c.Context(), u.ID, uuid.MustParse(req.ID))
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return nil, e.NewNotFoundError("Notification not found.", e.Err404NotificationNotFound)
+	case err != nil:
+		return nil, e.NewUnprocessableEntityErrorWrap("Notification error.", e.Err422NotificationListError, err)
+	}
+	return n, nil
+}
+
+func currentUser(c fiber.Ctx) (*user.User, error) {
+	u, ok := c.Locals("user").(*user.User)
+	if !ok || u == nil {
+		return nil, e.NewUnauthorizedError("Unauthorized", e.Err401UserNotFoundError)
+	}
+	return u, nil
+}
+
+func preferenceError(err error) error {
+	if errors.Is(err, ErrUnknownType) {
+		return e.NewUnprocessableEntityError(err.Error(), e.Err422NotificationUnknownTypeError)
+	}
+	return e.NewUnprocessableEntityErrorWrap("Notification preference error.", e.Err422NotificationPreferenceError, err)
+}
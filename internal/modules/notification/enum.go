@@ -0,0 +1,32 @@
+package notification
+
+import "slices"
+
+type Channel string
+
+type Channels []Channel
+
+const (
+	ChannelInApp Channel = "in_app"
+	ChannelEmail Channel = "email"
+	ChannelSSE   Channel = "sse"
+)
+
+const (
+	TypeAccountConfirmed = "account.confirmed"
+	TypeAccountStatus    = "account.status"
+	TypeAdminMessage     = "admin.message"
+)
+
+// Types lists every notification type with the channels used until the user sets a preference.
+func Types() map[string]Channels {
+	return map[string]Channels{
+		TypeAccountConfirmed: {ChannelInApp, ChannelSSE},
+		TypeAccountStatus:    {ChannelInApp, ChannelEmail, ChannelSSE},
+		TypeAdminMessage:     {ChannelInApp, ChannelSSE},
+	}
+}
+
+func (c Channels) Has(channel Channel) bool {
+	return slices.Contains(c, channel)
+}
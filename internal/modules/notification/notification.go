@@ -0,0 +1,62 @@
+package notification
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Notification struct {
+	ID        uuid.UUID  `db:"id"         json:"id"`
+	UserID    uuid.UUID  `db:"user_id"    json:"userId"`
+	Type      string     `db:"type"       json:"type"`
+	Title     string     `db:"title"      json:"title"`
+	Body      string     `db:"body"       json:"body"`
+	Data      []byte     `db:"data"       json:"-"`
+	ReadAt    *time.Time `db:"read_at"    json:"readAt"`
+	CreatedAt time.Time  `db:"created_at" json:"createdAt"`
+	UpdatedAt time.Time  `db:"updated_at" json:"updatedAt"`
+}
+
+func (n *Notification) TableName() string  { return "notifications" }
+func (n *Notification) GetID() uuid.UUID   { return n.ID }
+func (n *Notification) SetID(id uuid.UUID) { n.ID = id }
+func (n *Notification) NextID() *Notification {
+	n.ID = nextID()
+	return n
+}
+
+type Preference struct {
+	ID        uuid.UUID `db:"id"         json:"id"`
+	UserID    uuid.UUID `db:"user_id"    json:"userId"`
+	Type      string    `db:"type"       json:"type"`
+	Channels  Channels  `db:"channels"   json:"channels"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+func (p *Preference) TableName() string  { return "notification_preferences" }
+func (p *Preference) GetID() uuid.UUID   { return p.ID }
+func (p *Preference) SetID(id uuid.UUID) { p.ID = id }
+func (p *Preference) NextID() *Preference {
+	p.ID = nextID()
+	return p
+}
+
+// Message is what other modules send; the service decides the channels.
+type Message struct {
+	Type  string
+	Title string
+	Body  string
+	Link  string
+	Data  map[string]any
+}
+
+func nextID() uuid.UUID {
+	id, err := uuid.NewV7()
+	if err != nil {
+		panic(fmt.Errorf("failed to generate uuid: %w", err))
+	}
+	return id
+}
@@ -0,0 +1,117 @@
+package notification
+
+import (
+	"github.com/dbunt1tled/fiber-go-api/pkg/storage"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	*storage.Repository[*Notification]
+}
+
+type PreferenceRepository struct {
+	*storage.Repository[*Preference]
+}
+
+func NewNotificationRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{
+		Repository: storage.NewRepository[*Notification](
+			pool,
+			"notifications",
+			scanNotification,
+			scanNotifications,
+			buildNotificationRecord,
+		),
+	}
+}
+
+func NewPreferenceRepository(pool *pgxpool.Pool) *PreferenceRepository {
+	return &PreferenceRepository{
+		Repository: storage.NewRepository[*Preference](
+			pool,
+			"notification_preferences",
+			scanPreference,
+			scanPreferences,
+			buildPreferenceRecord,
+		),
+	}
+}
+
+func scanNotification(row pgx.Row) (*Notification, error) {
+	var n Notification
+
+	err := row.Scan(
+		&n.ID,
+		&n.UserID,
+		&n.Type,
+		&n.Title,
+		&n.Body,
+		&n.Data,
+		&n.ReadAt,
+		&n.CreatedAt,
+		&n.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &n, nil
+}
+
+func scanNotifications(rows pgx.Rows) ([]*Notification, error) {
+	return storage.ScanRowsWithScanner(rows, scanNotification)
+}
+
+func buildNotificationRecord(n *Notification) goqu.Record {
+	data := "{}"
+	if len(n.Data) > 0 {
+		data = string(n.Data)
+	}
+
+	return goqu.Record{
+		"id":         n.ID,
+		"user_id":    n.UserID,
+		"type":       n.Type,
+		"title":      n.Title,
+		"body":       n.Body,
+		"data":       data,
+		"read_at":    n.ReadAt,
+		"updated_at": goqu.L("NOW()"),
+		"created_at": n.CreatedAt,
+	}
+}
+
+func scanPreference(row pgx.Row) (*Preference, error) {
+	var p Preference
+
+	err := row.Scan(
+		&p.ID,
+		&p.UserID,
+		&p.Type,
+		&p.Channels,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+func scanPreferences(rows pgx.Rows) ([]*Preference, error) {
+	return storage.ScanRowsWithScanner(rows, scanPreference)
+}
+
+func buildPreferenceRecord(p *Preference) goqu.Record {
+	return goqu.Record{
+		"id":         p.ID,
+		"user_id":    p.UserID,
+		"type":       p.Type,
+		"channels":   storage.ToPgArray(p.Channels),
+		"updated_at": goqu.L("NOW()"),
+		"created_at": p.CreatedAt,
+	}
+}
@@ -0,0 +1,44 @@
+package notification
+
+import "github.com/dbunt1tled/fiber-go-api/pkg/http/dto"
+
+type ListRequest struct {
+	dto.PaginationQuery
+
+	Unread *bool   `query:"unread" json:"unread" validate:"omitempty"         example:"true"`
+	Type   *string `query:"type"   json:"type"   validate:"omitempty,max=100" example:"account.confirmed"`
+}
+
+type Show struct {
+	ID string `params:"id" json:"id" validate:"required,uuid" example:"0e4f8d4c-6c1f-4a44-9c59-6f1f3e2b7a10"`
+}
+
+type Unsubscribe struct {
+	UserID string `params:"id"   json:"id"   validate:"required,uuid"    example:"0e4f8d4c-6c1f-4a44-9c59-6f1f3e2b7a10"`
+	Type   string `params:"type" json:"type" validate:"required,max=100" example:"account.status"`
+}
+
+type PreferenceRequest struct {
+	Type     string    `json:"type"     validate:"required,max=100"                 example:"account.status"`
+	Channels []Channel `json:"channels" validate:"unique,dive,oneof=in_app email sse" example:"in_app,email"`
+}
+
+type Preferences struct {
+	Preferences []PreferenceRequest `json:"preferences" validate:"required,min=1,dive"`
+}
+
+type Send struct {
+	UserID string `json:"userId" validate:"required,uuid"           example:"0e4f8d4c-6c1f-4a44-9c59-6f1f3e2b7a10"`
+	Title  string `json:"title"  validate:"required,max=255"        example:"Scheduled maintenance"`
+	Body   string `json:"body"   validate:"omitempty,max=5000"      example:"The service will be unavailable at 22:00 UTC."`
+	Link   string `json:"link"   validate:"omitempty,http_url,max=2048" example:"https://status.example.com"`
+}
+
+func (r Send) ToMessage() Message {
+	return Message{
+		Type:  TypeAdminMessage,
+		Title: r.Title,
+		Body:  r.Body,
+		Link:  r.Link,
+	}
+}
@@ -0,0 +1,52 @@
+package notification
+
+import (
+	"encoding/json"
+
+	"github.com/dbunt1tled/fiber-go-api/pkg/http/dto"
+	"github.com/dbunt1tled/fiber-go-api/pkg/storage"
+)
+
+func NewNotificationResource(n *Notification) *dto.Resource {
+	resource := dto.NewResource("notification", n.ID.String())
+	resource.MarshalAttributes(n)
+	data := json.RawMessage("{}")
+	if len(n.Data) > 0 {
+		data = n.Data
+	}
+	resource.SetAttribute("data", data)
+	return resource
+}
+
+func NewNotificationResponse(n *Notification) *dto.Document {
+	return dto.NewResponse().SetData(NewNotificationResource(n)).Build()
+}
+
+func NewNotificationListResponse(n *storage.Paginator[*Notification], unread int64) *dto.Document {
+	resources := make([]*dto.Resource, len(n.Items))
+	for i, notification := range n.Items {
+		resources[i] = NewNotificationResource(notification)
+	}
+	return dto.NewResponse().SetData(resources).SetMetaPagination(n).SetMeta("unread", unread).Build()
+}
+
+func NewPreferenceResource(p *Preference) *dto.Resource {
+	resource := dto.NewResource("notificationPreference", p.Type)
+	resource.SetAttributes(map[string]interface{}{
+		"type":     p.Type,
+		"channels": p.Channels,
+	})
+	return resource
+}
+
+func NewPreferenceListResponse(p []*Preference) *dto.Document {
+	resources := make([]*dto.Resource, len(p))
+	for i, preference := range p {
+		resources[i] = NewPreferenceResource(preference)
+	}
+	return dto.NewResponse().SetData(resources).Build()
+}
+
+func NewAffectedResponse(affected int64) *dto.Document {
+	return dto.NewResponse().SetMeta("affected", affected).Build()
+}
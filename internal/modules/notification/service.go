@@ -0,0 +1,310 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/dbunt1tled/fiber-go-api/internal/lib/aemail"
+	"github.com/dbunt1tled/fiber-go-api/internal/modules/realtime"
+	"github.com/dbunt1tled/fiber-go-api/internal/modules/user"
+	"github.com/dbunt1tled/fiber-go-api/pkg/event"
+	"github.com/dbunt1tled/fiber-go-api/pkg/f"
+	"github.com/dbunt1tled/fiber-go-api/pkg/storage"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const EventNotification = "notification"
+
+var (
+	ErrUnknownType = errors.New("unknown notification type")
+	ErrNotFound    = errors.New("notification not found")
+)
+
+type Service struct {
+	notificationRepository *Repository
+	preferenceRepository   *PreferenceRepository
+	userService            *user.Service
+	mailService            *aemail.MailServiceAsync
+	realtimeService        *realtime.Service
+}
+
+// NewNotificationService takes optional channel services: a nil mail or realtime
+// service silently disables that channel.
+func NewNotificationService(
+	pool *pgxpool.Pool,
+	userService *user.Service,
+	mailService *aemail.MailServiceAsync,
+	realtimeService *realtime.Service,
+) *Service {
+	return &Service{
+		notificationRepository: NewNotificationRepository(pool),
+		preferenceRepository:   NewPreferenceRepository(pool),
+		userService:            userService,
+		mailService:            mailService,
+		realtimeService:        realtimeService,
+	}
+}
+
+// Notify delivers the message to the user through the channels chosen in their
+// preferences for the message type, falling back to the type defaults. A failing
+// channel doesn't keep the message from the others.
+func (s *Service) Notify(ctx context.Context, userID uuid.UUID, m Message) error {
+	channels, err := s.Channels(ctx, userID, m.Type)
+	if err != nil {
+		return err
+	}
+
+	var (
+		n    *Notification
+		errs []error
+	)
+	if channels.Has(ChannelInApp) {
+		if n, err = s.store(ctx, userID, m); err != nil {
+			n = nil
+			errs = append(errs, fmt.Errorf("in-app: %w", err))
+		}
+	}
+	if channels.Has(ChannelEmail) && s.mailService != nil {
+		if err = s.email(ctx, userID, m); err != nil {
+			errs = append(errs, fmt.Errorf("email: %w", err))
+		}
+	}
+	if channels.Has(ChannelSSE) && s.realtimeService != nil {
+		if err = s.realtimeService.Notify(ctx, userID, EventNotification, push(n, m)); err != nil {
+			errs = append(errs, fmt.Errorf("sse: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Handle is the event bus listener turning account events into notifications.
+func (s *Service) Handle(ctx context.Context, ev event.Event) error {
+	payload, ok := ev.Payload.(user.EventPayload)
+	if !ok {
+		return nil
+	}
+
+	switch ev.Name {
+	case user.EventConfirmed:
+		return s.Notify(ctx, payload.ID, Message{
+			Type:  TypeAccountConfirmed,
+			Title: "Your account has been confirmed",
+		})
+	case user.EventStatusChanged:
+		// confirmation has its own notification
+		if payload.PreviousStatus != nil && *payload.PreviousStatus == user.Pending {
+			return nil
+		}
+		return s.Notify(ctx, payload.ID, Message{
+			Type:  TypeAccountStatus,
+			Title: "Your account status has changed",
+			Body:  "Your account is now " + payload.Status.String() + ".",
+			Data:  map[string]any{"status": payload.Status},
+		})
+	default:
+		return nil
+	}
+}
+
+func (s *Service) FindForUser(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*Notification, error) {
+	n, err := s.notificationRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if n == nil || n.UserID != userID {
+		return nil, ErrNotFound
+	}
+	return n, nil
+}
+
+func (s *Service) Paginate(
+	ctx context.Context,
+	userID uuid.UUID,
+	page int,
+	perPage int,
+	opts ...storage.QueryOption,
+) (*storage.Paginator[*Notification], error) {
+	opts = append(opts, storage.WithFilter(storage.NewRule("user_id", storage.OpEqual, userID)))
+	return s.notificationRepository.Paginate(ctx, page, perPage, opts...)
+}
+
+func (s *Service) UnreadCount(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return s.notificationRepository.Count(
+		ctx,
+		storage.WithFilter(
+			storage.NewRule("user_id", storage.OpEqual, userID),
+			storage.NewRule("read_at", storage.OpIsNull, nil),
+		),
+	)
+}
+
+func (s *Service) MarkRead(ctx context.Context, n *Notification, read bool) (*Notification, error) {
+	n.ReadAt = nil
+	if read {
+		n.ReadAt = f.Pointer(time.Now())
+	}
+	return s.notificationRepository.Update(ctx, n)
+}
+
+func (s *Service) MarkAllRead(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return s.notificationRepository.UpdateWhere(
+		ctx,
+		goqu.Record{"read_at": goqu.L("NOW()"), "updated_at": goqu.L("NOW()")},
+		storage.NewRule("user_id", storage.OpEqual, userID),
+		storage.NewRule("read_at", storage.OpIsNull, nil),
+	)
+}
+
+func (s *Service) Delete(ctx context.Context, n *Notification) error {
+	return s.notificationRepository.Delete(ctx, n.ID)
+}
+
+// Channels returns the effective channels of a notification type for the user.
+func (s *Service) Channels(ctx context.Context, userID uuid.UUID, typ string) (Channels, error) {
+	defaults, ok := Types()[typ]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownType, typ)
+	}
+	p, err := s.preference(ctx, userID, typ)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return defaults, nil
+	}
+	return p.Channels, nil
+}
+
+// Preferences returns the effective preference of every notification type; types
+// without a stored preference carry their defaults and a zero ID.
+func (s *Service) Preferences(ctx context.Context, userID uuid.UUID) ([]*Preference, error) {
+	stored, err := s.preferenceRepository.List(
+		ctx,
+		storage.WithFilter(storage.NewRule("user_id", storage.OpEqual, userID)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	byType := make(map[string]*Preference, len(stored))
+	for _, p := range stored {
+		byType[p.Type] = p
+	}
+
+	types := Types()
+	preferences := make([]*Preference, 0, len(types))
+	for _, typ := range slices.Sorted(maps.Keys(types)) {
+		p, ok := byType[typ]
+		if !ok {
+			p = &Preference{UserID: userID, Type: typ, Channels: types[typ]}
+		}
+		preferences = append(preferences, p)
+	}
+
+	return preferences, nil
+}
+
+func (s *Service) SetPreference(ctx context.Context, userID uuid.UUID, typ string, channels Channels) (*Preference, error) {
+	if _, ok := Types()[typ]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownType, typ)
+	}
+	if channels == nil {
+		channels = Channels{}
+	}
+
+	p, err := s.preference(ctx, userID, typ)
+	if err != nil {
+		return nil, err
+	}
+	if p != nil {
+		p.Channels = channels
+		return s.preferenceRepository.Update(ctx, p)
+	}
+
+	return s.preferenceRepository.Insert(ctx, (&Preference{
+		UserID:    userID,
+		Type:      typ,
+		Channels:  channels,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}).NextID())
+}
+
+func (s *Service) preference(ctx context.Context, userID uuid.UUID, typ string) (*Preference, error) {
+	return s.preferenceRepository.One(
+		ctx,
+		storage.WithFilter(
+			storage.NewRule("user_id", storage.OpEqual, userID),
+			storage.NewRule("type", storage.OpEqual, typ),
+		),
+	)
+}
+
+func (s *Service) store(ctx context.Context, userID uuid.UUID, m Message) (*Notification, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if m.Data != nil {
+		data, err = sonic.ConfigFastest.Marshal(m.Data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.notificationRepository.Insert(ctx, (&Notification{
+		UserID:    userID,
+		Type:      m.Type,
+		Title:     m.Title,
+		Body:      m.Body,
+		Data:      data,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}).NextID())
+}
+
+func (s *Service) email(ctx context.Context, userID uuid.UUID, m Message) error {
+	u, err := s.userService.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if u == nil {
+		return fmt.Errorf("user %s not found", userID)
+	}
+	return s.mailService.SendNotificationMail(u, m.Type, m.Title, m.Body, m.Link)
+}
+
+// Unsubscribe stops emails of the notification type, leaving the other channels as they are.
+func (s *Service) Unsubscribe(ctx context.Context, userID uuid.UUID, typ string) (*Preference, error) {
+	channels, err := s.Channels(ctx, userID, typ)
+	if err != nil {
+		return nil, err
+	}
+	channels = slices.DeleteFunc(slices.Clone(channels), func(c Channel) bool {
+		return c == ChannelEmail
+	})
+	return s.SetPreference(ctx, userID, typ, channels)
+}
+
+// push is the SSE payload, referencing the stored notification when there is one.
+func push(n *Notification, m Message) map[string]any {
+	payload := map[string]any{
+		"type":  m.Type,
+		"title": m.Title,
+		"body":  m.Body,
+		"link":  m.Link,
+		"data":  m.Data,
+	}
+	if n != nil {
+		payload["id"] = n.ID
+		payload["createdAt"] = n.CreatedAt
+	}
+	return payload
+}
@@ -0,0 +1,102 @@
+package realtime
+
+import (
+	"bufio"
+	"strconv"
+
+	"github.com/dbunt1tled/fiber-go-api/internal/modules/user"
+	"github.com/dbunt1tled/fiber-go-api/pkg/e"
+	"github.com/dbunt1tled/fiber-go-api/pkg/http"
+	"github.com/dbunt1tled/fiber-go-api/pkg/http/dto"
+	"github.com/dbunt1tled/fiber-go-api/pkg/sse"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+)
+
+type Controller struct {
+	http.BaseController
+
+	realtimeService *Service
+	userService     *user.Service
+	stream          sse.StreamOptions
+}
+
+func NewController(
+	realtimeService *Service,
+	userService *user.Service,
+	stream sse.StreamOptions,
+	validation *validator.Validate,
+) *Controller {
+	return &Controller{
+		BaseController:  http.NewBaseController(validation),
+		realtimeService: realtimeService,
+		userService:     userService,
+		stream:          stream,
+	}
+}
+
+// Stream keeps an event stream open for the authenticated user. Reconnecting
+// clients send Last-Event-ID and receive what they missed from the buffer.
+func (rc *Controller) Stream(c fiber.Ctx) error {
+	u, ok := c.Locals("user").(*user.User)
+	if !ok || u == nil {
+		return e.NewUnauthorizedError("Unauthorized", e.Err401UserNotFoundError)
+	}
+	lastEventID, _ := strconv.ParseUint(c.Get("Last-Event-ID"), 10, 64)
+
+	sub, replay, err := rc.realtimeService.Subscribe(c.Context(), u.ID, lastEventID)
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Event stream error.", e.Err422EventStreamError, err)
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	conn := c.RequestCtx().Conn()
+	return c.SendStreamWriter(func(w *bufio.Writer) {
+		sse.Serve(w, conn, sub, replay, rc.stream)
+	})
+}
+
+// Link answers with a short-lived signed url of the stream, for browsers whose EventSource can't send
+// the Authorization header. The link only opens a connection, a client reconnecting after it expired
+// has to ask for a new one.
+func (rc *Controller) Link(c fiber.Ctx) error {
+	u, ok := c.Locals("user").(*user.User)
+	if !ok || u == nil {
+		return e.NewUnauthorizedError("Unauthorized", e.Err401UserNotFoundError)
+	}
+
+	link, err := rc.realtimeService.StreamURL(u.ID)
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Event stream error.", e.Err422EventStreamError, err)
+	}
+
+	return rc.JSON200(c, dto.NewResponse().SetMeta("url", link).Build())
+}
+
+func (rc *Controller) Message(c fiber.Ctx) error {
+	req := new(Message)
+	if err := rc.BindAndValidate(c, req, e.Err422EventValidateError); err != nil {
+		return err
+	}
+
+	u, err := rc.userService.FindByID(c.Context(), uuid.MustParse(req.UserID))
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Event publish error.", e.Err422EventPublishError, err)
+	}
+	if u == nil {
+		return e.NewNotFoundError("User not found.", e.Err404UserNotFound)
+	}
+
+	if err = rc.realtimeService.Notify(c.Context(), u.ID, EventAdminMessage, map[string]string{
+		"message": req.Message,
+	}); err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Event publish error.", e.Err422EventPublishError, err)
+	}
+
+	return rc.JSON200(c, dto.NewResponse().SetMeta("published", true).Build())
+}
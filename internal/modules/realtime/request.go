@@ -0,0 +1,6 @@
+package realtime
+
+type Message struct {
+	UserID  string `json:"userId"  validate:"required,uuid"    example:"0e4f8d4c-6c1f-4a44-9c59-6f1f3e2b7a10"`
+	Message string `json:"message" validate:"required,max=1000" example:"Scheduled maintenance at 22:00 UTC"`
+}
@@ -0,0 +1,84 @@
+package realtime
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dbunt1tled/fiber-go-api/internal/modules/user"
+	"github.com/dbunt1tled/fiber-go-api/pkg/event"
+	"github.com/dbunt1tled/fiber-go-api/pkg/hasher"
+	"github.com/dbunt1tled/fiber-go-api/pkg/sse"
+	"github.com/google/uuid"
+)
+
+const (
+	EventAdminMessage = "admin.message"
+	// EventSessionRevoked tells the clients of a user that their sessions stopped working.
+	EventSessionRevoked = "session.revoked"
+)
+
+type Options struct {
+	BaseURL string
+	// LinkExpire is how long a signed stream link can be used to connect.
+	LinkExpire time.Duration
+	// LinkUserParam is the query parameter of the user a stream link authenticates.
+	LinkUserParam string
+}
+
+type Service struct {
+	hub    *sse.Hub
+	signer *hasher.URLSigner
+	opts   Options
+}
+
+func NewRealtimeService(hub *sse.Hub, signer *hasher.URLSigner, opts Options) *Service {
+	return &Service{
+		hub:    hub,
+		signer: signer,
+		opts:   opts,
+	}
+}
+
+func (s *Service) Notify(ctx context.Context, userID uuid.UUID, name string, data any) error {
+	return s.hub.Publish(ctx, userID.String(), name, data)
+}
+
+func (s *Service) Subscribe(ctx context.Context, userID uuid.UUID, lastEventID uint64) (*sse.Subscription, []sse.Message, error) {
+	return s.hub.Subscribe(ctx, userID.String(), lastEventID)
+}
+
+// StreamURL returns a signed link opening the event stream of the user without an Authorization header.
+func (s *Service) StreamURL(userID uuid.UUID) (string, error) {
+	query := url.Values{s.opts.LinkUserParam: {userID.String()}}
+	return s.signer.Sign(strings.TrimRight(s.opts.BaseURL, "/")+"/api/events?"+query.Encode(), s.opts.LinkExpire)
+}
+
+// Handle is the event bus listener forwarding user events to the streams of that user.
+func (s *Service) Handle(ctx context.Context, ev event.Event) error {
+	switch ev.Name {
+	case user.EventConfirmed, user.EventStatusChanged:
+		payload, ok := ev.Payload.(user.EventPayload)
+		if !ok {
+			return nil
+		}
+		if err := s.Notify(ctx, payload.ID, ev.Name, ev); err != nil {
+			return err
+		}
+		// tokens are stateless, a user leaving the active status is what ends their sessions
+		if revoked(payload) {
+			return s.Notify(ctx, payload.ID, EventSessionRevoked, map[string]any{
+				"reason": ev.Name,
+				"status": payload.Status,
+			})
+		}
+		return nil
+	default:
+		return nil
+	}
+}
+
+func revoked(payload user.EventPayload) bool {
+	return payload.PreviousStatus != nil && *payload.PreviousStatus == user.Active && payload.Status != user.Active
+}
@@ -0,0 +1,71 @@
+package user
+
+import (
+	"context"
+
+	"github.com/dbunt1tled/fiber-go-api/pkg/audit"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/google/uuid"
+)
+
+const (
+	AuditTarget        = "user"
+	AuditCreated       = "user.created"
+	AuditUpdated       = "user.updated"
+	AuditDeleted       = "user.deleted"
+	AuditStatusChanged = "user.status_changed"
+	AuditRolesChanged  = "user.roles_changed"
+)
+
+// auditRedacted are the fields the audit log only records as changed.
+var auditRedacted = []string{"password"}
+
+// audit records the difference between the stored versions of a user, before is nil for a created user
+// and after for a deleted one. Status and role changes get entries of their own.
+func (s *Service) audit(ctx context.Context, id uuid.UUID, before *User, after *User, details map[string]any) {
+	changes := audit.Diff(recordOf(before), recordOf(after), auditRedacted...)
+
+	action := AuditUpdated
+	switch {
+	case before == nil:
+		action = AuditCreated
+	case after == nil:
+		action = AuditDeleted
+	default:
+		if status := changes.Only("status"); len(status) > 0 {
+			s.recorder.Record(ctx, audit.Action{
+				Name:       AuditStatusChanged,
+				TargetType: AuditTarget,
+				TargetID:   id.String(),
+				Changes:    status,
+			})
+		}
+		if roles := changes.Only("roles"); len(roles) > 0 {
+			s.recorder.Record(ctx, audit.Action{
+				Name:       AuditRolesChanged,
+				TargetType: AuditTarget,
+				TargetID:   id.String(),
+				Changes:    roles,
+			})
+		}
+		changes = changes.Without("status", "roles")
+		if len(changes) == 0 {
+			return
+		}
+	}
+
+	s.recorder.Record(ctx, audit.Action{
+		Name:       action,
+		TargetType: AuditTarget,
+		TargetID:   id.String(),
+		Changes:    changes,
+		Details:    details,
+	})
+}
+
+func recordOf(u *User) goqu.Record {
+	if u == nil {
+		return nil
+	}
+	return buildUserRecord(u)
+}
@@ -29,6 +29,8 @@ func (s *Status) String() string {
 	switch *s {
 	case Inactive:
 		return "inactive"
+	case Pending:
+		return "pending"
 	case Active:
 		return "active"
 	default:
@@ -0,0 +1,37 @@
+package user
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	EventRegistered    = "user.registered"
+	EventConfirmed     = "user.confirmed"
+	EventStatusChanged = "user.status_changed"
+)
+
+// EventPayload is the public view of a user carried by domain events; it never includes credentials.
+type EventPayload struct {
+	ID             uuid.UUID  `json:"id"`
+	FirstName      string     `json:"firstName"`
+	SecondName     string     `json:"secondName"`
+	Email          string     `json:"email"`
+	Status         Status     `json:"status"`
+	PreviousStatus *Status    `json:"previousStatus,omitempty"`
+	ConfirmedAt    *time.Time `json:"confirmedAt"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+func NewEventPayload(u *User) EventPayload {
+	return EventPayload{
+		ID:          u.ID,
+		FirstName:   u.FirstName,
+		SecondName:  u.SecondName,
+		Email:       u.Email,
+		Status:      u.Status,
+		ConfirmedAt: u.ConfirmedAt,
+		CreatedAt:   u.CreatedAt,
+	}
+}
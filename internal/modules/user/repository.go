@@ -28,13 +28,14 @@ func NewUserRepository(pool *pgxpool.Pool) *Repository {
 func scanUser(row pgx.Row) (*User, error) {
 	var user User
 	var confirmedAt sql.NullTime
+	var phoneNumber sql.NullString
 
 	err := row.Scan(
 		&user.ID,
 		&user.FirstName,
 		&user.SecondName,
 		&user.Email,
-		&user.PhoneNumber,
+		&phoneNumber,
 		&user.Status,
 		&user.Password,
 		&user.Roles,
@@ -42,6 +43,12 @@ func scanUser(row pgx.Row) (*User, error) {
 		&confirmedAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.AvatarID,
+		&user.PendingEmail,
+		&user.PendingEmailAt,
+		&user.ConfirmationSentAt,
+		&user.DeletedAt,
+		&user.Version,
 	)
 	if err != nil {
 		return nil, err
@@ -50,6 +57,7 @@ func scanUser(row pgx.Row) (*User, error) {
 	if confirmedAt.Valid {
 		user.ConfirmedAt = &confirmedAt.Time
 	}
+	user.PhoneNumber = phoneNumber.String
 
 	return &user, nil
 }
@@ -60,17 +68,21 @@ func scanUsers(rows pgx.Rows) ([]*User, error) {
 
 func buildUserRecord(user *User) goqu.Record {
 	record := goqu.Record{
-		"id":           user.ID,
-		"first_name":   user.FirstName,
-		"second_name":  user.SecondName,
-		"email":        user.Email,
-		"phone_number": user.PhoneNumber,
-		"status":       user.Status,
-		"password":     user.Password,
-		"roles":        storage.ToPgArray(user.Roles),
-		"address":      user.Address,
-		"updated_at":   goqu.L("NOW()"),
-		"created_at":   user.CreatedAt,
+		"id":                   user.ID,
+		"first_name":           user.FirstName,
+		"second_name":          user.SecondName,
+		"email":                user.Email,
+		"phone_number":         sql.NullString{String: user.PhoneNumber, Valid: user.PhoneNumber != ""},
+		"status":               user.Status,
+		"password":             user.Password,
+		"roles":                storage.ToPgArray(user.Roles),
+		"address":              user.Address,
+		"avatar_id":            user.AvatarID,
+		"pending_email":        user.PendingEmail,
+		"pending_email_at":     user.PendingEmailAt,
+		"confirmation_sent_at": user.ConfirmationSentAt,
+		"updated_at":           goqu.L("NOW()"),
+		"created_at":           user.CreatedAt,
 	}
 
 	if user.ConfirmedAt != nil {
@@ -2,19 +2,27 @@ package user
 
 import (
 	"context"
+	"time"
 
+	"github.com/dbunt1tled/fiber-go-api/pkg/audit"
+	"github.com/dbunt1tled/fiber-go-api/pkg/event"
 	"github.com/dbunt1tled/fiber-go-api/pkg/storage"
+	"github.com/doug-martin/goqu/v9"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type Service struct {
 	userRepository *Repository
+	events         event.Publisher
+	recorder       audit.Recorder
 }
 
-func NewUserService(pool *pgxpool.Pool) *Service {
+func NewUserService(pool *pgxpool.Pool, events event.Publisher, recorder audit.Recorder) *Service {
 	return &Service{
 		userRepository: NewUserRepository(pool),
+		events:         events,
+		recorder:       recorder,
 	}
 }
 
@@ -39,10 +47,90 @@ func (s *Service) Paginate(
 	return s.userRepository.Paginate(ctx, page, perPage, opts...)
 }
 
+// Update persists the user, records the changed fields in the audit log and publishes EventStatusChanged
+// when the status differs from the stored one.
 func (s *Service) Update(ctx context.Context, user *User) (*User, error) {
-	return s.userRepository.Update(ctx, user)
+	previous, err := s.userRepository.FindByID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	user, err = s.userRepository.Update(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if previous != nil {
+		s.audit(ctx, user.ID, previous, user, nil)
+	}
+	if previous != nil && previous.Status != user.Status {
+		payload := NewEventPayload(user)
+		payload.PreviousStatus = &previous.Status
+		s.events.Publish(ctx, EventStatusChanged, payload)
+	}
+
+	return user, nil
 }
 
 func (s *Service) Create(ctx context.Context, user *User) (*User, error) {
-	return s.userRepository.Insert(ctx, user)
+	user, err := s.userRepository.Insert(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	s.audit(ctx, user.ID, nil, user, nil)
+
+	return user, nil
+}
+
+// Delete soft deletes the user, its email and phone number can be registered again.
+func (s *Service) Delete(ctx context.Context, id uuid.UUID) error {
+	previous, err := s.userRepository.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err = s.userRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	if previous != nil {
+		s.audit(ctx, id, previous, nil, nil)
+	}
+
+	return nil
+}
+
+// ForceDelete removes the user for good, soft deleted or not.
+func (s *Service) ForceDelete(ctx context.Context, id uuid.UUID) error {
+	previous, err := s.userRepository.One(
+		ctx,
+		storage.WithFilter(storage.NewRule("id", storage.OpEqual, id)),
+		storage.WithTrashed(),
+	)
+	if err != nil {
+		return err
+	}
+	if err = s.userRepository.ForceDelete(ctx, id); err != nil {
+		return err
+	}
+	if previous != nil {
+		s.audit(ctx, id, previous, nil, map[string]any{"force": true})
+	}
+
+	return nil
+}
+
+// PurgeUnconfirmed removes pending users whose last confirmation mail, or sign up when none was sent,
+// is older than the given time and returns how many were removed. The rows are deleted for good, soft
+// deleted ones included, so the emails can be used to sign up again.
+func (s *Service) PurgeUnconfirmed(ctx context.Context, before time.Time) (int, error) {
+	users, err := s.userRepository.ForceDeleteWhere(
+		ctx,
+		goqu.Ex{"status": Pending},
+		goqu.COALESCE(goqu.C("confirmation_sent_at"), goqu.C("created_at")).Lt(before),
+	)
+	if err != nil {
+		return 0, err
+	}
+	for _, u := range users {
+		s.audit(ctx, u.ID, u, nil, map[string]any{"reason": "unconfirmed"})
+	}
+
+	return len(users), nil
 }
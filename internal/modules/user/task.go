@@ -0,0 +1,35 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dbunt1tled/fiber-go-api/pkg/log"
+	"github.com/dbunt1tled/fiber-go-api/pkg/queue"
+	"github.com/hibiken/asynq"
+)
+
+const (
+	PurgeUnconfirmedName = "userpurge"
+	PurgeUnconfirmedTask = "user:purge"
+)
+
+// NewPurgeUnconfirmedTask removes users that did not confirm their email within expire of the last confirmation mail,
+// or of signing up when none was sent.
+func NewPurgeUnconfirmedTask(s *Service, expire time.Duration) queue.PeriodicTask {
+	return queue.PeriodicTask{
+		Name: PurgeUnconfirmedName,
+		Cron: "@hourly",
+		Type: PurgeUnconfirmedTask,
+		Handler: func(ctx context.Context, _ *asynq.Task) error {
+			count, err := s.PurgeUnconfirmed(ctx, time.Now().Add(-expire))
+			if err != nil {
+				return err
+			}
+			log.Logger().InfoContext(ctx, fmt.Sprintf("Purged %d unconfirmed users", count))
+			return nil
+		},
+		Options: []asynq.Option{asynq.Queue(queue.DefaultQueue), asynq.MaxRetry(1)},
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/dbunt1tled/fiber-go-api/pkg/f"
 	"github.com/google/uuid"
 )
 
@@ -28,11 +29,26 @@ type User struct {
 	ConfirmedAt *time.Time `db:"confirmed_at" json:"confirmedAt"`
 	CreatedAt   time.Time  `db:"created_at"   json:"createdAt"`
 	UpdatedAt   time.Time  `db:"updated_at"   json:"updatedAt"`
+	AvatarID    *uuid.UUID `db:"avatar_id"    json:"avatarId"`
+	// PendingEmail replaces Email once the new address is confirmed.
+	PendingEmail   *string    `db:"pending_email"    json:"pendingEmail"`
+	PendingEmailAt *time.Time `db:"pending_email_at" json:"pendingEmailAt"`
+	// ConfirmationSentAt is renewed with every confirmation mail, which invalidates the earlier links.
+	ConfirmationSentAt *time.Time `db:"confirmation_sent_at" json:"confirmationSentAt"`
+	DeletedAt          *time.Time `db:"deleted_at"           json:"deletedAt"`
+	// Version guards updates against overwriting a concurrent change.
+	Version int `db:"version" json:"version"`
 }
 
 func (u *User) TableName() string  { return "users" }
 func (u *User) GetID() uuid.UUID   { return u.ID }
 func (u *User) SetID(id uuid.UUID) { u.ID = id }
+func (u *User) GetDeletedAt() *time.Time {
+	return u.DeletedAt
+}
+func (u *User) GetVersion() int {
+	return u.Version
+}
 func (u *User) NextID() *User {
 	var err error
 	u.ID, err = uuid.NewV7()
@@ -47,6 +63,25 @@ func (u *User) WithPassword(password string) *User {
 	return u
 }
 
+func (u *User) RequestEmailChange(email string) {
+	u.PendingEmail = &email
+	u.PendingEmailAt = f.Pointer(time.Now())
+}
+
+func (u *User) ClearPendingEmail() {
+	u.PendingEmail = nil
+	u.PendingEmailAt = nil
+}
+
+func (u *User) HasRole(role Role) bool {
+	for _, r := range u.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
 func (u *User) Sanitize() {
 	u.FirstName = strings.TrimSpace(u.FirstName)
 	u.SecondName = strings.TrimSpace(u.SecondName)
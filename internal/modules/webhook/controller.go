@@ -0,0 +1,174 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dbunt1tled/fiber-go-api/pkg/e"
+	"github.com/dbunt1tled/fiber-go-api/pkg/http"
+	"github.com/dbunt1tled/fiber-go-api/pkg/storage"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+)
+
+type Controller struct {
+	http.BaseController
+
+	webhookService *Service
+}
+
+func NewController(
+	webhookService *Service,
+	validation *validator.Validate,
+) *Controller {
+	return &Controller{
+		BaseController: http.NewBaseController(validation),
+		webhookService: webhookService,
+	}
+}
+
+func (wc *Controller) List(c fiber.Ctx) error {
+	req := new(ListRequest)
+	if err := wc.BindAndValidate(c, req, e.Err422WebhookValidateError); err != nil {
+		return err
+	}
+
+	rules := []storage.Rule{storage.NewRule("active", storage.OpEqual, req.Active)}
+	if req.Event != nil {
+		rules = append(rules, storage.NewRule("events", storage.OpContains, []string{*req.Event}))
+	}
+	webhooks, err := wc.webhookService.Paginate(
+		c.Context(),
+		req.Page.Page,
+		req.Page.Limit,
+		storage.WithFilter(rules...),
+		storage.WithSort(req.Sort.Field, req.Sort.Order),
+	)
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Webhook list error.", e.Err422WebhookListError, err)
+	}
+
+	return wc.JSON200(c, NewWebhookListResponse(webhooks))
+}
+
+func (wc *Controller) Show(c fiber.Ctx) error {
+	req := new(Show)
+	if err := wc.BindAndValidate(c, req, e.Err422WebhookValidateError); err != nil {
+		return err
+	}
+
+	w, err := wc.find(c.Context(), req.ID)
+	if err != nil {
+		return err
+	}
+
+	return wc.JSON200(c, NewWebhookResponse(w))
+}
+
+func (wc *Controller) Create(c fiber.Ctx) error {
+	req := new(Create)
+	if err := wc.BindAndValidate(c, req, e.Err422WebhookValidateError); err != nil {
+		return err
+	}
+
+	w, err := wc.webhookService.Create(c.Context(), req.ToWebhook())
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Webhook creation error.", e.Err422WebhookCreateError, err)
+	}
+
+	return wc.JSON201(c, NewWebhookSecretResponse(w))
+}
+
+func (wc *Controller) Update(c fiber.Ctx) error {
+	req := new(Update)
+	if err := wc.BindAndValidate(c, req, e.Err422WebhookValidateError); err != nil {
+		return err
+	}
+
+	w, err := wc.find(c.Context(), req.ID)
+	if err != nil {
+		return err
+	}
+	w, err = wc.webhookService.Update(c.Context(), req.Apply(w))
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Webhook update error.", e.Err422WebhookUpdateError, err)
+	}
+
+	if req.Secret != nil {
+		return wc.JSON200(c, NewWebhookSecretResponse(w))
+	}
+	return wc.JSON200(c, NewWebhookResponse(w))
+}
+
+func (wc *Controller) Delete(c fiber.Ctx) error {
+	req := new(Show)
+	if err := wc.BindAndValidate(c, req, e.Err422WebhookValidateError); err != nil {
+		return err
+	}
+
+	w, err := wc.find(c.Context(), req.ID)
+	if err != nil {
+		return err
+	}
+	if err = wc.webhookService.Delete(c.Context(), w.ID); err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Webhook delete error.", e.Err422WebhookDeleteError, err)
+	}
+
+	return wc.JSON200(c, NewWebhookResponse(w))
+}
+
+func (wc *Controller) Deliveries(c fiber.Ctx) error {
+	req := new(DeliveryList)
+	if err := wc.BindAndValidate(c, req, e.Err422WebhookValidateError); err != nil {
+		return err
+	}
+
+	w, err := wc.find(c.Context(), req.ID)
+	if err != nil {
+		return err
+	}
+	deliveries, err := wc.webhookService.Deliveries(
+		c.Context(),
+		w.ID,
+		req.Page.Page,
+		req.Page.Limit,
+		storage.WithFilter(storage.NewRule("status", storage.OpEqual, req.Status)),
+		storage.WithSort(req.Sort.Field, req.Sort.Order),
+	)
+	if err != nil {
+		return e.NewUnprocessableEntityErrorWrap("Webhook delivery list error.", e.Err422WebhookDeliveryListError, err)
+	}
+
+	return wc.JSON200(c, NewDeliveryListResponse(deliveries))
+}
+
+func (wc *Controller) Redeliver(c fiber.Ctx) error {
+	req := new(Redeliver)
+	if err := wc.BindAndValidate(c, req, e.Err422WebhookValidateError); err != nil {
+		return err
+	}
+
+	d, err := wc.webhookService.Redeliver(c.Context(), uuid.MustParse(req.ID), uuid.MustParse(req.DeliveryID))
+	switch {
+	case errors.Is(err, ErrDeliveryNotFound):
+		return e.NewNotFoundError("Webhook delivery not found.", e.Err404WebhookDeliveryNotFound)
+	case errors.Is(err, ErrWebhookDisabled):
+		return e.NewUnprocessableEntityError("Webhook is disabled.", e.Err422WebhookDisabledError)
+	case err != nil:
+		return e.NewUnprocessableEntityErrorWrap("Webhook redeliver error.", e.Err422WebhookRedeliverError, err)
+	}
+
+	return wc.JSON200(c, NewDeliveryResponse(d))
+}
+
+func (wc *Controller) find(ctx context.Context, id string) (*Webhook, error) {
+	w, err := wc.webhookService.FindByID(ctx, uuid.MustParse(id))
+	if err != nil {
+		return nil, e.NewUnprocessableEntityErrorWrap("Webhook error.", e.Err422WebhookListError, err)
+	}
+	if w == nil {
+		return nil, e.NewNotFoundError("Webhook not found.", e.Err404WebhookNotFound)
+	}
+	return w, nil
+}
@@ -0,0 +1,22 @@
+package webhook
+
+type DeliveryStatus int
+
+const (
+	DeliveryPending DeliveryStatus = iota
+	DeliverySucceeded
+	DeliveryFailed
+)
+
+func (s DeliveryStatus) String() string {
+	switch s {
+	case DeliveryPending:
+		return "pending"
+	case DeliverySucceeded:
+		return "succeeded"
+	case DeliveryFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,141 @@
+package webhook
+
+import (
+	"context"
+
+	"github.com/dbunt1tled/fiber-go-api/pkg/storage"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// store is the part of the repositories the service relies on.
+type store[T storage.Model] interface {
+	FindByID(ctx context.Context, id uuid.UUID) (T, error)
+	List(ctx context.Context, opts ...storage.QueryOption) ([]T, error)
+	Paginate(ctx context.Context, page int, perPage int, opts ...storage.QueryOption) (*storage.Paginator[T], error)
+	Insert(ctx context.Context, entity T) (T, error)
+	Update(ctx context.Context, entity T) (T, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type Repository struct {
+	*storage.Repository[*Webhook]
+}
+
+type DeliveryRepository struct {
+	*storage.Repository[*Delivery]
+}
+
+func NewWebhookRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{
+		Repository: storage.NewRepository[*Webhook](
+			pool,
+			"webhooks",
+			scanWebhook,
+			scanWebhooks,
+			buildWebhookRecord,
+		),
+	}
+}
+
+func NewDeliveryRepository(pool *pgxpool.Pool) *DeliveryRepository {
+	return &DeliveryRepository{
+		Repository: storage.NewRepository[*Delivery](
+			pool,
+			"webhook_deliveries",
+			scanDelivery,
+			scanDeliveries,
+			buildDeliveryRecord,
+		),
+	}
+}
+
+func scanWebhook(row pgx.Row) (*Webhook, error) {
+	var w Webhook
+
+	err := row.Scan(
+		&w.ID,
+		&w.URL,
+		&w.Secret,
+		&w.Events,
+		&w.Active,
+		&w.FailureCount,
+		&w.DisabledAt,
+		&w.CreatedAt,
+		&w.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &w, nil
+}
+
+func scanWebhooks(rows pgx.Rows) ([]*Webhook, error) {
+	return storage.ScanRowsWithScanner(rows, scanWebhook)
+}
+
+func buildWebhookRecord(w *Webhook) goqu.Record {
+	return goqu.Record{
+		"id":            w.ID,
+		"url":           w.URL,
+		"secret":        w.Secret,
+		"events":        storage.ToPgArray(w.Events),
+		"active":        w.Active,
+		"failure_count": w.FailureCount,
+		"disabled_at":   w.DisabledAt,
+		"updated_at":    goqu.L("NOW()"),
+		"created_at":    w.CreatedAt,
+	}
+}
+
+func scanDelivery(row pgx.Row) (*Delivery, error) {
+	var d Delivery
+
+	err := row.Scan(
+		&d.ID,
+		&d.WebhookID,
+		&d.EventID,
+		&d.Event,
+		&d.Payload,
+		&d.Status,
+		&d.Attempts,
+		&d.ResponseCode,
+		&d.ResponseBody,
+		&d.Error,
+		&d.DurationMs,
+		&d.DeliveredAt,
+		&d.CreatedAt,
+		&d.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &d, nil
+}
+
+func scanDeliveries(rows pgx.Rows) ([]*Delivery, error) {
+	return storage.ScanRowsWithScanner(rows, scanDelivery)
+}
+
+func buildDeliveryRecord(d *Delivery) goqu.Record {
+	return goqu.Record{
+		"id":            d.ID,
+		"webhook_id":    d.WebhookID,
+		"event_id":      d.EventID,
+		"event":         d.Event,
+		"payload":       string(d.Payload),
+		"status":        d.Status,
+		"attempts":      d.Attempts,
+		"response_code": d.ResponseCode,
+		"response_body": d.ResponseBody,
+		"error":         d.Error,
+		"duration_ms":   d.DurationMs,
+		"delivered_at":  d.DeliveredAt,
+		"updated_at":    goqu.L("NOW()"),
+		"created_at":    d.CreatedAt,
+	}
+}
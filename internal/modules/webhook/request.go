@@ -0,0 +1,71 @@
+package webhook
+
+import (
+	"time"
+
+	"github.com/dbunt1tled/fiber-go-api/pkg/http/dto"
+)
+
+type ListRequest struct {
+	dto.PaginationQuery
+
+	Active *bool   `query:"active" json:"active" validate:"omitempty"                                                  example:"true"`
+	Event  *string `query:"event"  json:"event"  validate:"omitempty,oneof=user.registered user.confirmed user.status_changed" example:"user.registered"`
+}
+
+type Show struct {
+	ID string `params:"id" json:"id" validate:"required,uuid" example:"0e4f8d4c-6c1f-4a44-9c59-6f1f3e2b7a10"`
+}
+
+type Create struct {
+	URL    string   `json:"url"    validate:"required,http_url,max=2048"                                                   example:"https://partner.example.com/hooks"`
+	Secret string   `json:"secret" validate:"omitempty,min=16,max=255"                                                     example:"whsec_9f86d081884c7d659a2feaa0c55ad015"`
+	Events []string `json:"events" validate:"required,min=1,unique,dive,oneof=user.registered user.confirmed user.status_changed" example:"user.registered,user.confirmed"`
+}
+
+func (r Create) ToWebhook() *Webhook {
+	return (&Webhook{
+		URL:       r.URL,
+		Secret:    r.Secret,
+		Events:    r.Events,
+		Active:    true,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}).NextID()
+}
+
+type Update struct {
+	ID     string    `params:"id"   json:"id"     validate:"required,uuid"                                                             example:"0e4f8d4c-6c1f-4a44-9c59-6f1f3e2b7a10"`
+	URL    *string   `json:"url"    validate:"omitempty,http_url,max=2048"                                                   example:"https://partner.example.com/hooks"`
+	Secret *string   `json:"secret" validate:"omitempty,min=16,max=255"                                                     example:"whsec_9f86d081884c7d659a2feaa0c55ad015"`
+	Events *[]string `json:"events" validate:"omitempty,min=1,unique,dive,oneof=user.registered user.confirmed user.status_changed" example:"user.registered,user.confirmed"`
+	Active *bool     `json:"active" validate:"omitempty"                                                                    example:"true"`
+}
+
+func (r Update) Apply(w *Webhook) *Webhook {
+	if r.URL != nil {
+		w.URL = *r.URL
+	}
+	if r.Secret != nil {
+		w.Secret = *r.Secret
+	}
+	if r.Events != nil {
+		w.Events = *r.Events
+	}
+	if r.Active != nil {
+		w.Active = *r.Active
+	}
+	return w
+}
+
+type DeliveryList struct {
+	dto.PaginationQuery
+
+	ID     string          `params:"id"    json:"id"     validate:"required,uuid" example:"0e4f8d4c-6c1f-4a44-9c59-6f1f3e2b7a10"`
+	Status *DeliveryStatus `query:"status" json:"status" validate:"omitempty"     example:"2"`
+}
+
+type Redeliver struct {
+	ID         string `params:"id"         json:"id"         validate:"required,uuid" example:"0e4f8d4c-6c1f-4a44-9c59-6f1f3e2b7a10"`
+	DeliveryID string `params:"deliveryId" json:"deliveryId" validate:"required,uuid" example:"0e4f8d4c-6c1f-4a44-9c59-6f1f3e2b7a11"`
+}
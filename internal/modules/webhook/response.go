@@ -0,0 +1,54 @@
+package webhook
+
+import (
+	"encoding/json"
+
+	"github.com/dbunt1tled/fiber-go-api/pkg/http/dto"
+	"github.com/dbunt1tled/fiber-go-api/pkg/storage"
+)
+
+func NewWebhookResource(w *Webhook) *dto.Resource {
+	resource := dto.NewResource("webhook", w.ID.String())
+	resource.MarshalAttributes(w)
+	return resource
+}
+
+func NewWebhookResponse(w *Webhook) *dto.Document {
+	return dto.NewResponse().SetData(NewWebhookResource(w)).Build()
+}
+
+// NewWebhookSecretResponse is the only response exposing the signing secret,
+// returned when it is created or changed.
+func NewWebhookSecretResponse(w *Webhook) *dto.Document {
+	resource := NewWebhookResource(w)
+	resource.SetAttribute("secret", w.Secret)
+	return dto.NewResponse().SetData(resource).Build()
+}
+
+func NewWebhookListResponse(w *storage.Paginator[*Webhook]) *dto.Document {
+	resources := make([]*dto.Resource, len(w.Items))
+	for i, webhook := range w.Items {
+		resources[i] = NewWebhookResource(webhook)
+	}
+	return dto.NewResponse().SetData(resources).SetMetaPagination(w).Build()
+}
+
+func NewDeliveryResource(d *Delivery) *dto.Resource {
+	resource := dto.NewResource("webhookDelivery", d.ID.String())
+	resource.MarshalAttributes(d)
+	resource.SetAttribute("payload", json.RawMessage(d.Payload))
+	resource.SetRelationship("webhook", "webhook", d.WebhookID.String())
+	return resource
+}
+
+func NewDeliveryResponse(d *Delivery) *dto.Document {
+	return dto.NewResponse().SetData(NewDeliveryResource(d)).Build()
+}
+
+func NewDeliveryListResponse(d *storage.Paginator[*Delivery]) *dto.Document {
+	resources := make([]*dto.Resource, len(d.Items))
+	for i, delivery := range d.Items {
+		resources[i] = NewDeliveryResource(delivery)
+	}
+	return dto.NewResponse().SetData(resources).SetMetaPagination(d).Build()
+}
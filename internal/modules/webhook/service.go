@@ -0,0 +1,315 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/dbunt1tled/fiber-go-api/pkg/event"
+	"github.com/dbunt1tled/fiber-go-api/pkg/f"
+	"github.com/dbunt1tled/fiber-go-api/pkg/log"
+	"github.com/dbunt1tled/fiber-go-api/pkg/queue"
+	"github.com/dbunt1tled/fiber-go-api/pkg/storage"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	secretPrefix  = "whsec_"
+	secretLength  = 32
+	responseLimit = 4096
+)
+
+var (
+	ErrWebhookDisabled  = errors.New("webhook is disabled")
+	ErrDeliveryNotFound = errors.New("delivery not found")
+)
+
+type Options struct {
+	Timeout     time.Duration
+	MaxFailures int
+	UserAgent   string
+}
+
+type Service struct {
+	webhookRepository  store[*Webhook]
+	deliveryRepository store[*Delivery]
+	deliver            *queue.Task[DeliverPayload]
+	client             *http.Client
+	opts               Options
+}
+
+func NewWebhookService(pool *pgxpool.Pool, tasks *queue.Registry, opts Options) *Service {
+	s := &Service{
+		webhookRepository:  NewWebhookRepository(pool),
+		deliveryRepository: NewDeliveryRepository(pool),
+		client:             &http.Client{Timeout: opts.Timeout},
+		opts:               opts,
+	}
+	s.deliver = NewDeliverTask(tasks, s)
+
+	return s
+}
+
+func (s *Service) FindByID(ctx context.Context, id uuid.UUID) (*Webhook, error) {
+	return s.webhookRepository.FindByID(ctx, id)
+}
+
+func (s *Service) Paginate(
+	ctx context.Context,
+	page int,
+	perPage int,
+	opts ...storage.QueryOption,
+) (*storage.Paginator[*Webhook], error) {
+	return s.webhookRepository.Paginate(ctx, page, perPage, opts...)
+}
+
+func (s *Service) Create(ctx context.Context, w *Webhook) (*Webhook, error) {
+	if w.Secret == "" {
+		secret, err := GenerateSecret()
+		if err != nil {
+			return nil, err
+		}
+		w.Secret = secret
+	}
+	return s.webhookRepository.Insert(ctx, w)
+}
+
+// Update persists the webhook; enabling it again clears the failure counter.
+func (s *Service) Update(ctx context.Context, w *Webhook) (*Webhook, error) {
+	switch {
+	case w.Active && w.DisabledAt != nil:
+		w.DisabledAt = nil
+		w.FailureCount = 0
+	case !w.Active && w.DisabledAt == nil:
+		w.DisabledAt = f.Pointer(time.Now())
+	}
+	return s.webhookRepository.Update(ctx, w)
+}
+
+func (s *Service) Delete(ctx context.Context, id uuid.UUID) error {
+	return s.webhookRepository.Delete(ctx, id)
+}
+
+func (s *Service) Deliveries(
+	ctx context.Context,
+	webhookID uuid.UUID,
+	page int,
+	perPage int,
+	opts ...storage.QueryOption,
+) (*storage.Paginator[*Delivery], error) {
+	opts = append(opts, storage.WithFilter(storage.NewRule("webhook_id", storage.OpEqual, webhookID)))
+	return s.deliveryRepository.Paginate(ctx, page, perPage, opts...)
+}
+
+// Handle is the event bus listener: it records a delivery for every active
+// webhook subscribed to the event and queues it.
+func (s *Service) Handle(ctx context.Context, ev event.Event) error {
+	hooks, err := s.webhookRepository.List(
+		ctx,
+		storage.WithFilter(
+			storage.NewRule("active", storage.OpEqual, true),
+			storage.NewRule("events", storage.OpContains, []string{ev.Name}),
+		),
+	)
+	if err != nil || len(hooks) == 0 {
+		return err
+	}
+
+	payload, err := sonic.ConfigFastest.Marshal(ev)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, w := range hooks {
+		d := (&Delivery{
+			WebhookID: w.ID,
+			EventID:   ev.ID,
+			Event:     ev.Name,
+			Payload:   payload,
+			Status:    DeliveryPending,
+			CreatedAt: time.Now(),
+		}).NextID()
+		if _, err = s.enqueue(ctx, d); err != nil {
+			errs = append(errs, fmt.Errorf("webhook %s: %w", w.ID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Redeliver sends the payload of a past delivery again as a new delivery.
+func (s *Service) Redeliver(ctx context.Context, webhookID uuid.UUID, deliveryID uuid.UUID) (*Delivery, error) {
+	d, err := s.deliveryRepository.FindByID(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil || d.WebhookID != webhookID {
+		return nil, ErrDeliveryNotFound
+	}
+	w, err := s.webhookRepository.FindByID(ctx, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	if w == nil || !w.Active {
+		return nil, ErrWebhookDisabled
+	}
+
+	return s.enqueue(ctx, (&Delivery{
+		WebhookID: d.WebhookID,
+		EventID:   d.EventID,
+		Event:     d.Event,
+		Payload:   d.Payload,
+		Status:    DeliveryPending,
+		CreatedAt: time.Now(),
+	}).NextID())
+}
+
+// Deliver is the queue handler. A returned error schedules a retry with backoff;
+// when the last attempt fails the webhook failure counter grows and the webhook
+// is disabled once it reaches MaxFailures.
+func (s *Service) Deliver(ctx context.Context, p DeliverPayload) error {
+	d, err := s.deliveryRepository.FindByID(ctx, p.DeliveryID)
+	if err != nil {
+		return err
+	}
+	if d == nil {
+		return fmt.Errorf("delivery %s: %w: %w", p.DeliveryID, ErrDeliveryNotFound, asynq.SkipRetry)
+	}
+	w, err := s.webhookRepository.FindByID(ctx, d.WebhookID)
+	if err != nil {
+		return err
+	}
+	if w == nil || !w.Active {
+		d.Status = DeliveryFailed
+		d.Error = f.Pointer(ErrWebhookDisabled.Error())
+		_, err = s.deliveryRepository.Update(ctx, d)
+		return err
+	}
+
+	start := time.Now()
+	code, body, sendErr := s.send(ctx, w, d)
+	d.Attempts++
+	d.DurationMs = f.Pointer(time.Since(start).Milliseconds())
+	d.ResponseCode = f.PointerOrNil(code)
+	d.ResponseBody = f.PointerOrNil(body)
+
+	if sendErr == nil {
+		d.Status = DeliverySucceeded
+		d.Error = nil
+		d.DeliveredAt = f.Pointer(time.Now())
+		if _, err = s.deliveryRepository.Update(ctx, d); err != nil {
+			return fmt.Errorf("%w: %w", err, asynq.SkipRetry)
+		}
+		if w.FailureCount > 0 {
+			w.FailureCount = 0
+			_, err = s.webhookRepository.Update(ctx, w)
+		}
+		return err
+	}
+
+	last := isLastAttempt(ctx)
+	d.Error = f.Pointer(sendErr.Error())
+	if last {
+		d.Status = DeliveryFailed
+	}
+	if _, err = s.deliveryRepository.Update(ctx, d); err != nil {
+		return errors.Join(sendErr, err)
+	}
+	if last {
+		if err = s.registerFailure(ctx, w); err != nil {
+			return errors.Join(sendErr, err)
+		}
+	}
+
+	return sendErr
+}
+
+func (s *Service) send(ctx context.Context, w *Webhook, d *Delivery) (int, string, error) {
+	timestamp := time.Now().Unix()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", s.opts.UserAgent)
+	req.Header.Set(HeaderID, w.ID.String())
+	req.Header.Set(HeaderDelivery, d.ID.String())
+	req.Header.Set(HeaderEvent, d.Event)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(w.Secret, timestamp, d.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, responseLimit))
+	body := strings.ToValidUTF8(strings.ReplaceAll(string(raw), "\x00", ""), "")
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return resp.StatusCode, body, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, body, nil
+}
+
+func (s *Service) registerFailure(ctx context.Context, w *Webhook) error {
+	w.FailureCount++
+	if s.opts.MaxFailures > 0 && w.FailureCount >= s.opts.MaxFailures {
+		w.Active = false
+		w.DisabledAt = f.Pointer(time.Now())
+		log.Logger().WarnContext(
+			ctx,
+			"Webhook disabled after repeated failures",
+			slog.String("webhook", w.ID.String()),
+			slog.Int("failures", w.FailureCount),
+		)
+	}
+	_, err := s.webhookRepository.Update(ctx, w)
+	return err
+}
+
+func (s *Service) enqueue(ctx context.Context, d *Delivery) (*Delivery, error) {
+	d, err := s.deliveryRepository.Insert(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = s.deliver.Enqueue(ctx, DeliverPayload{DeliveryID: d.ID}); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func GenerateSecret() (string, error) {
+	b := make([]byte, secretLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return secretPrefix + hex.EncodeToString(b), nil
+}
+
+// isLastAttempt reports whether the queue won't retry the task after this run.
+func isLastAttempt(ctx context.Context) bool {
+	retried, ok := asynq.GetRetryCount(ctx)
+	if !ok {
+		return true
+	}
+	maxRetry, ok := asynq.GetMaxRetry(ctx)
+	if !ok {
+		return true
+	}
+	return retried >= maxRetry
+}
@@ -0,0 +1,142 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dbunt1tled/fiber-go-api/pkg/storage"
+	"github.com/google/uuid"
+)
+
+// memoryStore keeps entities in memory in place of a repository.
+type memoryStore[T storage.Model] struct {
+	items map[uuid.UUID]T
+}
+
+func newMemoryStore[T storage.Model](items ...T) *memoryStore[T] {
+	s := &memoryStore[T]{items: make(map[uuid.UUID]T)}
+	for _, item := range items {
+		s.items[item.GetID()] = item
+	}
+	return s
+}
+
+func (s *memoryStore[T]) FindByID(_ context.Context, id uuid.UUID) (T, error) {
+	return s.items[id], nil
+}
+
+func (s *memoryStore[T]) List(_ context.Context, _ ...storage.QueryOption) ([]T, error) {
+	items := make([]T, 0, len(s.items))
+	for _, item := range s.items {
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (s *memoryStore[T]) Paginate(
+	ctx context.Context,
+	page int,
+	perPage int,
+	opts ...storage.QueryOption,
+) (*storage.Paginator[T], error) {
+	items, _ := s.List(ctx, opts...)
+	return storage.NewPaginator(items, int64(len(items)), page, perPage), nil
+}
+
+func (s *memoryStore[T]) Insert(_ context.Context, entity T) (T, error) {
+	s.items[entity.GetID()] = entity
+	return entity, nil
+}
+
+func (s *memoryStore[T]) Update(_ context.Context, entity T) (T, error) {
+	s.items[entity.GetID()] = entity
+	return entity, nil
+}
+
+func (s *memoryStore[T]) Delete(_ context.Context, id uuid.UUID) error {
+	delete(s.items, id)
+	return nil
+}
+
+func TestDeliver(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		wantErr      bool
+		wantStatus   DeliveryStatus
+		wantFailures int
+	}{
+		{name: "success", status: http.StatusNoContent, wantStatus: DeliverySucceeded},
+		{name: "server error", status: http.StatusBadGateway, wantErr: true, wantStatus: DeliveryFailed, wantFailures: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received *http.Request
+			var receivedBody []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received = r
+				receivedBody, _ = io.ReadAll(r.Body)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			w := (&Webhook{URL: server.URL, Secret: "whsec_test", Events: []string{"user.created"}, Active: true}).NextID()
+			d := (&Delivery{
+				WebhookID: w.ID,
+				EventID:   uuid.New(),
+				Event:     "user.created",
+				Payload:   []byte(`{"name":"user.created"}`),
+				Status:    DeliveryPending,
+			}).NextID()
+			webhooks := newMemoryStore(w)
+			deliveries := newMemoryStore(d)
+			s := &Service{
+				webhookRepository:  webhooks,
+				deliveryRepository: deliveries,
+				client:             server.Client(),
+				opts:               Options{Timeout: time.Second, UserAgent: "test"},
+			}
+
+			// outside the queue every run counts as the last attempt
+			err := s.Deliver(context.Background(), DeliverPayload{DeliveryID: d.ID})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Deliver() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if received == nil {
+				t.Fatal("receiver got no request")
+			}
+			if got := received.Header.Get(HeaderDelivery); got != d.ID.String() {
+				t.Errorf("%s = %q, want %q", HeaderDelivery, got, d.ID)
+			}
+			if got := received.Header.Get(HeaderEvent); got != d.Event {
+				t.Errorf("%s = %q, want %q", HeaderEvent, got, d.Event)
+			}
+			if !Verify(w.Secret, received.Header.Get(HeaderTimestamp), receivedBody, received.Header.Get(HeaderSignature), time.Minute) {
+				t.Errorf("signature %q doesn't verify", received.Header.Get(HeaderSignature))
+			}
+
+			stored := deliveries.items[d.ID]
+			if stored.Status != tt.wantStatus {
+				t.Errorf("delivery status = %v, want %v", stored.Status, tt.wantStatus)
+			}
+			if stored.ResponseCode == nil || *stored.ResponseCode != tt.status {
+				t.Errorf("delivery response code = %v, want %d", stored.ResponseCode, tt.status)
+			}
+			if stored.Attempts != 1 {
+				t.Errorf("delivery attempts = %d, want 1", stored.Attempts)
+			}
+			if tt.wantErr && stored.Error == nil {
+				t.Error("delivery error wasn't recorded")
+			}
+			if got := webhooks.items[w.ID].FailureCount; got != tt.wantFailures {
+				t.Errorf("webhook failures = %d, want %d", got, tt.wantFailures)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package webhook
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSignVerify(t *testing.T) {
+	const secret = "whsec_test"
+	body := []byte(`{"name":"user.created"}`)
+	now := time.Now().Unix()
+
+	tests := []struct {
+		name      string
+		timestamp int64
+		body      []byte
+		secret    string
+		want      bool
+	}{
+		{name: "round trip", timestamp: now, body: body, secret: secret, want: true},
+		{name: "stale timestamp", timestamp: now - 600, body: body, secret: secret, want: false},
+		{name: "future timestamp", timestamp: now + 600, body: body, secret: secret, want: false},
+		{name: "tampered body", timestamp: now, body: []byte(`{"name":"user.deleted"}`), secret: secret, want: false},
+		{name: "other secret", timestamp: now, body: body, secret: "whsec_other", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signature := Sign(secret, tt.timestamp, body)
+			got := Verify(tt.secret, strconv.FormatInt(tt.timestamp, 10), tt.body, signature, 5*time.Minute)
+			if got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsMalformedInput(t *testing.T) {
+	const secret = "whsec_test"
+	body := []byte("{}")
+	now := time.Now().Unix()
+	signature := Sign(secret, now, body)
+
+	if Verify(secret, "not-a-number", body, signature, time.Minute) {
+		t.Error("Verify() accepted a malformed timestamp")
+	}
+	if Verify(secret, strconv.FormatInt(now, 10), body, signature[len(signatureVersion):], time.Minute) {
+		t.Error("Verify() accepted a signature without version prefix")
+	}
+}
@@ -0,0 +1,31 @@
+package webhook
+
+import (
+	"time"
+
+	"github.com/dbunt1tled/fiber-go-api/pkg/f"
+	"github.com/dbunt1tled/fiber-go-api/pkg/queue"
+	"github.com/google/uuid"
+)
+
+const (
+	DeliverQueue   = "webhooks"
+	DeliverTask    = "webhook:deliver"
+	DeliverVersion = 1
+	DeliverRetry   = 8
+	DeliverTimeOut = time.Minute
+)
+
+type DeliverPayload struct {
+	DeliveryID uuid.UUID `json:"deliveryId"`
+}
+
+// NewDeliverTask registers delivery attempts. Backoff between attempts comes from
+// the `queue.tasks.webhookdeliver.retry` configuration.
+func NewDeliverTask(r *queue.Registry, s *Service) *queue.Task[DeliverPayload] {
+	return queue.Register(r, DeliverTask, DeliverVersion, queue.TaskOptions{
+		Queue:    DeliverQueue,
+		MaxRetry: f.Pointer(DeliverRetry),
+		Timeout:  DeliverTimeOut,
+	}, s.Deliver)
+}
@@ -0,0 +1,61 @@
+package webhook
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Webhook struct {
+	ID           uuid.UUID  `db:"id"            json:"id"`
+	URL          string     `db:"url"           json:"url"`
+	Secret       string     `db:"secret"        json:"-"`
+	Events       []string   `db:"events"        json:"events"`
+	Active       bool       `db:"active"        json:"active"`
+	FailureCount int        `db:"failure_count" json:"failureCount"`
+	DisabledAt   *time.Time `db:"disabled_at"   json:"disabledAt"`
+	CreatedAt    time.Time  `db:"created_at"    json:"createdAt"`
+	UpdatedAt    time.Time  `db:"updated_at"    json:"updatedAt"`
+}
+
+func (w *Webhook) TableName() string  { return "webhooks" }
+func (w *Webhook) GetID() uuid.UUID   { return w.ID }
+func (w *Webhook) SetID(id uuid.UUID) { w.ID = id }
+func (w *Webhook) NextID() *Webhook {
+	w.ID = nextID()
+	return w
+}
+
+type Delivery struct {
+	ID           uuid.UUID      `db:"id"            json:"id"`
+	WebhookID    uuid.UUID      `db:"webhook_id"    json:"webhookId"`
+	EventID      uuid.UUID      `db:"event_id"      json:"eventId"`
+	Event        string         `db:"event"         json:"event"`
+	Payload      []byte         `db:"payload"       json:"-"`
+	Status       DeliveryStatus `db:"status"        json:"status"`
+	Attempts     int            `db:"attempts"      json:"attempts"`
+	ResponseCode *int           `db:"response_code" json:"responseCode"`
+	ResponseBody *string        `db:"response_body" json:"responseBody"`
+	Error        *string        `db:"error"         json:"error"`
+	DurationMs   *int64         `db:"duration_ms"   json:"durationMs"`
+	DeliveredAt  *time.Time     `db:"delivered_at"  json:"deliveredAt"`
+	CreatedAt    time.Time      `db:"created_at"    json:"createdAt"`
+	UpdatedAt    time.Time      `db:"updated_at"    json:"updatedAt"`
+}
+
+func (d *Delivery) TableName() string  { return "webhook_deliveries" }
+func (d *Delivery) GetID() uuid.UUID   { return d.ID }
+func (d *Delivery) SetID(id uuid.UUID) { d.ID = id }
+func (d *Delivery) NextID() *Delivery {
+	d.ID = nextID()
+	return d
+}
+
+func nextID() uuid.UUID {
+	id, err := uuid.NewV7()
+	if err != nil {
+		panic(fmt.Errorf("failed to generate uuid: %w", err))
+	}
+	return id
+}
@@ -0,0 +1,44 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE webhooks (
+   id UUID PRIMARY KEY DEFAULT uuidv7(),
+   url VARCHAR(2048) NOT NULL,
+   secret VARCHAR(255) NOT NULL,
+   events TEXT[] NOT NULL DEFAULT '{}',
+   active BOOLEAN NOT NULL DEFAULT TRUE,
+   failure_count INTEGER NOT NULL DEFAULT 0,
+   disabled_at TIMESTAMP,
+   created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+   updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_webhooks_events ON webhooks USING GIN(events);
+CREATE INDEX idx_webhooks_active ON webhooks(active);
+
+CREATE TABLE webhook_deliveries (
+   id UUID PRIMARY KEY DEFAULT uuidv7(),
+   webhook_id UUID NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+   event_id UUID NOT NULL,
+   event VARCHAR(255) NOT NULL,
+   payload JSONB NOT NULL,
+   status INTEGER NOT NULL DEFAULT 0,
+   attempts INTEGER NOT NULL DEFAULT 0,
+   response_code INTEGER,
+   response_body TEXT,
+   error TEXT,
+   duration_ms BIGINT,
+   delivered_at TIMESTAMP,
+   created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+   updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_webhook_deliveries_webhook_id ON webhook_deliveries(webhook_id);
+CREATE INDEX idx_webhook_deliveries_status ON webhook_deliveries(status);
+CREATE INDEX idx_webhook_deliveries_created_at ON webhook_deliveries(created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
+-- +goose StatementEnd
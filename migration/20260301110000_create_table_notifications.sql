@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE notifications (
+   id UUID PRIMARY KEY DEFAULT uuidv7(),
+   user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+   type VARCHAR(100) NOT NULL,
+   title VARCHAR(255) NOT NULL,
+   body TEXT NOT NULL DEFAULT '',
+   data JSONB NOT NULL DEFAULT '{}',
+   read_at TIMESTAMP,
+   created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+   updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_notifications_user_id_created_at ON notifications(user_id, created_at DESC);
+CREATE INDEX idx_notifications_user_id_unread ON notifications(user_id) WHERE read_at IS NULL;
+
+CREATE TABLE notification_preferences (
+   id UUID PRIMARY KEY DEFAULT uuidv7(),
+   user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+   type VARCHAR(100) NOT NULL,
+   channels TEXT[] NOT NULL DEFAULT '{}',
+   created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+   updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+   UNIQUE (user_id, type)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS notification_preferences;
+DROP TABLE IF EXISTS notifications;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE files (
+   id UUID PRIMARY KEY DEFAULT uuidv7(),
+   user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+   driver VARCHAR(20) NOT NULL,
+   key VARCHAR(512) NOT NULL,
+   name VARCHAR(255) NOT NULL,
+   mime VARCHAR(100) NOT NULL,
+   size BIGINT NOT NULL,
+   checksum VARCHAR(64) NOT NULL,
+   metadata JSONB NOT NULL DEFAULT '{}',
+   created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+   updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_files_user_id_created_at ON files(user_id, created_at DESC);
+CREATE INDEX idx_files_checksum ON files(checksum);
+
+ALTER TABLE users ADD COLUMN avatar_id UUID REFERENCES files(id) ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN IF EXISTS avatar_id;
+DROP TABLE IF EXISTS files;
+-- +goose StatementEnd
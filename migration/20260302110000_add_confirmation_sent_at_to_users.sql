@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN confirmation_sent_at TIMESTAMP;
+UPDATE users SET confirmation_sent_at = created_at;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN IF EXISTS confirmation_sent_at;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE user_identities (
+   id UUID PRIMARY KEY DEFAULT uuidv7(),
+   user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+   provider VARCHAR(50) NOT NULL,
+   subject VARCHAR(255) NOT NULL,
+   email VARCHAR(255),
+   created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+   updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_user_identities_provider_subject ON user_identities(provider, subject);
+CREATE UNIQUE INDEX idx_user_identities_user_id_provider ON user_identities(user_id, provider);
+
+-- users created from a social login have no phone number
+ALTER TABLE users ALTER COLUMN phone_number DROP NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_identities;
+ALTER TABLE users ALTER COLUMN phone_number SET NOT NULL;
+-- +goose StatementEnd
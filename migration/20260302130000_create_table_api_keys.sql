@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE api_keys (
+   id UUID PRIMARY KEY DEFAULT uuidv7(),
+   user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+   name VARCHAR(100) NOT NULL,
+   prefix VARCHAR(50) NOT NULL,
+   hash VARCHAR(64) NOT NULL,
+   scopes TEXT[] NOT NULL DEFAULT '{}',
+   expires_at TIMESTAMP,
+   last_used_at TIMESTAMP,
+   revoked_at TIMESTAMP,
+   created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+   updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_api_keys_prefix ON api_keys(prefix);
+CREATE INDEX idx_api_keys_user_id_created_at ON api_keys(user_id, created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS api_keys;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE oauth_clients (
+   id UUID PRIMARY KEY DEFAULT uuidv7(),
+   name VARCHAR(100) NOT NULL,
+   client_id VARCHAR(50) NOT NULL,
+   secret_hash VARCHAR(64) NOT NULL,
+   scopes TEXT[] NOT NULL DEFAULT '{}',
+   active BOOLEAN NOT NULL DEFAULT TRUE,
+   created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+   updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_oauth_clients_client_id ON oauth_clients(client_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS oauth_clients;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE impersonations (
+   id UUID PRIMARY KEY DEFAULT uuidv7(),
+   actor_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+   user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+   reason VARCHAR(255) NOT NULL,
+   ip VARCHAR(45) NOT NULL DEFAULT '',
+   expires_at TIMESTAMP NOT NULL,
+   stopped_at TIMESTAMP,
+   stopped_by UUID REFERENCES users(id) ON DELETE SET NULL,
+   created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+   updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_impersonations_actor_id_created_at ON impersonations(actor_id, created_at DESC);
+CREATE INDEX idx_impersonations_user_id_created_at ON impersonations(user_id, created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS impersonations;
+-- +goose StatementEnd
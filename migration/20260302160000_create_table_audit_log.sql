@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- actors and targets aren't foreign keys, entries outlive what they are about
+CREATE TABLE audit_log (
+   id UUID PRIMARY KEY DEFAULT uuidv7(),
+   actor_id UUID,
+   impersonator_id UUID,
+   action VARCHAR(100) NOT NULL,
+   target_type VARCHAR(50) NOT NULL DEFAULT '',
+   target_id VARCHAR(100) NOT NULL DEFAULT '',
+   changes JSONB NOT NULL DEFAULT '{}',
+   details JSONB NOT NULL DEFAULT '{}',
+   ip VARCHAR(45) NOT NULL DEFAULT '',
+   request_id VARCHAR(100) NOT NULL DEFAULT '',
+   created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_audit_log_created_at ON audit_log(created_at DESC);
+CREATE INDEX idx_audit_log_actor_id_created_at ON audit_log(actor_id, created_at DESC);
+CREATE INDEX idx_audit_log_target_created_at ON audit_log(target_type, target_id, created_at DESC);
+CREATE INDEX idx_audit_log_action_created_at ON audit_log(action, created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS audit_log;
+-- +goose StatementEnd
@@ -0,0 +1,54 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Entry is a row of the audit log: who did what to which record, and what changed.
+type Entry struct {
+	ID      uuid.UUID  `db:"id"       json:"id"`
+	ActorID *uuid.UUID `db:"actor_id" json:"actorId"`
+	// ImpersonatorID is the admin who acted as the actor.
+	ImpersonatorID *uuid.UUID      `db:"impersonator_id" json:"impersonatorId"`
+	Action         string          `db:"action"          json:"action"`
+	TargetType     string          `db:"target_type"     json:"targetType"`
+	TargetID       string          `db:"target_id"       json:"targetId"`
+	Changes        json.RawMessage `db:"changes"         json:"changes"`
+	Details        json.RawMessage `db:"details"         json:"details"`
+	IP             string          `db:"ip"              json:"ip"`
+	RequestID      string          `db:"request_id"      json:"requestId"`
+	CreatedAt      time.Time       `db:"created_at"      json:"createdAt"`
+}
+
+func (e *Entry) TableName() string  { return "audit_log" }
+func (e *Entry) GetID() uuid.UUID   { return e.ID }
+func (e *Entry) SetID(id uuid.UUID) { e.ID = id }
+func (e *Entry) NextID() *Entry {
+	var err error
+	e.ID, err = uuid.NewV7()
+	if err != nil {
+		panic(fmt.Errorf("failed to generate uuid: %w", err))
+	}
+	return e
+}
+
+// Action is what a service reports to the audit log, the request it happens in fills in the rest of the entry.
+type Action struct {
+	Name       string
+	TargetType string
+	TargetID   string
+	Changes    Changes
+	Details    map[string]any
+	// ActorID stands in for the actor of the request, e.g. on sign in, before anyone is authenticated.
+	ActorID *uuid.UUID
+}
+
+// Recorder is the part of the audit log services depend on.
+type Recorder interface {
+	Record(ctx context.Context, action Action)
+}
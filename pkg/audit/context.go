@@ -0,0 +1,38 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type contextKey int
+
+const metaKey contextKey = iota
+
+// Meta is what the audit log knows about the request an action happens in. The HTTP middleware puts it in
+// the request context and authentication sets the actor, so services record actions with the context alone.
+type Meta struct {
+	ActorID        *uuid.UUID
+	ImpersonatorID *uuid.UUID
+	IP             string
+	RequestID      string
+}
+
+func WithMeta(ctx context.Context, meta *Meta) context.Context {
+	return context.WithValue(ctx, metaKey, meta)
+}
+
+// MetaFrom returns the meta of the request, nil outside of requests, e.g. in tasks.
+func MetaFrom(ctx context.Context) *Meta {
+	meta, _ := ctx.Value(metaKey).(*Meta)
+	return meta
+}
+
+// SetActor records who the request is authenticated as, impersonatorID is the admin acting as them if any.
+func SetActor(ctx context.Context, actorID uuid.UUID, impersonatorID *uuid.UUID) {
+	if meta := MetaFrom(ctx); meta != nil {
+		meta.ActorID = &actorID
+		meta.ImpersonatorID = impersonatorID
+	}
+}
@@ -0,0 +1,133 @@
+package audit
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+)
+
+const redacted = "[redacted]"
+
+// Change is a field before and after an action, nil where the record didn't exist.
+type Change struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+type Changes map[string]Change
+
+// Diff compares records built by a storage.RecordBuilder and returns the fields that differ; before is nil
+// for created records and after for deleted ones. Values of redact fields are hidden, only the fact they
+// changed is kept. SQL expressions other than arrays, e.g. NOW(), are left out.
+func Diff(before goqu.Record, after goqu.Record, redact ...string) Changes {
+	changes := make(Changes)
+	for _, field := range fields(before, after) {
+		old, okOld := normalize(before[field])
+		cur, okNew := normalize(after[field])
+		if !okOld || !okNew || equal(old, cur) {
+			continue
+		}
+		if slices.Contains(redact, field) {
+			old, cur = hide(old), hide(cur)
+		}
+		changes[field] = Change{Old: old, New: cur}
+	}
+
+	return changes
+}
+
+// Only keeps the given fields.
+func (c Changes) Only(fields ...string) Changes {
+	only := make(Changes)
+	for _, field := range fields {
+		if change, ok := c[field]; ok {
+			only[field] = change
+		}
+	}
+	return only
+}
+
+// Without leaves the given fields out.
+func (c Changes) Without(fields ...string) Changes {
+	without := make(Changes, len(c))
+	for field, change := range c {
+		without[field] = change
+	}
+	for _, field := range fields {
+		delete(without, field)
+	}
+	return without
+}
+
+func fields(records ...goqu.Record) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, record := range records {
+		for key := range record {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// normalize turns record values into what they are stored as, false for values the diff leaves out.
+func normalize(value any) (any, bool) {
+	if value == nil {
+		return nil, true
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return nil, true
+	}
+
+	switch v := value.(type) {
+	case exp.LiteralExpression:
+		if !strings.HasPrefix(v.Literal(), "ARRAY[") {
+			return nil, false
+		}
+		if len(v.Args()) == 0 {
+			return []any{}, true
+		}
+		return v.Args(), true
+	case time.Time:
+		// the database keeps microseconds
+		return v.UTC().Truncate(time.Microsecond), true
+	case driver.Valuer:
+		stored, err := v.Value()
+		if err != nil {
+			return nil, false
+		}
+		return normalize(stored)
+	}
+
+	if rv.Kind() == reflect.Pointer {
+		return normalize(rv.Elem().Interface())
+	}
+	return value, true
+}
+
+func equal(a any, b any) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	if errA != nil || errB != nil {
+		return reflect.DeepEqual(a, b)
+	}
+	return bytes.Equal(ja, jb)
+}
+
+func hide(value any) any {
+	if value == nil {
+		return nil
+	}
+	return redacted
+}
@@ -0,0 +1,99 @@
+package audit
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+)
+
+func TestDiff(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 123456789, time.UTC)
+	name := "Jane"
+
+	tests := []struct {
+		name   string
+		before goqu.Record
+		after  goqu.Record
+		redact []string
+		want   Changes
+	}{
+		{
+			name:   "changed field",
+			before: goqu.Record{"first_name": "John", "email": "j@example.com"},
+			after:  goqu.Record{"first_name": "Jane", "email": "j@example.com"},
+			want:   Changes{"first_name": {Old: "John", New: "Jane"}},
+		},
+		{
+			name:   "redacted field",
+			before: goqu.Record{"password": "old-hash"},
+			after:  goqu.Record{"password": "new-hash"},
+			redact: []string{"password"},
+			want:   Changes{"password": {Old: redacted, New: redacted}},
+		},
+		{
+			name:   "unchanged redacted field",
+			before: goqu.Record{"password": "hash"},
+			after:  goqu.Record{"password": "hash"},
+			redact: []string{"password"},
+			want:   Changes{},
+		},
+		{
+			name:   "sql expression is skipped",
+			before: goqu.Record{"updated_at": now},
+			after:  goqu.Record{"updated_at": goqu.L("NOW()")},
+			want:   Changes{},
+		},
+		{
+			name:   "array literal",
+			before: goqu.Record{"roles": goqu.L("ARRAY[?]", "user")},
+			after:  goqu.Record{"roles": goqu.L("ARRAY[?,?]", "user", "admin")},
+			want:   Changes{"roles": {Old: []any{"user"}, New: []any{"user", "admin"}}},
+		},
+		{
+			name:   "empty array literal",
+			before: goqu.Record{"roles": goqu.L("ARRAY[]::text[]")},
+			after:  goqu.Record{"roles": goqu.L("ARRAY[?]", "user")},
+			want:   Changes{"roles": {Old: []any{}, New: []any{"user"}}},
+		},
+		{
+			name:   "time below microseconds is equal",
+			before: goqu.Record{"confirmed_at": now.Truncate(time.Microsecond)},
+			after:  goqu.Record{"confirmed_at": now},
+			want:   Changes{},
+		},
+		{
+			name:   "time in another zone is equal",
+			before: goqu.Record{"confirmed_at": now.In(time.FixedZone("UTC+2", 2*60*60))},
+			after:  goqu.Record{"confirmed_at": now},
+			want:   Changes{},
+		},
+		{
+			name:   "pointer fields",
+			before: goqu.Record{"first_name": (*string)(nil)},
+			after:  goqu.Record{"first_name": &name},
+			want:   Changes{"first_name": {Old: nil, New: "Jane"}},
+		},
+		{
+			name:   "nil before",
+			before: nil,
+			after:  goqu.Record{"first_name": "Jane"},
+			want:   Changes{"first_name": {Old: nil, New: "Jane"}},
+		},
+		{
+			name:   "nil after",
+			before: goqu.Record{"first_name": "Jane"},
+			after:  nil,
+			want:   Changes{"first_name": {Old: "Jane", New: nil}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Diff(tt.before, tt.after, tt.redact...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/dbunt1tled/fiber-go-api/pkg/storage"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// store is the part of the repository the service uses.
+type store interface {
+	Paginate(ctx context.Context, page int, perPage int, opts ...storage.QueryOption) (*storage.Paginator[*Entry], error)
+	InsertIgnore(ctx context.Context, e *Entry) error
+}
+
+type Repository struct {
+	*storage.Repository[*Entry]
+}
+
+func NewAuditRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{
+		Repository: storage.NewRepository[*Entry](
+			pool,
+			"audit_log",
+			scanEntry,
+			scanEntries,
+			buildEntryRecord,
+		),
+	}
+}
+
+func scanEntry(row pgx.Row) (*Entry, error) {
+	var e Entry
+
+	err := row.Scan(
+		&e.ID,
+		&e.ActorID,
+		&e.ImpersonatorID,
+		&e.Action,
+		&e.TargetType,
+		&e.TargetID,
+		&e.Changes,
+		&e.Details,
+		&e.IP,
+		&e.RequestID,
+		&e.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &e, nil
+}
+
+func scanEntries(rows pgx.Rows) ([]*Entry, error) {
+	return storage.ScanRowsWithScanner(rows, scanEntry)
+}
+
+func buildEntryRecord(e *Entry) goqu.Record {
+	changes, details := "{}", "{}"
+	if len(e.Changes) > 0 {
+		changes = string(e.Changes)
+	}
+	if len(e.Details) > 0 {
+		details = string(e.Details)
+	}
+
+	return goqu.Record{
+		"id":              e.ID,
+		"actor_id":        e.ActorID,
+		"impersonator_id": e.ImpersonatorID,
+		"action":          e.Action,
+		"target_type":     e.TargetType,
+		"target_id":       e.TargetID,
+		"changes":         changes,
+		"details":         details,
+		"ip":              e.IP,
+		"request_id":      e.RequestID,
+		"created_at":      e.CreatedAt,
+	}
+}
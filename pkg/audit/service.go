@@ -0,0 +1,112 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/dbunt1tled/fiber-go-api/pkg/f"
+	"github.com/dbunt1tled/fiber-go-api/pkg/log"
+	"github.com/dbunt1tled/fiber-go-api/pkg/queue"
+	"github.com/dbunt1tled/fiber-go-api/pkg/storage"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	WriteTask    = "audit:write"
+	WriteVersion = 1
+	WriteRetry   = 5
+	WriteTimeOut = 10 * time.Second
+)
+
+type Options struct {
+	// Async writes entries from the queue, off the request path.
+	Async bool
+}
+
+type Service struct {
+	auditRepository store
+	write           *queue.Task[Entry]
+	opts            Options
+}
+
+func NewAuditService(pool *pgxpool.Pool, tasks *queue.Registry, opts Options) *Service {
+	s := &Service{
+		auditRepository: NewAuditRepository(pool),
+		opts:            opts,
+	}
+	s.write = queue.Register(tasks, WriteTask, WriteVersion, queue.TaskOptions{
+		MaxRetry: f.Pointer(WriteRetry),
+		Timeout:  WriteTimeOut,
+	}, s.Write)
+
+	return s
+}
+
+func (s *Service) Paginate(
+	ctx context.Context,
+	page int,
+	perPage int,
+	opts ...storage.QueryOption,
+) (*storage.Paginator[*Entry], error) {
+	return s.auditRepository.Paginate(ctx, page, perPage, opts...)
+}
+
+// Record adds the action to the log. Errors are logged and never reach the caller, auditing can't fail
+// the action it records; when the entry can't be queued it is written right away.
+func (s *Service) Record(ctx context.Context, action Action) {
+	e, err := newEntry(ctx, action)
+	if err != nil {
+		log.Logger().ErrorContext(ctx, "Audit: entry error", err, "action", action.Name)
+		return
+	}
+
+	if s.opts.Async {
+		if _, err = s.write.Enqueue(ctx, *e); err == nil {
+			return
+		}
+		log.Logger().ErrorContext(ctx, "Audit: enqueue error", err, "action", action.Name)
+	}
+	if err = s.Write(ctx, *e); err != nil {
+		log.Logger().ErrorContext(ctx, "Audit: write error", err, "action", action.Name)
+	}
+}
+
+// Write stores an entry, it also handles the queued writes. A retry of a write that reached
+// the database before failing keeps the stored entry.
+func (s *Service) Write(ctx context.Context, e Entry) error {
+	return s.auditRepository.InsertIgnore(ctx, &e)
+}
+
+func newEntry(ctx context.Context, action Action) (*Entry, error) {
+	e := (&Entry{
+		Action:     action.Name,
+		TargetType: action.TargetType,
+		TargetID:   action.TargetID,
+		CreatedAt:  time.Now(),
+	}).NextID()
+
+	var err error
+	if len(action.Changes) > 0 {
+		if e.Changes, err = json.Marshal(action.Changes); err != nil {
+			return nil, err
+		}
+	}
+	if len(action.Details) > 0 {
+		if e.Details, err = json.Marshal(action.Details); err != nil {
+			return nil, err
+		}
+	}
+
+	if meta := MetaFrom(ctx); meta != nil {
+		e.ActorID = meta.ActorID
+		e.ImpersonatorID = meta.ImpersonatorID
+		e.IP = meta.IP
+		e.RequestID = meta.RequestID
+	}
+	if action.ActorID != nil {
+		e.ActorID = action.ActorID
+	}
+
+	return e, nil
+}
@@ -0,0 +1,55 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dbunt1tled/fiber-go-api/pkg/log"
+	"github.com/dbunt1tled/fiber-go-api/pkg/queue"
+	"github.com/dbunt1tled/fiber-go-api/pkg/storage"
+	"github.com/google/uuid"
+)
+
+// memoryStore keeps entries in memory in place of the repository.
+type memoryStore struct {
+	entries map[uuid.UUID]*Entry
+}
+
+func (s *memoryStore) Paginate(
+	_ context.Context,
+	_ int,
+	_ int,
+	_ ...storage.QueryOption,
+) (*storage.Paginator[*Entry], error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s *memoryStore) InsertIgnore(_ context.Context, e *Entry) error {
+	if _, ok := s.entries[e.ID]; !ok {
+		s.entries[e.ID] = e
+	}
+	return nil
+}
+
+func TestRecordFallsBackToWrite(t *testing.T) {
+	log.Load("test", "dev", log.LevelDebug, "")
+
+	// nothing listens on the port, so every enqueue fails
+	producer := queue.NewQueueProducer(queue.RedisOptions{Addr: "127.0.0.1:1"}, nil)
+	defer producer.Close()
+	entries := &memoryStore{entries: make(map[uuid.UUID]*Entry)}
+	s := &Service{auditRepository: entries, opts: Options{Async: true}}
+	s.write = queue.Register(queue.NewRegistry(producer), WriteTask, WriteVersion, queue.TaskOptions{}, s.Write)
+
+	s.Record(context.Background(), Action{Name: "user.updated", TargetType: "user", TargetID: "42"})
+
+	if len(entries.entries) != 1 {
+		t.Fatalf("stored %d entries, want 1", len(entries.entries))
+	}
+	for _, e := range entries.entries {
+		if e.Action != "user.updated" || e.TargetID != "42" {
+			t.Errorf("stored entry = %+v", e)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package breach
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1" //nolint:gosec // the breach corpus is indexed by SHA-1
+	"encoding/hex"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// prefixLength is the number of hex characters of the hash shared with a range endpoint.
+const prefixLength = 5
+
+// Checker tells whether a password appears in a corpus of breached passwords.
+type Checker interface {
+	Breached(ctx context.Context, password string) (bool, error)
+}
+
+// Hash is the uppercase hex SHA-1 both the corpus files and the range API use.
+func Hash(password string) string {
+	sum := sha1.Sum([]byte(password)) //nolint:gosec // the breach corpus is indexed by SHA-1
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// inRange scans `SUFFIX:COUNT` lines, padding entries with a zero count are ignored.
+func inRange(r io.Reader, suffix string) (bool, error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, count, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !ok || !strings.EqualFold(line, suffix) {
+			continue
+		}
+		n, err := strconv.Atoi(count)
+		return err == nil && n > 0, nil
+	}
+	return false, scanner.Err()
+}
@@ -0,0 +1,90 @@
+package breach
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+// FileChecker searches a local copy of the corpus: `HASH:COUNT` lines sorted by hash,
+// as written by the Pwned Passwords downloader. Passwords never leave the server.
+type FileChecker struct {
+	path string
+}
+
+func NewFileChecker(path string) (*FileChecker, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &FileChecker{path: path}, f.Close()
+}
+
+func (c *FileChecker) Breached(_ context.Context, password string) (bool, error) {
+	f, err := os.Open(c.path)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	info, err := f.Stat()
+	if err != nil {
+		return false, err
+	}
+
+	// binary search over byte offsets for the first line whose hash is not below ours
+	hash := Hash(password)
+	low, high := int64(0), info.Size()
+	for low < high {
+		mid := low + (high-low)/2 //nolint:mnd // halve the range
+		line, next, err := lineAt(f, mid)
+		if err != nil {
+			return false, err
+		}
+		if line == "" || lineHash(line) >= hash {
+			high = mid
+		} else {
+			low = next
+		}
+	}
+
+	line, _, err := lineAt(f, low)
+	if err != nil {
+		return false, err
+	}
+	return inRange(strings.NewReader(line), hash)
+}
+
+// lineAt returns the first line starting at or after offset and the offset of the line behind it.
+func lineAt(f *os.File, offset int64) (string, int64, error) {
+	start := offset
+	if offset > 0 {
+		// the character before offset tells whether a line starts exactly there
+		start--
+	}
+	r := bufio.NewReader(io.NewSectionReader(f, start, 1<<62)) //nolint:mnd // up to the end of the file
+	if offset > 0 {
+		skipped, err := r.ReadString('\n')
+		if errors.Is(err, io.EOF) {
+			return "", start + int64(len(skipped)), nil
+		}
+		if err != nil {
+			return "", 0, err
+		}
+		start += int64(len(skipped))
+	}
+	line, err := r.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", 0, err
+	}
+	return strings.TrimSpace(line), start + int64(len(line)), nil
+}
+
+func lineHash(line string) string {
+	hash, _, _ := strings.Cut(line, ":")
+	return strings.ToUpper(hash)
+}
@@ -0,0 +1,46 @@
+package breach
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HTTPChecker asks a Pwned Passwords compatible range endpoint. Only the first
+// five characters of the hash are sent and the response is padded, so neither
+// the password nor whether it matched leaks to the service.
+type HTTPChecker struct {
+	endpoint string
+	client   *http.Client
+}
+
+func NewHTTPChecker(endpoint string, timeout time.Duration) *HTTPChecker {
+	return &HTTPChecker{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+func (c *HTTPChecker) Breached(ctx context.Context, password string) (bool, error) {
+	hash := Hash(password)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint+"/range/"+hash[:prefixLength], nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Add-Padding", "true")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("breach range request: status %d", resp.StatusCode)
+	}
+
+	return inRange(resp.Body, hash[prefixLength:])
+}
@@ -24,6 +24,25 @@ const (
 	Err401RefreshUserNotActiveError
 	Err401SystemEmptyTokenError
 	Err401SystemTokenError
+	Err401MagicLinkError
+	Err401MagicLinkUserNotActiveError
+	Err401IdentityUserNotActiveError
+	Err401APIKeyError
+	Err401APIKeyUserNotActiveError
+	Err401ClientError
+	Err401ClientNotActiveError
+	Err401ImpersonationError
+)
+
+const (
+	// 403 Forbidden errors.
+	_ = 40300000 + iota
+	Err403AdminRequiredError
+	Err403URLSignatureInvalid
+	Err403APIKeyScopeError
+	Err403APIKeyNotAllowedError
+	Err403ScopeError
+	Err403ImpersonationForbiddenError
 )
 
 const (
@@ -32,6 +51,24 @@ const (
 	Err404NotFoundDefault
 	Err404UserNotFound
 	Err404URLExpired
+	Err404MailPreviewNotFound
+	Err404QueueNotFound
+	Err404QueueTaskNotFound
+	Err404WebhookNotFound
+	Err404WebhookDeliveryNotFound
+	Err404NotificationNotFound
+	Err404FileNotFound
+	Err404IdentityProviderNotFound
+	Err404IdentityNotFound
+	Err404APIKeyNotFound
+	Err404ClientNotFound
+	Err404ImpersonationNotFound
+)
+
+const (
+	// 409 Conflict errors.
+	_ = 40900000 + iota
+	Err409ConflictError
 )
 
 const (
@@ -62,4 +99,90 @@ const (
 	Err422TokenSubjectError
 	Err422UserListValidateError
 	Err422UserListError
+	Err422MailPreviewValidateError
+	Err422MailPreviewRenderError
+	Err422MailPreviewSendError
+	Err422QueueValidateError
+	Err422QueueListError
+	Err422QueueTaskListError
+	Err422QueueTaskRunError
+	Err422QueueTaskDeleteError
+	Err422QueuePauseError
+	Err422QueueTaskCancelError
+	Err422WebhookValidateError
+	Err422WebhookListError
+	Err422WebhookCreateError
+	Err422WebhookUpdateError
+	Err422WebhookDeleteError
+	Err422WebhookDeliveryListError
+	Err422WebhookRedeliverError
+	Err422WebhookDisabledError
+	Err422EventStreamError
+	Err422EventValidateError
+	Err422EventPublishError
+	Err422NotificationValidateError
+	Err422NotificationListError
+	Err422NotificationUpdateError
+	Err422NotificationDeleteError
+	Err422NotificationPreferenceError
+	Err422NotificationUnknownTypeError
+	Err422NotificationSendError
+	Err422FileValidateError
+	Err422FileUploadError
+	Err422FileTooLargeError
+	Err422FileTypeError
+	Err422FileListError
+	Err422FileDeleteError
+	Err422FileDownloadError
+	Err422AvatarError
+	Err422PasswordForgotValidateError
+	Err422SendPasswordResetEmailError
+	Err422PasswordResetValidateError
+	Err422PasswordResetError
+	Err422NotificationUnsubscribeError
+	Err422EmailChangeValidateError
+	Err422EmailChangePasswordWrongError
+	Err422EmailChangeError
+	Err422SendEmailChangeEmailError
+	Err422EmailConfirmValidateError
+	Err422EmailConfirmError
+	Err422ConfirmResendValidateError
+	Err422ConfirmResendError
+	Err422MagicLinkValidateError
+	Err422MagicLinkError
+	Err422SendMagicLinkEmailError
+	Err422IdentityValidateError
+	Err422IdentityStartError
+	Err422IdentityStateError
+	Err422IdentityProviderError
+	Err422IdentityLinkError
+	Err422IdentityEmailNotVerifiedError
+	Err422IdentityEmailTakenError
+	Err422IdentityUserCreateError
+	Err422IdentityListError
+	Err422IdentityUnlinkError
+	Err422APIKeyValidateError
+	Err422APIKeyAdminScopeError
+	Err422APIKeyCreateError
+	Err422APIKeyListError
+	Err422APIKeyRevokeError
+	Err422ClientValidateError
+	Err422ClientCreateError
+	Err422ClientListError
+	Err422ClientDeactivateError
+	Err422IntrospectValidateError
+	Err422ImpersonationValidateError
+	Err422ImpersonationTargetError
+	Err422ImpersonationStartError
+	Err422ImpersonationStopError
+	Err422ImpersonationListError
+	Err422ImpersonationNotActiveError
+	Err422AuditValidateError
+	Err422AuditListError
+)
+
+const (
+	// 429 Too Many Requests errors.
+	_ = 42900000 + iota
+	Err429MagicLinkRateLimit
 )
@@ -13,6 +13,8 @@ type ErrNo struct {
 	Status int     `json:"status"`
 	Stack  *string `json:"stack,omitempty"`
 	stack  errors.StackTrace
+	// cause is the error a wrapping constructor was given, errors.Is and errors.As still find it.
+	cause error
 }
 
 type HTTPError interface {
@@ -62,6 +64,14 @@ func NewUnauthorizedError(msg string, code int) HTTPError {
 	return NewErrNo(msg, code, http.StatusUnauthorized)
 }
 
+func NewConflictError(msg string, code int) HTTPError {
+	return NewErrNo(msg, code, http.StatusConflict)
+}
+
+func NewTooManyRequestsError(msg string, code int) HTTPError {
+	return NewErrNo(msg, code, http.StatusTooManyRequests)
+}
+
 func NewValidationError(msg string, code int) HTTPError {
 	return NewErrNo(msg, code, http.StatusUnprocessableEntity)
 }
@@ -77,13 +87,22 @@ func NewUnprocessableEntityErrorWrap(msg string, code int, e error) HTTPError {
 	} else {
 		message = fmt.Sprintf("%s: %s", msg, e.Error())
 	}
-	return NewErrNo(message, code, http.StatusUnprocessableEntity)
+	return errors.WithStack(&ErrNo{
+		Msg:    message,
+		Code:   code,
+		Status: http.StatusUnprocessableEntity,
+		cause:  e,
+	})
 }
 
 func (e ErrNo) Error() string {
 	return e.Msg
 }
 
+func (e ErrNo) Unwrap() error {
+	return e.cause
+}
+
 func GetErrTrace(err error) *string {
 	var er StackTracer
 	if errors.As(err, &er) {
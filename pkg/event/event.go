@@ -0,0 +1,63 @@
+package event
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/dbunt1tled/fiber-go-api/pkg/log"
+	"github.com/google/uuid"
+)
+
+type Event struct {
+	ID         uuid.UUID `json:"id"`
+	Name       string    `json:"event"`
+	OccurredAt time.Time `json:"occurredAt"`
+	Payload    any       `json:"data"`
+}
+
+type Listener func(ctx context.Context, e Event) error
+
+type Publisher interface {
+	Publish(ctx context.Context, name string, payload any)
+}
+
+// Bus dispatches domain events to in-process listeners. Listener errors are
+// logged and never reach the publisher, so emitting an event can't fail a request.
+type Bus struct {
+	mu        sync.RWMutex
+	listeners []Listener
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+func (b *Bus) Subscribe(listener Listener) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.listeners = append(b.listeners, listener)
+}
+
+func (b *Bus) Publish(ctx context.Context, name string, payload any) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		id = uuid.New()
+	}
+	e := Event{
+		ID:         id,
+		Name:       name,
+		OccurredAt: time.Now().UTC(),
+		Payload:    payload,
+	}
+
+	b.mu.RLock()
+	listeners := b.listeners
+	b.mu.RUnlock()
+	for _, listener := range listeners {
+		if err = listener(ctx, e); err != nil {
+			log.Logger().ErrorContext(ctx, "Event listener failed", err, slog.String("event", name))
+		}
+	}
+}
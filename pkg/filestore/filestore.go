@@ -0,0 +1,40 @@
+package filestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+var ErrNotFound = errors.New("filestore: object not found")
+
+// Driver stores objects under slash separated keys.
+type Driver interface {
+	Name() string
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+type Options struct {
+	Driver string
+	Dir    string
+	S3     S3Options
+}
+
+func New(opts Options) (Driver, error) {
+	switch opts.Driver {
+	case DriverS3:
+		return NewS3Driver(opts.S3)
+	case DriverLocal, "":
+		return NewLocalDriver(opts.Dir)
+	default:
+		return nil, fmt.Errorf("filestore: unknown driver %q", opts.Driver)
+	}
+}
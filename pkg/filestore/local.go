@@ -0,0 +1,86 @@
+package filestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type LocalDriver struct {
+	root string
+}
+
+func NewLocalDriver(root string) (*LocalDriver, error) {
+	if err := os.MkdirAll(root, 0o750); err != nil { //nolint:mnd // owner and group only
+		return nil, fmt.Errorf("filestore: create %s: %w", root, err)
+	}
+	return &LocalDriver{root: root}, nil
+}
+
+func (d *LocalDriver) Name() string {
+	return DriverLocal
+}
+
+// Put writes to a temporary file first so readers never see a partial object.
+func (d *LocalDriver) Put(_ context.Context, key string, r io.Reader, _ int64, _ string) error {
+	path, err := d.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o750); err != nil { //nolint:mnd // owner and group only
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = io.Copy(tmp, r); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (d *LocalDriver) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	path, err := d.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return file, err
+}
+
+func (d *LocalDriver) Delete(_ context.Context, key string) error {
+	path, err := d.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path resolves the key inside the root, rejecting keys that escape it.
+func (d *LocalDriver) path(key string) (string, error) {
+	path := filepath.Join(d.root, filepath.FromSlash(key))
+	rel, err := filepath.Rel(d.root, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("filestore: invalid key %q", key)
+	}
+	return path, nil
+}
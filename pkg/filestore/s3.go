@@ -0,0 +1,73 @@
+package filestore
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+type S3Options struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+	PathStyle bool
+}
+
+// S3Driver works with AWS S3 and any S3 compatible service such as MinIO.
+type S3Driver struct {
+	client *minio.Client
+	bucket string
+}
+
+func NewS3Driver(opts S3Options) (*S3Driver, error) {
+	lookup := minio.BucketLookupAuto
+	if opts.PathStyle {
+		lookup = minio.BucketLookupPath
+	}
+	client, err := minio.New(opts.Endpoint, &minio.Options{
+		Creds:        credentials.NewStaticV4(opts.AccessKey, opts.SecretKey, ""),
+		Secure:       opts.UseSSL,
+		Region:       opts.Region,
+		BucketLookup: lookup,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("filestore: s3 client: %w", err)
+	}
+
+	return &S3Driver{client: client, bucket: opts.Bucket}, nil
+}
+
+func (d *S3Driver) Name() string {
+	return DriverS3
+}
+
+func (d *S3Driver) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	_, err := d.client.PutObject(ctx, d.bucket, key, r, size, minio.PutObjectOptions{ContentType: contentType})
+	return err
+}
+
+func (d *S3Driver) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	obj, err := d.client.GetObject(ctx, d.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	// GetObject is lazy, Stat surfaces a missing key before the response starts
+	if _, err = obj.Stat(); err != nil {
+		_ = obj.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return obj, nil
+}
+
+func (d *S3Driver) Delete(ctx context.Context, key string) error {
+	return d.client.RemoveObject(ctx, d.bucket, key, minio.RemoveObjectOptions{})
+}
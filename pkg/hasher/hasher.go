@@ -1,52 +1,98 @@
 package hasher
 
 import (
-	"crypto/ecdsa"
+	"crypto/hmac"
 	"crypto/rand"
-	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/subtle"
 	"encoding/base64"
 	"encoding/binary"
+	"encoding/hex"
 	"fmt"
 	"strings"
 	"time"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/jxskiss/base62"
 	"github.com/pkg/errors"
 	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
 )
 
 var (
 	ErrInvalidHash         = errors.New("the encoded hash is not in the correct format")
 	ErrIncompatibleVersion = errors.New("incompatible version of argon2")
+	ErrUnknownPepper       = errors.New("the hash was made with another pepper")
 )
 
+// ArgonConfig holds the argon2id parameters of new hashes.
 type ArgonConfig struct {
-	memory      uint32
-	iterations  uint32
-	parallelism uint8
-	saltLength  uint32
-	keyLength   uint32
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
 }
 
-type JWTManager struct {
-	algorithm     string
-	publicKey     interface{}
-	privateKey    interface{}
-	signingMethod jwt.SigningMethod
+func DefaultArgonConfig() ArgonConfig {
+	return ArgonConfig{
+		Memory:      64 * 1024, //nolint:mnd // based on argon defaults
+		Iterations:  3,         //nolint:mnd // based on argon defaults
+		Parallelism: 2,         //nolint:mnd // based on argon defaults
+		SaltLength:  16,        //nolint:mnd // based on argon defaults
+		KeyLength:   32,        //nolint:mnd // based on argon defaults
+	}
 }
 
 type Hasher struct {
-	jwtManager *JWTManager
-	argon      *ArgonConfig
+	keys  *KeySet
+	codec tokenCodec
+	argon *ArgonConfig
+	// pepper is mixed into argon2 hashes, which name it by pepperID.
+	pepper   []byte
+	pepperID string
+}
+
+type Option func(*Hasher)
+
+// WithArgon sets the parameters of new hashes, zero fields keep the defaults.
+func WithArgon(a ArgonConfig) Option {
+	return func(h *Hasher) {
+		if a.Memory > 0 {
+			h.argon.Memory = a.Memory
+		}
+		if a.Iterations > 0 {
+			h.argon.Iterations = a.Iterations
+		}
+		if a.Parallelism > 0 {
+			h.argon.Parallelism = a.Parallelism
+		}
+		if a.SaltLength > 0 {
+			h.argon.SaltLength = a.SaltLength
+		}
+		if a.KeyLength > 0 {
+			h.argon.KeyLength = a.KeyLength
+		}
+	}
+}
+
+// WithPepper keys argon2 hashes with a secret kept out of the database.
+func WithPepper(pepper string) Option {
+	return func(h *Hasher) {
+		if pepper == "" {
+			return
+		}
+		sum := sha256.Sum256([]byte(pepper))
+		h.pepper = []byte(pepper)
+		h.pepperID = base64.RawStdEncoding.EncodeToString(sum[:6]) //nolint:mnd // short identifier
+	}
 }
 
 type DecodeOpt func(*decopts)
 
 type decopts struct {
-	subject *string
-	expire  bool
+	subject  *string
+	audience *string
+	expire   bool
 }
 
 func defaultDecOpts() *decopts {
@@ -62,117 +108,199 @@ func WithSubject(subject string) DecodeOpt {
 	}
 }
 
+// WithAudience requires the aud claim, a string or a list, to contain the audience.
+func WithAudience(audience string) DecodeOpt {
+	return func(o *decopts) {
+		o.audience = &audience
+	}
+}
+
 func WithExpire(expire bool) DecodeOpt {
 	return func(o *decopts) {
 		o.expire = expire
 	}
 }
 
-func NewHasher(jwtAlgorithm string, jwtPublicKey string, jwtPrivateKey string) (*Hasher, error) {
-	jm, err := getJWTManager(jwtAlgorithm, jwtPublicKey, jwtPrivateKey)
-	if err != nil {
-		return nil, err
+func NewHasher(keys *KeySet, opts ...Option) (*Hasher, error) {
+	if keys == nil || keys.Active() == nil {
+		return nil, errors.New("hasher needs a key set with an active key")
+	}
+	argon := DefaultArgonConfig()
+	h := &Hasher{
+		keys:  keys,
+		codec: newTokenCodec(keys),
+		argon: &argon,
 	}
-	return &Hasher{
-		jwtManager: jm,
-		argon: &ArgonConfig{
-			memory:      64 * 1024, //nolint:mnd // based on argon defaults
-			iterations:  3,         //nolint:mnd // based on argon defaults
-			parallelism: 2,         //nolint:mnd // based on argon defaults
-			saltLength:  16,        //nolint:mnd // based on argon defaults
-			keyLength:   32,        //nolint:mnd // based on argon defaults
-		},
-	}, nil
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h, nil
 }
 
 func (h *Hasher) HashArgon(password string) (string, error) {
-	salt, err := h.RandomBytes(16) //nolint:mnd //standard salt size
+	salt, err := h.RandomBytes(uint(h.argon.SaltLength))
 	if err != nil {
 		return "", err
 	}
 
 	argonHash := argon2.IDKey(
-		[]byte(password),
+		h.peppered(password),
 		salt,
-		h.argon.iterations,
-		h.argon.memory,
-		h.argon.parallelism,
-		h.argon.keyLength,
+		h.argon.Iterations,
+		h.argon.Memory,
+		h.argon.Parallelism,
+		h.argon.KeyLength,
 	)
+	params := fmt.Sprintf("m=%d,t=%d,p=%d", h.argon.Memory, h.argon.Iterations, h.argon.Parallelism)
+	if h.pepperID != "" {
+		params += ",keyid=" + h.pepperID
+	}
 	b64Salt := base64.RawStdEncoding.EncodeToString(salt)
 	b64Hash := base64.RawStdEncoding.EncodeToString(argonHash)
 	encodedHash := fmt.Sprintf(
-		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		"$argon2id$v=%d$%s$%s$%s",
 		argon2.Version,
-		h.argon.memory,
-		h.argon.iterations,
-		h.argon.parallelism,
+		params,
 		b64Salt,
 		b64Hash,
 	)
 	return encodedHash, nil
 }
 
+// ComparePassword checks argon2id hashes and the bcrypt hashes of accounts imported from older systems.
+func (h *Hasher) ComparePassword(password string, encodedHash string) (bool, error) {
+	if !isBcrypt(encodedHash) {
+		return h.CompareArgon(password, encodedHash)
+	}
+	err := bcrypt.CompareHashAndPassword([]byte(encodedHash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
 func (h *Hasher) CompareArgon(password string, encodedHash string) (bool, error) {
-	a, salt, hash, err := h.decodeHashArgon(encodedHash)
+	a, err := h.decodeHashArgon(encodedHash)
 	if err != nil {
 		return false, err
 	}
+	input := []byte(password)
+	if a.keyID != "" {
+		if a.keyID != h.pepperID {
+			return false, ErrUnknownPepper
+		}
+		input = h.peppered(password)
+	}
 
 	otherHash := argon2.IDKey(
-		[]byte(password),
-		salt,
-		a.iterations,
-		a.memory,
-		a.parallelism,
-		a.keyLength,
+		input,
+		a.salt,
+		a.Iterations,
+		a.Memory,
+		a.Parallelism,
+		a.KeyLength,
 	)
-	if subtle.ConstantTimeCompare(hash, otherHash) == 1 {
+	if subtle.ConstantTimeCompare(a.hash, otherHash) == 1 {
 		return true, nil
 	}
 	return false, nil
 }
 
-func (h *Hasher) decodeHashArgon(encodedHash string) (*ArgonConfig, []byte, []byte, error) {
-	var (
-		a    *ArgonConfig
-		err  error
-		salt []byte
-		hash []byte
-	)
+// NeedsRehash reports whether a hash was made with other parameters, another pepper or bcrypt,
+// so it can be replaced once the password is known.
+func (h *Hasher) NeedsRehash(encodedHash string) bool {
+	a, err := h.decodeHashArgon(encodedHash)
+	if err != nil {
+		return true
+	}
+	return a.ArgonConfig != *h.argon || a.keyID != h.pepperID
+}
+
+func (h *Hasher) peppered(password string) []byte {
+	if h.pepper == nil {
+		return []byte(password)
+	}
+	mac := hmac.New(sha256.New, h.pepper)
+	mac.Write([]byte(password))
+	return mac.Sum(nil)
+}
+
+type argonHash struct {
+	ArgonConfig
+	keyID string
+	salt  []byte
+	hash  []byte
+}
+
+func (h *Hasher) decodeHashArgon(encodedHash string) (*argonHash, error) {
+	var err error
 	vals := strings.Split(encodedHash, "$")
-	if len(vals) != 6 { //nolint:mnd // number argon parameters
-		return nil, nil, nil, ErrInvalidHash
+	if len(vals) != 6 || vals[1] != "argon2id" { //nolint:mnd // number argon parameters
+		return nil, ErrInvalidHash
 	}
 
 	var version int
 	_, err = fmt.Sscanf(vals[2], "v=%d", &version)
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, err
 	}
 	if version != argon2.Version {
-		return nil, nil, nil, ErrIncompatibleVersion
+		return nil, ErrIncompatibleVersion
 	}
 
-	a = &ArgonConfig{}
-	_, err = fmt.Sscanf(vals[3], "m=%d,t=%d,p=%d", &a.memory, &a.iterations, &a.parallelism)
-	if err != nil {
-		return nil, nil, nil, err
+	a := &argonHash{}
+	for _, param := range strings.Split(vals[3], ",") {
+		name, value, _ := strings.Cut(param, "=")
+		switch name {
+		case "m":
+			_, err = fmt.Sscanf(value, "%d", &a.Memory)
+		case "t":
+			_, err = fmt.Sscanf(value, "%d", &a.Iterations)
+		case "p":
+			_, err = fmt.Sscanf(value, "%d", &a.Parallelism)
+		case "keyid":
+			a.keyID = value
+		default:
+			err = ErrInvalidHash
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if a.Memory == 0 || a.Iterations == 0 || a.Parallelism == 0 {
+		return nil, ErrInvalidHash
 	}
 
-	salt, err = base64.RawStdEncoding.Strict().DecodeString(vals[4])
+	a.salt, err = base64.RawStdEncoding.Strict().DecodeString(vals[4])
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, err
 	}
-	a.saltLength = uint32(len(salt)) //nolint:gosec // salt length is fixed
+	a.SaltLength = uint32(len(a.salt)) //nolint:gosec // salt length is fixed
 
-	hash, err = base64.RawStdEncoding.Strict().DecodeString(vals[5])
+	a.hash, err = base64.RawStdEncoding.Strict().DecodeString(vals[5])
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, err
 	}
-	a.keyLength = uint32(len(hash)) //nolint:gosec // hash length is fixed
+	a.KeyLength = uint32(len(a.hash)) //nolint:gosec // hash length is fixed
+
+	return a, nil
+}
 
-	return a, salt, hash, nil
+func isBcrypt(encodedHash string) bool {
+	return strings.HasPrefix(encodedHash, "$2a$") ||
+		strings.HasPrefix(encodedHash, "$2b$") ||
+		strings.HasPrefix(encodedHash, "$2y$")
+}
+
+// HashToken hashes high-entropy secrets such as API keys. They can't be guessed,
+// so unlike passwords they need no salt or slow hashing and can be looked up on every request.
+func (h *Hasher) HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func (h *Hasher) CompareToken(token string, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(h.HashToken(token)), []byte(hash)) == 1
 }
 
 func (h *Hasher) RandomBytes(n uint) ([]byte, error) {
@@ -231,42 +359,30 @@ func (h *Hasher) NewHexID() (string, error) {
 
 // Encode time.Now().Add(time.Second * time.Duration(exp)).Unix().
 func (h *Hasher) EncodeJWT(payload map[string]interface{}) (string, error) {
-	claims := jwt.MapClaims(payload)
-	token := jwt.NewWithClaims(h.jwtManager.signingMethod, claims)
-	privateKey := h.jwtManager.privateKey
-	return token.SignedString(privateKey)
+	return h.codec.encode(payload)
 }
 
-func (h *Hasher) DecodeJWT(token string, opts ...DecodeOpt) (map[string]interface{}, error) {
-	var (
-		claims jwt.MapClaims
-		sub    string
-		err    error
-	)
+func (h *Hasher) JWKS() JWKS {
+	return h.keys.JWKS()
+}
 
+func (h *Hasher) DecodeJWT(token string, opts ...DecodeOpt) (map[string]interface{}, error) {
 	o := defaultDecOpts()
 	for _, opt := range opts {
 		opt(o)
 	}
 
-	tokenData, err := jwt.ParseWithClaims(token, &claims, func(t *jwt.Token) (interface{}, error) {
-		if t.Method != h.jwtManager.signingMethod {
-			return nil, errors.New("invalid token algorithm")
-		}
-		return h.jwtManager.publicKey, nil
-	})
-
-	if tokenData == nil || !tokenData.Valid || err != nil {
-		if errors.Is(err, jwt.ErrTokenExpired) && !o.expire {
+	claims, err := h.codec.decode(token)
+	if err != nil {
+		if errors.Is(err, errTokenExpired) && !o.expire {
 			return claims, nil
 		}
 		return nil, errors.New("invalid token")
 	}
 
 	if o.subject != nil {
-		sub, err = tokenData.Claims.GetSubject()
-
-		if err != nil {
+		sub, ok := claims["sub"].(string)
+		if !ok {
 			return nil, errors.New("invalid token subject error")
 		}
 
@@ -275,104 +391,29 @@ func (h *Hasher) DecodeJWT(token string, opts ...DecodeOpt) (map[string]interfac
 		}
 	}
 
+	if o.audience != nil && !hasAudience(claims["aud"], *o.audience) {
+		return nil, errors.New("invalid token audience")
+	}
+
 	return claims, nil
 }
 
-func getJWTManager(jwtAlgorithm string, jwtPublicKey string, jwtPrivateKey string) (*JWTManager, error) {
-	var err error
-	jm := &JWTManager{
-		algorithm: jwtAlgorithm,
-	}
-	switch jwtAlgorithm {
-	case "RS512":
-		jm.signingMethod = jwt.SigningMethodRS512
-		jm.publicKey, jm.privateKey, err = loadRSAKeys(jwtPublicKey, jwtPrivateKey)
-		if err != nil {
-			return nil, err
-		}
-	case "RS256":
-		jm.signingMethod = jwt.SigningMethodRS256
-		jm.publicKey, jm.privateKey, err = loadRSAKeys(jwtPublicKey, jwtPrivateKey)
-		if err != nil {
-			return nil, err
-		}
-	case "ES512":
-		jm.signingMethod = jwt.SigningMethodES512
-		jm.publicKey, jm.privateKey, err = loadECKeys(jwtPublicKey, jwtPrivateKey)
-		if err != nil {
-			return nil, err
+func hasAudience(claim any, audience string) bool {
+	switch aud := claim.(type) {
+	case string:
+		return aud == audience
+	case []any:
+		for _, item := range aud {
+			if item == audience {
+				return true
+			}
 		}
-	case "ES256":
-		jm.signingMethod = jwt.SigningMethodES256
-		jm.publicKey, jm.privateKey, err = loadECKeys(jwtPublicKey, jwtPrivateKey)
-		if err != nil {
-			return nil, err
+	case []string:
+		for _, item := range aud {
+			if item == audience {
+				return true
+			}
 		}
-
-	default:
-		return nil, errors.New("invalid algorithm")
 	}
-	return jm, nil
-}
-
-func loadRSAKeys(jwtPublicKey string, jwtPrivateKey string) (*rsa.PublicKey, *rsa.PrivateKey, error) {
-	var (
-		privateKeyBytes []byte
-		publicKeyBytes  []byte
-		publicKey       *rsa.PublicKey
-		privateKey      *rsa.PrivateKey
-		err             error
-	)
-	privateKeyBytes, err = base64.StdEncoding.DecodeString(jwtPrivateKey)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	publicKeyBytes, err = base64.StdEncoding.DecodeString(jwtPublicKey)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	publicKey, err = jwt.ParseRSAPublicKeyFromPEM(publicKeyBytes)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	privateKey, err = jwt.ParseRSAPrivateKeyFromPEM(privateKeyBytes)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	return publicKey, privateKey, nil
-}
-
-func loadECKeys(jwtPublicKey string, jwtPrivateKey string) (*ecdsa.PublicKey, *ecdsa.PrivateKey, error) {
-	var (
-		privateKeyBytes []byte
-		publicKeyBytes  []byte
-		publicKey       *ecdsa.PublicKey
-		privateKey      *ecdsa.PrivateKey
-		err             error
-	)
-	privateKeyBytes, err = base64.StdEncoding.DecodeString(jwtPrivateKey)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	publicKeyBytes, err = base64.StdEncoding.DecodeString(jwtPublicKey)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	publicKey, err = jwt.ParseECPublicKeyFromPEM(publicKeyBytes)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	privateKey, err = jwt.ParseECPrivateKeyFromPEM(privateKeyBytes)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	return publicKey, privateKey, nil
+	return false
 }
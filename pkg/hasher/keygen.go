@@ -0,0 +1,106 @@
+package hasher
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+)
+
+// GeneratedKey holds a new key in the formats the configuration expects:
+// PEM files, their base64 form for the environment, or a shared secret.
+type GeneratedKey struct {
+	Algorithm  string
+	KeyID      string
+	PrivatePEM []byte
+	PublicPEM  []byte
+	Secret     string
+}
+
+// PrivateKey is the base64 PEM for APP_SERVER_JWT_PRIVATE.
+func (g *GeneratedKey) PrivateKey() string {
+	return base64.StdEncoding.EncodeToString(g.PrivatePEM)
+}
+
+// PublicKey is the base64 PEM for APP_SERVER_JWT_PUBLIC.
+func (g *GeneratedKey) PublicKey() string {
+	return base64.StdEncoding.EncodeToString(g.PublicPEM)
+}
+
+// GenerateKey creates a key for the algorithm, asymmetric keys get their thumbprint as kid.
+func GenerateKey(algorithm string) (*GeneratedKey, error) {
+	switch algorithm {
+	case AlgHS256:
+		return generateSecret(algorithm, 32, false) //nolint:mnd // SHA-256 output size
+	case AlgHS512:
+		return generateSecret(algorithm, 64, false) //nolint:mnd // SHA-512 output size
+	case AlgPasetoLocal:
+		return generateSecret(algorithm, pasetoLocalKeySize, true)
+	}
+
+	var (
+		private crypto.Signer
+		err     error
+	)
+	switch algorithm {
+	case AlgRS256:
+		private, err = rsa.GenerateKey(rand.Reader, 2048) //nolint:mnd // key size
+	case AlgRS512:
+		private, err = rsa.GenerateKey(rand.Reader, 4096) //nolint:mnd // key size
+	case AlgES256:
+		private, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgES384:
+		private, err = ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	case AlgES512:
+		private, err = ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	case AlgEdDSA, AlgPasetoPublic:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return encodeKeyPair(algorithm, private)
+}
+
+func encodeKeyPair(algorithm string, private crypto.Signer) (*GeneratedKey, error) {
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(private.Public())
+	if err != nil {
+		return nil, err
+	}
+	kid, err := Thumbprint(private.Public())
+	if err != nil {
+		return nil, err
+	}
+	return &GeneratedKey{
+		Algorithm:  algorithm,
+		KeyID:      kid,
+		PrivatePEM: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}),
+		PublicPEM:  pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}),
+	}, nil
+}
+
+func generateSecret(algorithm string, size int, hexEncoded bool) (*GeneratedKey, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	// HMAC secrets are used as given, so they are kept printable
+	secret := base64.RawURLEncoding.EncodeToString(b)
+	if hexEncoded {
+		secret = hex.EncodeToString(b)
+	}
+	return &GeneratedKey{Algorithm: algorithm, KeyID: secretKeyID, Secret: secret}, nil
+}
@@ -0,0 +1,471 @@
+package hasher
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+)
+
+const (
+	pemExt       = ".pem"
+	publicPEMExt = ".pub.pem"
+	secretKeyID  = "default"
+)
+
+// JWTKey is one key of the set; keys without a private part only verify.
+// Shared secrets (HMAC, v4.local) are both the Public and the Private part.
+type JWTKey struct {
+	ID       string
+	Method   jwt.SigningMethod
+	Public   crypto.PublicKey
+	Private  any
+	RetireAt *time.Time
+}
+
+func (k *JWTKey) retired(now time.Time) bool {
+	return k.RetireAt != nil && now.After(*k.RetireAt)
+}
+
+type KeySetOptions struct {
+	// Algorithm applies to RSA and HMAC keys, EC keys take the algorithm of their curve.
+	// v4.public and v4.local switch the token format to PASETO.
+	Algorithm string
+	// Secret is the shared key of HS256/HS512 and, hex encoded, of v4.local.
+	Secret string
+	// PublicKey and PrivateKey are a base64 encoded PEM pair, the single key of earlier releases.
+	PublicKey  string
+	PrivateKey string
+	KeyID      string
+	// Dir holds <kid>.pem private keys and <kid>.pub.pem verification only keys.
+	Dir string
+	// Files lists keys as kid:path pairs separated by commas.
+	Files string
+	// Active is the kid of the signing key, the PublicKey/PrivateKey pair by default.
+	Active string
+	// Retire lists kid:RFC3339 pairs, after which tokens of the key are rejected.
+	Retire string
+}
+
+// KeySet signs with one active key and verifies with every key that isn't retired,
+// so rotating keys leaves the tokens issued with the previous key valid for an overlap window.
+type KeySet struct {
+	algorithm string
+	keys      map[string]*JWTKey
+	active    *JWTKey
+	// legacy verifies tokens issued before kid headers were added.
+	legacy *JWTKey
+}
+
+func NewKeySet(opts KeySetOptions) (*KeySet, error) {
+	ks := &KeySet{algorithm: opts.Algorithm, keys: make(map[string]*JWTKey)}
+
+	if opts.Secret != "" && (opts.PrivateKey != "" || opts.PublicKey != "") {
+		return nil, errors.New("jwt: configure either a secret or a key pair")
+	}
+	if opts.PrivateKey != "" || opts.PublicKey != "" || opts.Secret != "" {
+		key, err := legacyKey(opts)
+		if err != nil {
+			return nil, err
+		}
+		ks.keys[key.ID] = key
+		ks.legacy = key
+	}
+	if opts.Dir != "" {
+		if err := ks.loadDir(opts.Dir, opts.Algorithm); err != nil {
+			return nil, err
+		}
+	}
+	for _, item := range splitList(opts.Files) {
+		kid, path, ok := strings.Cut(item, ":")
+		if !ok || kid == "" || path == "" {
+			return nil, fmt.Errorf("invalid jwt key file %q, expected kid:path", item)
+		}
+		if err := ks.loadFile(kid, path, opts.Algorithm); err != nil {
+			return nil, err
+		}
+	}
+	if err := ks.retire(opts.Retire); err != nil {
+		return nil, err
+	}
+
+	return ks, ks.activate(opts.Active)
+}
+
+func (ks *KeySet) Active() *JWTKey {
+	return ks.active
+}
+
+func (ks *KeySet) Algorithm() string {
+	return ks.algorithm
+}
+
+// Key returns the verification key of the kid, an empty kid selecting the key of earlier releases.
+func (ks *KeySet) Key(kid string) (*JWTKey, bool) {
+	key := ks.legacy
+	if kid != "" {
+		key = ks.keys[kid]
+	}
+	if key == nil || key.retired(time.Now()) {
+		return nil, false
+	}
+	return key, true
+}
+
+// Keys returns the keys that still verify, sorted by kid.
+func (ks *KeySet) Keys() []*JWTKey {
+	keys := make([]*JWTKey, 0, len(ks.keys))
+	for _, key := range ks.keys {
+		if !key.retired(time.Now()) {
+			keys = append(keys, key)
+		}
+	}
+	slices.SortFunc(keys, func(a, b *JWTKey) int { return strings.Compare(a.ID, b.ID) })
+	return keys
+}
+
+func (ks *KeySet) activate(kid string) error {
+	if kid == "" && ks.legacy != nil {
+		kid = ks.legacy.ID
+	}
+	if kid == "" && len(ks.keys) == 1 {
+		for id := range ks.keys {
+			kid = id
+		}
+	}
+	key, ok := ks.keys[kid]
+	switch {
+	case kid == "":
+		return errors.New("jwt: no active signing key configured")
+	case !ok:
+		return fmt.Errorf("jwt: active key %q not found", kid)
+	case key.Private == nil:
+		return fmt.Errorf("jwt: active key %q has no private key", kid)
+	case key.retired(time.Now()):
+		return fmt.Errorf("jwt: active key %q is retired", kid)
+	case !signs(key, ks.algorithm):
+		return fmt.Errorf("jwt: active key %q can't sign %s tokens", kid, ks.algorithm)
+	}
+	ks.active = key
+	return nil
+}
+
+func (ks *KeySet) loadDir(dir string, algorithm string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("jwt: read key dir: %w", err)
+	}
+	// private keys first, so a public file next to its private key is skipped
+	slices.SortFunc(entries, func(a, b os.DirEntry) int {
+		return compareBool(strings.HasSuffix(a.Name(), publicPEMExt), strings.HasSuffix(b.Name(), publicPEMExt))
+	})
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, pemExt) {
+			continue
+		}
+		kid := strings.TrimSuffix(strings.TrimSuffix(name, publicPEMExt), pemExt)
+		if _, ok := ks.keys[kid]; ok {
+			continue
+		}
+		if err = ks.loadFile(kid, filepath.Join(dir, name), algorithm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ks *KeySet) loadFile(kid string, path string, algorithm string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("jwt: read key %s: %w", kid, err)
+	}
+	key, err := newJWTKey(kid, data, algorithm)
+	if err != nil {
+		return err
+	}
+	if _, ok := ks.keys[kid]; ok {
+		return fmt.Errorf("jwt: duplicate key %q", kid)
+	}
+	ks.keys[kid] = key
+	return nil
+}
+
+func (ks *KeySet) retire(list string) error {
+	for _, item := range splitList(list) {
+		kid, at, ok := strings.Cut(item, ":")
+		if !ok {
+			return fmt.Errorf("invalid jwt key retirement %q, expected kid:RFC3339", item)
+		}
+		key, found := ks.keys[kid]
+		if !found {
+			return fmt.Errorf("jwt: retired key %q not found", kid)
+		}
+		t, err := time.Parse(time.RFC3339, at)
+		if err != nil {
+			return fmt.Errorf("jwt: retirement of %q: %w", kid, err)
+		}
+		key.RetireAt = &t
+	}
+	return nil
+}
+
+// signs reports whether the key fits the token format of the algorithm.
+func signs(key *JWTKey, algorithm string) bool {
+	switch algorithm {
+	case AlgPasetoPublic:
+		_, ok := key.Private.(ed25519.PrivateKey)
+		return ok
+	case AlgPasetoLocal:
+		secret, ok := key.Private.([]byte)
+		return ok && len(secret) == pasetoLocalKeySize
+	default:
+		return key.Method != nil
+	}
+}
+
+func legacyKey(opts KeySetOptions) (*JWTKey, error) {
+	if opts.Secret != "" {
+		return secretKey(opts)
+	}
+	source := opts.PrivateKey
+	if source == "" {
+		source = opts.PublicKey
+	}
+	data, err := base64.StdEncoding.DecodeString(source)
+	if err != nil {
+		return nil, err
+	}
+	key, err := newJWTKey(opts.KeyID, data, opts.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	if alg := methodAlgorithm(opts.Algorithm); alg != "" && key.Method.Alg() != alg {
+		return nil, fmt.Errorf("jwt: %s key does not match algorithm %s", key.Method.Alg(), opts.Algorithm)
+	}
+	if key.ID == "" {
+		if key.ID, err = Thumbprint(key.Public); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+func secretKey(opts KeySetOptions) (*JWTKey, error) {
+	kid := opts.KeyID
+	if kid == "" {
+		kid = secretKeyID
+	}
+	if opts.Algorithm == AlgPasetoLocal {
+		secret, err := hex.DecodeString(opts.Secret)
+		if err != nil || len(secret) != pasetoLocalKeySize {
+			return nil, errors.New("jwt: v4.local secret must be 32 hex encoded bytes")
+		}
+		return &JWTKey{ID: kid, Public: secret, Private: secret}, nil
+	}
+
+	method, ok := jwt.GetSigningMethod(opts.Algorithm).(*jwt.SigningMethodHMAC)
+	if !ok {
+		return nil, fmt.Errorf("jwt: secret is not supported by algorithm %s", opts.Algorithm)
+	}
+	// RFC 7518: the key is at least as long as the hash output
+	if len(opts.Secret) < method.Hash.Size() {
+		return nil, fmt.Errorf("jwt: %s secret must be at least %d bytes", method.Alg(), method.Hash.Size())
+	}
+	secret := []byte(opts.Secret)
+	return &JWTKey{ID: kid, Method: method, Public: secret, Private: secret}, nil
+}
+
+// methodAlgorithm is the JWT algorithm that keys of a token format carry.
+func methodAlgorithm(algorithm string) string {
+	if algorithm == AlgPasetoPublic {
+		return AlgEdDSA
+	}
+	return algorithm
+}
+
+func newJWTKey(kid string, data []byte, algorithm string) (*JWTKey, error) {
+	private, public, err := parsePEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("jwt: key %s: %w", kid, err)
+	}
+	method, err := signingMethod(public, algorithm)
+	if err != nil {
+		return nil, fmt.Errorf("jwt: key %s: %w", kid, err)
+	}
+	return &JWTKey{ID: kid, Method: method, Public: public, Private: private}, nil
+}
+
+func parsePEM(data []byte) (crypto.Signer, crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, nil, errors.New("no PEM data")
+	}
+	if pub, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		return nil, pub, nil
+	}
+	if pub, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return nil, pub, nil
+	}
+
+	var private any
+	private, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		if private, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+			if private, err = x509.ParseECPrivateKey(block.Bytes); err != nil {
+				return nil, nil, errors.New("unsupported PEM key")
+			}
+		}
+	}
+	signer, ok := private.(crypto.Signer)
+	if !ok {
+		return nil, nil, errors.New("unsupported private key")
+	}
+	return signer, signer.Public(), nil
+}
+
+func signingMethod(public crypto.PublicKey, algorithm string) (jwt.SigningMethod, error) {
+	switch pub := public.(type) {
+	case *rsa.PublicKey:
+		if strings.HasPrefix(algorithm, "RS") {
+			if method := jwt.GetSigningMethod(algorithm); method != nil {
+				return method, nil
+			}
+		}
+		return jwt.SigningMethodRS256, nil
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	case *ecdsa.PublicKey:
+		switch pub.Curve {
+		case elliptic.P256():
+			return jwt.SigningMethodES256, nil
+		case elliptic.P384():
+			return jwt.SigningMethodES384, nil
+		case elliptic.P521():
+			return jwt.SigningMethodES512, nil
+		}
+	}
+	return nil, errors.New("unsupported key type")
+}
+
+// JWK is the public part of a key as published in the JWKS document.
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Kid string `json:"kid,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+func (ks *KeySet) JWKS() JWKS {
+	keys := ks.Keys()
+	set := JWKS{Keys: make([]JWK, 0, len(keys))}
+	for _, key := range keys {
+		jwk, err := publicJWK(key.Public)
+		if err != nil {
+			continue
+		}
+		jwk.Use = "sig"
+		jwk.Alg = key.Method.Alg()
+		jwk.Kid = key.ID
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// Thumbprint is the RFC 7638 JWK thumbprint, the default kid of a key.
+func Thumbprint(public crypto.PublicKey) (string, error) {
+	jwk, err := publicJWK(public)
+	if err != nil {
+		return "", err
+	}
+	// members in lexicographic order, as the RFC requires
+	var canonical string
+	switch jwk.Kty {
+	case "RSA":
+		canonical = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, jwk.E, jwk.N)
+	case "OKP":
+		canonical = fmt.Sprintf(`{"crv":%q,"kty":"OKP","x":%q}`, jwk.Crv, jwk.X)
+	default:
+		canonical = fmt.Sprintf(`{"crv":%q,"kty":%q,"x":%q,"y":%q}`, jwk.Crv, jwk.Kty, jwk.X, jwk.Y)
+	}
+	sum := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+func publicJWK(public crypto.PublicKey) (JWK, error) {
+	switch pub := public.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}, nil
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(pub),
+		}, nil
+	case *ecdsa.PublicKey:
+		ecdh, err := pub.ECDH()
+		if err != nil {
+			return JWK{}, err
+		}
+		// uncompressed point: 0x04 || x || y
+		point := ecdh.Bytes()[1:]
+		size := len(point) / 2 //nolint:mnd // x and y halves
+		return JWK{
+			Kty: "EC",
+			Crv: pub.Curve.Params().Name,
+			X:   base64.RawURLEncoding.EncodeToString(point[:size]),
+			Y:   base64.RawURLEncoding.EncodeToString(point[size:]),
+		}, nil
+	}
+	return JWK{}, errors.New("unsupported key type")
+}
+
+func splitList(list string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	default:
+		return -1
+	}
+}
@@ -0,0 +1,299 @@
+package hasher
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/chacha20"
+)
+
+// PASETO v4 (https://github.com/paseto-standard/paseto-spec): v4.public signs
+// with Ed25519, v4.local encrypts with XChaCha20 and authenticates with BLAKE2b.
+const (
+	pasetoPublicHeader = "v4.public."
+	pasetoLocalHeader  = "v4.local."
+	pasetoNonceSize    = 32
+	pasetoMACSize      = 32
+	pasetoEncKeySize   = 56
+	pasetoLocalKeySize = 32
+)
+
+// pasetoTimeClaims are unix timestamps in our payloads and RFC 3339 strings in PASETO.
+var pasetoTimeClaims = []string{"exp", "iat", "nbf"}
+
+type pasetoFooter struct {
+	KeyID string `json:"kid"`
+}
+
+type pasetoPublicCodec struct {
+	keys *KeySet
+}
+
+func (p *pasetoPublicCodec) encode(claims map[string]interface{}) (string, error) {
+	key := p.keys.Active()
+	private, ok := key.Private.(ed25519.PrivateKey)
+	if !ok {
+		return "", errors.New("v4.public needs an Ed25519 signing key")
+	}
+	message, footer, err := pasetoMessage(claims, key.ID)
+	if err != nil {
+		return "", err
+	}
+
+	return pasetoSign(private, message, footer), nil
+}
+
+func (p *pasetoPublicCodec) decode(token string) (map[string]interface{}, error) {
+	body, footer, err := pasetoParts(token, pasetoPublicHeader)
+	if err != nil {
+		return nil, err
+	}
+	if len(body) < ed25519.SignatureSize {
+		return nil, errInvalidToken
+	}
+	key, ok := p.keys.Key(pasetoKeyID(footer))
+	if !ok {
+		return nil, errInvalidToken
+	}
+	public, ok := key.Public.(ed25519.PublicKey)
+	if !ok {
+		return nil, errInvalidToken
+	}
+	message, signature := body[:len(body)-ed25519.SignatureSize], body[len(body)-ed25519.SignatureSize:]
+	if !ed25519.Verify(public, pae([]byte(pasetoPublicHeader), message, footer, nil), signature) {
+		return nil, errInvalidToken
+	}
+
+	return pasetoClaims(message)
+}
+
+type pasetoLocalCodec struct {
+	keys *KeySet
+}
+
+func (p *pasetoLocalCodec) encode(claims map[string]interface{}) (string, error) {
+	key := p.keys.Active()
+	secret, ok := key.Private.([]byte)
+	if !ok || len(secret) != pasetoLocalKeySize {
+		return "", errors.New("v4.local needs a 32 byte symmetric key")
+	}
+	message, footer, err := pasetoMessage(claims, key.ID)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, pasetoNonceSize)
+	if _, err = rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	return pasetoEncrypt(secret, nonce, message, footer)
+}
+
+func (p *pasetoLocalCodec) decode(token string) (map[string]interface{}, error) {
+	body, footer, err := pasetoParts(token, pasetoLocalHeader)
+	if err != nil {
+		return nil, err
+	}
+	if len(body) < pasetoNonceSize+pasetoMACSize {
+		return nil, errInvalidToken
+	}
+	key, ok := p.keys.Key(pasetoKeyID(footer))
+	if !ok {
+		return nil, errInvalidToken
+	}
+	secret, ok := key.Private.([]byte)
+	if !ok || len(secret) != pasetoLocalKeySize {
+		return nil, errInvalidToken
+	}
+	nonce := body[:pasetoNonceSize]
+	ciphertext := body[pasetoNonceSize : len(body)-pasetoMACSize]
+	mac := body[len(body)-pasetoMACSize:]
+
+	encKey, encNonce, authKey, err := pasetoLocalKeys(secret, nonce)
+	if err != nil {
+		return nil, err
+	}
+	expected, err := pasetoMAC(authKey, nonce, ciphertext, footer)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(mac, expected) != 1 {
+		return nil, errInvalidToken
+	}
+	cipher, err := chacha20.NewUnauthenticatedCipher(encKey, encNonce)
+	if err != nil {
+		return nil, err
+	}
+	message := make([]byte, len(ciphertext))
+	cipher.XORKeyStream(message, ciphertext)
+
+	return pasetoClaims(message)
+}
+
+// pasetoSign builds a v4.public token.
+func pasetoSign(private ed25519.PrivateKey, message []byte, footer []byte) string {
+	signature := ed25519.Sign(private, pae([]byte(pasetoPublicHeader), message, footer, nil))
+	return pasetoToken(pasetoPublicHeader, append(message, signature...), footer)
+}
+
+// pasetoEncrypt builds a v4.local token, the nonce must be random outside of test vectors.
+func pasetoEncrypt(secret []byte, nonce []byte, message []byte, footer []byte) (string, error) {
+	encKey, encNonce, authKey, err := pasetoLocalKeys(secret, nonce)
+	if err != nil {
+		return "", err
+	}
+	cipher, err := chacha20.NewUnauthenticatedCipher(encKey, encNonce)
+	if err != nil {
+		return "", err
+	}
+	ciphertext := make([]byte, len(message))
+	cipher.XORKeyStream(ciphertext, message)
+
+	mac, err := pasetoMAC(authKey, nonce, ciphertext, footer)
+	if err != nil {
+		return "", err
+	}
+	body := append(append(append([]byte{}, nonce...), ciphertext...), mac...)
+
+	return pasetoToken(pasetoLocalHeader, body, footer), nil
+}
+
+func pasetoLocalKeys(secret []byte, nonce []byte) ([]byte, []byte, []byte, error) {
+	enc, err := blake2b.New(pasetoEncKeySize, secret)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	enc.Write([]byte("paseto-encryption-key"))
+	enc.Write(nonce)
+	derived := enc.Sum(nil)
+
+	auth, err := blake2b.New(pasetoMACSize, secret)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	auth.Write([]byte("paseto-auth-key-for-aead"))
+	auth.Write(nonce)
+
+	return derived[:chacha20.KeySize], derived[chacha20.KeySize:], auth.Sum(nil), nil
+}
+
+func pasetoMAC(authKey []byte, nonce []byte, ciphertext []byte, footer []byte) ([]byte, error) {
+	mac, err := blake2b.New(pasetoMACSize, authKey)
+	if err != nil {
+		return nil, err
+	}
+	mac.Write(pae([]byte(pasetoLocalHeader), nonce, ciphertext, footer, nil))
+	return mac.Sum(nil), nil
+}
+
+// pae is the pre-authentication encoding of the spec.
+func pae(pieces ...[]byte) []byte {
+	out := binary.LittleEndian.AppendUint64(nil, uint64(len(pieces)))
+	for _, piece := range pieces {
+		out = binary.LittleEndian.AppendUint64(out, uint64(len(piece)))
+		out = append(out, piece...)
+	}
+	return out
+}
+
+func pasetoMessage(claims map[string]interface{}, kid string) ([]byte, []byte, error) {
+	payload := make(map[string]interface{}, len(claims))
+	for name, value := range claims {
+		payload[name] = value
+	}
+	for _, name := range pasetoTimeClaims {
+		if unix, ok := unixClaim(payload[name]); ok {
+			payload[name] = time.Unix(unix, 0).UTC().Format(time.RFC3339)
+		}
+	}
+	message, err := sonic.ConfigStd.Marshal(payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	footer, err := sonic.ConfigStd.Marshal(pasetoFooter{KeyID: kid})
+	if err != nil {
+		return nil, nil, err
+	}
+	return message, footer, nil
+}
+
+// pasetoClaims returns the claims the way JWT decoding does, with unix timestamps.
+func pasetoClaims(message []byte) (map[string]interface{}, error) {
+	var claims map[string]interface{}
+	if err := sonic.ConfigStd.Unmarshal(message, &claims); err != nil {
+		return nil, errInvalidToken
+	}
+	for _, name := range pasetoTimeClaims {
+		value, ok := claims[name].(string)
+		if !ok {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, errInvalidToken
+		}
+		claims[name] = float64(t.Unix())
+	}
+
+	now := float64(time.Now().Unix())
+	if nbf, ok := claims["nbf"].(float64); ok && now < nbf {
+		return nil, errInvalidToken
+	}
+	if exp, ok := claims["exp"].(float64); ok && now > exp {
+		return claims, errTokenExpired
+	}
+	return claims, nil
+}
+
+func pasetoToken(header string, body []byte, footer []byte) string {
+	token := header + base64.RawURLEncoding.EncodeToString(body)
+	if len(footer) > 0 {
+		token += "." + base64.RawURLEncoding.EncodeToString(footer)
+	}
+	return token
+}
+
+func pasetoParts(token string, header string) ([]byte, []byte, error) {
+	if !strings.HasPrefix(token, header) {
+		return nil, nil, errInvalidToken
+	}
+	encoded, encodedFooter, _ := strings.Cut(strings.TrimPrefix(token, header), ".")
+	body, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, nil, errInvalidToken
+	}
+	footer, err := base64.RawURLEncoding.DecodeString(encodedFooter)
+	if err != nil {
+		return nil, nil, errInvalidToken
+	}
+	return body, footer, nil
+}
+
+func pasetoKeyID(footer []byte) string {
+	var f pasetoFooter
+	if len(footer) == 0 || sonic.ConfigStd.Unmarshal(footer, &f) != nil {
+		return ""
+	}
+	return f.KeyID
+}
+
+func unixClaim(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return v, true
+	case int:
+		return int64(v), true
+	case float64:
+		return int64(v), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,158 @@
+package hasher
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Test vectors from https://github.com/paseto-standard/test-vectors/blob/master/v4.json
+const (
+	pasetoVectorLocalKey   = "707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f"
+	pasetoVectorNonce      = "df654812bac492663825520ba2f6e67cf5ca5bdc13d4e7507a98cc4c2fcc3ad8"
+	pasetoVectorSeed       = "b4cbfb43df4ce210727d953e4a713307fa19bb7d9f85041438d9e11b942a3774"
+	pasetoVectorPublicKey  = "1eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2"
+	pasetoVectorFooter     = `{"kid":"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN"}`
+	pasetoVectorKeyID      = "zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN"
+	pasetoVectorSecretData = `{"data":"this is a secret message","exp":"2022-01-01T00:00:00+00:00"}`
+	pasetoVectorSignedData = `{"data":"this is a signed message","exp":"2022-01-01T00:00:00+00:00"}`
+)
+
+func pasetoVectorKeys(t *testing.T, key *JWTKey) *KeySet {
+	t.Helper()
+	return &KeySet{keys: map[string]*JWTKey{key.ID: key}, active: key, legacy: key}
+}
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestPasetoLocalVectors(t *testing.T) {
+	tests := []struct {
+		name   string
+		footer string
+		token  string
+	}{
+		{
+			name:  "4-E-3",
+			token: "v4.local.32VIErrEkmY4JVILovbmfPXKW9wT1OdQepjMTC_MOtjA4kiqw7_tcaOM5GNEcnTxl60WkwMsYXw6FSNb_UdJPXjpzm0KW9ojM5f4O2mRvE2IcweP-PRdoHjd5-RHCiExR1IK6t6-tyebyWG6Ov7kKvBdkrrAJ837lKP3iDag2hzUPHuMKA",
+		},
+		{
+			name:   "4-E-5",
+			footer: pasetoVectorFooter,
+			token:  "v4.local.32VIErrEkmY4JVILovbmfPXKW9wT1OdQepjMTC_MOtjA4kiqw7_tcaOM5GNEcnTxl60WkwMsYXw6FSNb_UdJPXjpzm0KW9ojM5f4O2mRvE2IcweP-PRdoHjd5-RHCiExR1IK6t4x-RMNXtQNbz7FvFZ_G-lFpk5RG3EOrwDL6CgDqcerSQ.eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9",
+		},
+	}
+
+	secret := mustHex(t, pasetoVectorLocalKey)
+	codec := &pasetoLocalCodec{keys: pasetoVectorKeys(t, &JWTKey{ID: pasetoVectorKeyID, Private: secret})}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := pasetoEncrypt(secret, mustHex(t, pasetoVectorNonce), []byte(pasetoVectorSecretData), []byte(tt.footer))
+			if err != nil {
+				t.Fatalf("pasetoEncrypt() error = %v", err)
+			}
+			if token != tt.token {
+				t.Errorf("pasetoEncrypt() = %s, want %s", token, tt.token)
+			}
+
+			claims, err := codec.decode(tt.token)
+			if !errors.Is(err, errTokenExpired) {
+				t.Fatalf("decode() error = %v, want %v", err, errTokenExpired)
+			}
+			if claims["data"] != "this is a secret message" {
+				t.Errorf("decode() data = %v", claims["data"])
+			}
+		})
+	}
+}
+
+func TestPasetoPublicVectors(t *testing.T) {
+	tests := []struct {
+		name   string
+		footer string
+		token  string
+	}{
+		{
+			name:  "4-S-1",
+			token: "v4.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9bg_XBBzds8lTZShVlwwKSgeKpLT3yukTw6JUz3W4h_ExsQV-P0V54zemZDcAxFaSeef1QlXEFtkqxT1ciiQEDA",
+		},
+		{
+			name:   "4-S-2",
+			footer: pasetoVectorFooter,
+			token:  "v4.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9v3Jt8mx_TdM2ceTGoqwrh4yDFn0XsHvvV_D0DtwQxVrJEBMl0F2caAdgnpKlt4p7xBnx1HcO-SPo8FPp214HDw.eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9",
+		},
+	}
+
+	private := ed25519.NewKeyFromSeed(mustHex(t, pasetoVectorSeed))
+	public := private.Public().(ed25519.PublicKey)
+	if hex.EncodeToString(public) != pasetoVectorPublicKey {
+		t.Fatalf("public key = %x, want %s", public, pasetoVectorPublicKey)
+	}
+	codec := &pasetoPublicCodec{keys: pasetoVectorKeys(t, &JWTKey{ID: pasetoVectorKeyID, Public: public, Private: private})}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if token := pasetoSign(private, []byte(pasetoVectorSignedData), []byte(tt.footer)); token != tt.token {
+				t.Errorf("pasetoSign() = %s, want %s", token, tt.token)
+			}
+
+			claims, err := codec.decode(tt.token)
+			if !errors.Is(err, errTokenExpired) {
+				t.Fatalf("decode() error = %v, want %v", err, errTokenExpired)
+			}
+			if claims["data"] != "this is a signed message" {
+				t.Errorf("decode() data = %v", claims["data"])
+			}
+		})
+	}
+}
+
+func TestPasetoRoundTrip(t *testing.T) {
+	private := ed25519.NewKeyFromSeed(mustHex(t, pasetoVectorSeed))
+	codecs := map[string]tokenCodec{
+		"v4.public": &pasetoPublicCodec{keys: pasetoVectorKeys(t, &JWTKey{ID: "k1", Public: private.Public(), Private: private})},
+		"v4.local":  &pasetoLocalCodec{keys: pasetoVectorKeys(t, &JWTKey{ID: "k1", Private: mustHex(t, pasetoVectorLocalKey)})},
+	}
+	exp := time.Now().Add(time.Hour).Unix()
+
+	for name, codec := range codecs {
+		t.Run(name, func(t *testing.T) {
+			token, err := codec.encode(map[string]interface{}{"sub": "42", "exp": exp})
+			if err != nil {
+				t.Fatalf("encode() error = %v", err)
+			}
+			claims, err := codec.decode(token)
+			if err != nil {
+				t.Fatalf("decode() error = %v", err)
+			}
+			if claims["sub"] != "42" || claims["exp"] != float64(exp) {
+				t.Errorf("decode() claims = %v", claims)
+			}
+
+			if _, err = codec.decode(tamper(token)); !errors.Is(err, errInvalidToken) {
+				t.Errorf("decode() of a tampered token error = %v, want %v", err, errInvalidToken)
+			}
+		})
+	}
+}
+
+// tamper flips a bit of the first byte of the token body.
+func tamper(token string) string {
+	parts := strings.Split(token, ".")
+	body, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	body[0] ^= 1
+	parts[2] = base64.RawURLEncoding.EncodeToString(body)
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,143 @@
+package hasher
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestURLSigner(t *testing.T) {
+	current := SignerKey{ID: "k2", Secret: []byte("current-secret")}
+	previous := SignerKey{ID: "k1", Secret: []byte("previous-secret")}
+	signer, err := NewURLSigner(current, previous)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldSigner, err := NewURLSigner(previous)
+	if err != nil {
+		t.Fatal(err)
+	}
+	retired, err := NewURLSigner(SignerKey{ID: "k0", Secret: []byte("retired-secret")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		url  func(t *testing.T) string
+		want error
+	}{
+		{
+			name: "valid",
+			url:  func(t *testing.T) string { return mustSign(t, signer, "https://api.test/confirm?user=1", time.Hour) },
+		},
+		{
+			name: "relative",
+			url:  func(t *testing.T) string { return mustSign(t, signer, "/files/1/download", time.Hour) },
+		},
+		{
+			name: "expired",
+			url:  func(t *testing.T) string { return mustSign(t, signer, "/confirm?user=1", -time.Minute) },
+			want: ErrURLExpired,
+		},
+		{
+			name: "tampered query",
+			url: func(t *testing.T) string {
+				return replaceQuery(t, mustSign(t, signer, "/confirm?user=1", time.Hour), "user", "2")
+			},
+			want: ErrURLSignature,
+		},
+		{
+			name: "extended expiry",
+			url: func(t *testing.T) string {
+				return replaceQuery(t, mustSign(t, signer, "/confirm?user=1", -time.Minute), ExpiresParam, "9999999999")
+			},
+			want: ErrURLSignature,
+		},
+		{
+			name: "tampered path",
+			url: func(t *testing.T) string {
+				u, _ := url.Parse(mustSign(t, signer, "/confirm?user=1", time.Hour))
+				u.Path = "/reset"
+				return u.String()
+			},
+			want: ErrURLSignature,
+		},
+		{
+			name: "rotated key",
+			url:  func(t *testing.T) string { return mustSign(t, oldSigner, "/confirm?user=1", time.Hour) },
+		},
+		{
+			name: "swapped kid",
+			url: func(t *testing.T) string {
+				return replaceQuery(t, mustSign(t, oldSigner, "/confirm?user=1", time.Hour), KeyIDParam, current.ID)
+			},
+			want: ErrURLSignature,
+		},
+		{
+			name: "removed key",
+			url:  func(t *testing.T) string { return mustSign(t, retired, "/confirm?user=1", time.Hour) },
+			want: ErrURLSignature,
+		},
+		{
+			name: "unsigned",
+			url:  func(*testing.T) string { return "/confirm?user=1" },
+			want: ErrURLSignature,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := signer.Verify(tt.url(t)); !errors.Is(err, tt.want) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignUsesFirstKey(t *testing.T) {
+	signer, err := NewURLSigner(SignerKey{ID: "k2", Secret: []byte("a")}, SignerKey{ID: "k1", Secret: []byte("b")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(mustSign(t, signer, "/confirm", time.Hour))
+	if got := u.Query().Get(KeyIDParam); got != "k2" {
+		t.Errorf("%s = %q, want k2", KeyIDParam, got)
+	}
+}
+
+func TestParseSignerKeys(t *testing.T) {
+	keys, err := ParseSignerKeys(" k2:secret2 , k1:secret1,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 || keys[0].ID != "k2" || string(keys[1].Secret) != "secret1" {
+		t.Errorf("ParseSignerKeys() = %+v", keys)
+	}
+	if _, err = ParseSignerKeys("k1"); err == nil {
+		t.Error("ParseSignerKeys() accepted a key without secret")
+	}
+}
+
+func mustSign(t *testing.T, signer *URLSigner, rawURL string, ttl time.Duration) string {
+	t.Helper()
+	signed, err := signer.Sign(rawURL, ttl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func replaceQuery(t *testing.T, rawURL string, name string, value string) string {
+	t.Helper()
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := u.Query()
+	query.Set(name, value)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
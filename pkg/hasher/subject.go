@@ -3,5 +3,7 @@ package hasher
 const (
 	AccessTokenSubject  = "access_token"
 	RefreshTokenSubject = "refresh_token"
-	ConfirmTokenSubject = "confirm_token"
+	MagicLinkSubject    = "magic_link"
+	// ClientTokenSubject marks access tokens of the client credentials grant, issued to clients rather than users.
+	ClientTokenSubject = "client_token"
 )
@@ -0,0 +1,74 @@
+package hasher
+
+import (
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+)
+
+const (
+	AlgRS256        = "RS256"
+	AlgRS512        = "RS512"
+	AlgES256        = "ES256"
+	AlgES384        = "ES384"
+	AlgES512        = "ES512"
+	AlgEdDSA        = "EdDSA"
+	AlgHS256        = "HS256"
+	AlgHS512        = "HS512"
+	AlgPasetoPublic = "v4.public"
+	AlgPasetoLocal  = "v4.local"
+)
+
+var (
+	errInvalidToken = errors.New("invalid token")
+	errTokenExpired = errors.New("token expired")
+)
+
+// tokenCodec is a token format; decode returns the claims along with errTokenExpired for expired tokens.
+type tokenCodec interface {
+	encode(claims map[string]interface{}) (string, error)
+	decode(token string) (map[string]interface{}, error)
+}
+
+func newTokenCodec(keys *KeySet) tokenCodec {
+	switch keys.Algorithm() {
+	case AlgPasetoPublic:
+		return &pasetoPublicCodec{keys: keys}
+	case AlgPasetoLocal:
+		return &pasetoLocalCodec{keys: keys}
+	default:
+		return &jwtCodec{keys: keys}
+	}
+}
+
+type jwtCodec struct {
+	keys *KeySet
+}
+
+func (j *jwtCodec) encode(claims map[string]interface{}) (string, error) {
+	key := j.keys.Active()
+	token := jwt.NewWithClaims(key.Method, jwt.MapClaims(claims))
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.Private)
+}
+
+func (j *jwtCodec) decode(token string) (map[string]interface{}, error) {
+	var claims jwt.MapClaims
+	tokenData, err := jwt.ParseWithClaims(token, &claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, ok := j.keys.Key(kid)
+		if !ok || key.Method == nil {
+			return nil, errors.New("unknown token key")
+		}
+		if t.Method.Alg() != key.Method.Alg() {
+			return nil, errors.New("invalid token algorithm")
+		}
+		return key.Public, nil
+	})
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return claims, errTokenExpired
+	}
+	if tokenData == nil || !tokenData.Valid || err != nil {
+		return nil, errInvalidToken
+	}
+	return claims, nil
+}
@@ -33,11 +33,16 @@ func (b *BaseController) BindAndValidate(c fiber.Ctx, dst any, code int) error {
 		return err
 	}
 
+	return b.Validate(c, dst)
+}
+
+// Validate checks an already bound request, for handlers that fill in data between binding and validation.
+func (b *BaseController) Validate(c fiber.Ctx, dst any) error {
 	if dst, ok := dst.(dto.SetDefaults); ok {
 		dst.SetDefaults()
 	}
 
-	if err = b.validation.Struct(dst); err != nil {
+	if err := b.validation.StructCtx(c.Context(), dst); err != nil {
 		return err
 	}
 
@@ -8,6 +8,7 @@ import (
 	"github.com/dbunt1tled/fiber-go-api/pkg/e"
 	"github.com/dbunt1tled/fiber-go-api/pkg/http/dto"
 	"github.com/dbunt1tled/fiber-go-api/pkg/log"
+	"github.com/dbunt1tled/fiber-go-api/pkg/storage"
 	"github.com/dbunt1tled/fiber-go-api/pkg/validation"
 	"github.com/gofiber/fiber/v3"
 )
@@ -31,6 +32,13 @@ func APIErrorHandler(ctx fiber.Ctx, err error) error {
 		code = errNo.Code
 	}
 
+	// Handle stale updates of versioned entities
+	if errors.Is(err, storage.ErrConflict) {
+		status = http.StatusConflict
+		message = "The resource was changed by another request, reload it and try again."
+		code = e.Err409ConflictError
+	}
+
 	vErr := validation.ErrorValidation(err)
 	if vErr != nil {
 		status = http.StatusUnprocessableEntity
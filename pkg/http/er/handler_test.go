@@ -0,0 +1,63 @@
+package er
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dbunt1tled/fiber-go-api/internal/config"
+	"github.com/dbunt1tled/fiber-go-api/pkg/e"
+	"github.com/dbunt1tled/fiber-go-api/pkg/log"
+	"github.com/dbunt1tled/fiber-go-api/pkg/storage"
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestAPIErrorHandlerStatus(t *testing.T) {
+	config.Load()
+	log.Load("test", "dev", log.LevelDebug, "")
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{
+			name: "conflict",
+			err:  fmt.Errorf("update user: %w", storage.ErrConflict),
+			want: http.StatusConflict,
+		},
+		{
+			name: "wrapped conflict",
+			err:  e.NewUnprocessableEntityErrorWrap("", e.Err422LoginValidateError, fmt.Errorf("update user: %w", storage.ErrConflict)),
+			want: http.StatusConflict,
+		},
+		{
+			name: "wrapped error",
+			err:  e.NewUnprocessableEntityErrorWrap("", e.Err422LoginValidateError, errors.New("boom")),
+			want: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "not found",
+			err:  e.NewNotFoundError("not found", 0),
+			want: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{ErrorHandler: APIErrorHandler})
+			app.Get("/", func(fiber.Ctx) error { return tt.err })
+
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
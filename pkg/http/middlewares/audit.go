@@ -0,0 +1,19 @@
+package middlewares
+
+import (
+	"github.com/dbunt1tled/fiber-go-api/pkg/audit"
+	"github.com/gofiber/fiber/v3"
+)
+
+// NewAudit puts the audit meta of the request in its context, it has to run after NewRequestID.
+// The actor is added once the request is authenticated.
+func NewAudit() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		c.SetContext(audit.WithMeta(c.Context(), &audit.Meta{
+			IP:        c.IP(),
+			RequestID: RequestID(c),
+		}))
+
+		return c.Next()
+	}
+}
@@ -1,30 +1,54 @@
 package middlewares
 
 import (
+	"github.com/dbunt1tled/fiber-go-api/internal/modules/apikey"
 	"github.com/dbunt1tled/fiber-go-api/internal/modules/auth"
+	"github.com/dbunt1tled/fiber-go-api/internal/modules/client"
+	"github.com/dbunt1tled/fiber-go-api/internal/modules/impersonation"
 	"github.com/dbunt1tled/fiber-go-api/internal/modules/user"
+	"github.com/dbunt1tled/fiber-go-api/pkg/audit"
 	"github.com/dbunt1tled/fiber-go-api/pkg/e"
 	"github.com/dbunt1tled/fiber-go-api/pkg/hasher"
 	"github.com/gofiber/fiber/v3"
 	"github.com/google/uuid"
 )
 
+const (
+	HeaderAPIKey = "X-API-Key"
+	// LinkUserParam names the user a signed link authenticates.
+	LinkUserParam = "user"
+)
+
 type AuthMiddleware struct {
-	authService *auth.Service
-	userService *user.Service
+	authService   *auth.Service
+	userService   *user.Service
+	apiKeyService *apikey.Service
+	clientService *client.Service
+
+	impersonationService *impersonation.Service
 }
 
 func NewAuthMiddleware(
 	authService *auth.Service,
 	userService *user.Service,
+	apiKeyService *apikey.Service,
+	clientService *client.Service,
+	impersonationService *impersonation.Service,
 ) *AuthMiddleware {
 	return &AuthMiddleware{
-		authService: authService,
-		userService: userService,
+		authService:          authService,
+		userService:          userService,
+		apiKeyService:        apiKeyService,
+		clientService:        clientService,
+		impersonationService: impersonationService,
 	}
 }
 
 func (a *AuthMiddleware) Auth(c fiber.Ctx) error {
+	if key := c.Get(HeaderAPIKey); key != "" {
+		return a.apiKey(c, key)
+	}
+
 	authorization := c.Get("Authorization")
 	if authorization == "" {
 		return e.NewUnauthorizedError("Unauthorized", e.Err401AuthEmptyTokenError)
@@ -53,7 +77,191 @@ func (a *AuthMiddleware) Auth(c fiber.Ctx) error {
 		return e.NewUnauthorizedError("Unauthorized", e.Err401UserNotActiveError)
 	}
 
+	// tokens issued before scopes were added carry none, they get the scopes of the roles
+	scopes := auth.UserScopes(u)
+	if scope, ok := token["scope"].(string); ok {
+		scopes = auth.ParseScope(scope)
+	}
+
+	var impersonator *uuid.UUID
+	if _, ok := token["act"]; ok {
+		if err = a.impersonate(c, token, u); err != nil {
+			return err
+		}
+		impersonator = &impersonation.Actor(c).ID
+	}
+
 	c.Locals("user", u)
+	c.Locals("scopes", scopes)
+	audit.SetActor(c.Context(), u.ID, impersonator)
+
+	return c.Next()
+}
+
+// LinkOrAuth authenticates the user named by a signed link, for clients such as the browser EventSource
+// that can't send headers; requests without a signature go through Auth.
+func (a *AuthMiddleware) LinkOrAuth(signed *SignedURLMiddleware) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if c.Query(hasher.SignatureParam) == "" {
+			return a.Auth(c)
+		}
+		if err := signed.verify(c); err != nil {
+			return err
+		}
+
+		id, err := uuid.Parse(c.Query(LinkUserParam))
+		if err != nil {
+			return e.NewUnauthorizedError("Unauthorized", e.Err401TokenUserIdError)
+		}
+
+		u, err := a.userService.FindByID(c.Context(), id)
+		if err != nil || u == nil {
+			return e.NewUnauthorizedError("Unauthorized", e.Err401UserNotFoundError)
+		}
+
+		if u.Status != user.Active {
+			return e.NewUnauthorizedError("Unauthorized", e.Err401UserNotActiveError)
+		}
+
+		c.Locals("user", u)
+		c.Locals("scopes", auth.UserScopes(u))
+		audit.SetActor(c.Context(), u.ID, nil)
+
+		return c.Next()
+	}
+}
+
+// Admin must run after Auth.
+func (a *AuthMiddleware) Admin(c fiber.Ctx) error {
+	u, ok := c.Locals("user").(*user.User)
+	if !ok || u == nil {
+		return e.NewUnauthorizedError("Unauthorized", e.Err401UserNotFoundError)
+	}
+
+	if !u.HasRole(user.Admin) {
+		return e.NewForbiddenError("Forbidden", e.Err403AdminRequiredError)
+	}
 
 	return c.Next()
 }
+
+// NotImpersonating must run after Auth, it keeps sensitive actions to the account owner.
+func (a *AuthMiddleware) NotImpersonating(c fiber.Ctx) error {
+	if impersonation.Actor(c) != nil {
+		return e.NewForbiddenError("Not allowed while impersonating.", e.Err403ImpersonationForbiddenError)
+	}
+
+	return c.Next()
+}
+
+// Scope must run after Auth or Client, the granted scopes have to include every given one.
+func (a *AuthMiddleware) Scope(scopes ...string) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		granted, _ := c.Locals("scopes").([]string)
+		if !auth.HasScopes(granted, scopes...) {
+			return e.NewForbiddenError("Insufficient scope.", e.Err403ScopeError)
+		}
+
+		return c.Next()
+	}
+}
+
+// Client authenticates a registered client by HTTP Basic credentials or by a token of the client credentials grant.
+func (a *AuthMiddleware) Client(c fiber.Ctx) error {
+	if clientID, secret, ok := client.Credentials(c); ok {
+		cl, err := a.clientService.Authenticate(c.Context(), clientID, secret)
+		if err != nil {
+			return e.NewUnauthorizedError("Unauthorized", e.Err401ClientError)
+		}
+
+		c.Locals("client", cl)
+		c.Locals("scopes", cl.Scopes)
+
+		return c.Next()
+	}
+
+	authorization := c.Get("Authorization")
+	if authorization == "" {
+		return e.NewUnauthorizedError("Unauthorized", e.Err401AuthEmptyTokenError)
+	}
+
+	token, err := a.authService.DecodeBearerToken(authorization, hasher.WithSubject(hasher.ClientTokenSubject))
+	if err != nil {
+		return err
+	}
+
+	iss, _ := token["iss"].(string)
+	id, err := uuid.Parse(iss)
+	if err != nil {
+		return e.NewUnauthorizedError("Unauthorized", e.Err401ClientError)
+	}
+
+	cl, err := a.clientService.FindByID(c.Context(), id)
+	if err != nil || cl == nil {
+		return e.NewUnauthorizedError("Unauthorized", e.Err401ClientError)
+	}
+
+	if !cl.Active {
+		return e.NewUnauthorizedError("Unauthorized", e.Err401ClientNotActiveError)
+	}
+
+	scope, _ := token["scope"].(string)
+
+	c.Locals("client", cl)
+	c.Locals("scopes", auth.ParseScope(scope))
+
+	return c.Next()
+}
+
+// apiKey authenticates the owner of the key, limited to what the key's scopes allow.
+func (a *AuthMiddleware) apiKey(c fiber.Ctx, key string) error {
+	k, err := a.apiKeyService.Authenticate(c.Context(), key)
+	if err != nil {
+		return e.NewUnauthorizedError("Unauthorized", e.Err401APIKeyError)
+	}
+
+	u, err := a.userService.FindByID(c.Context(), k.UserID)
+	if err != nil || u == nil {
+		return e.NewUnauthorizedError("Unauthorized", e.Err401APIKeyError)
+	}
+
+	if u.Status != user.Active {
+		return e.NewUnauthorizedError("Unauthorized", e.Err401APIKeyUserNotActiveError)
+	}
+
+	if !k.Allows(c.Method()) {
+		return e.NewForbiddenError("API key scopes don't allow this request.", e.Err403APIKeyScopeError)
+	}
+
+	c.Locals("user", u)
+	c.Locals("apiKey", k)
+	c.Locals("scopes", k.Scopes)
+	audit.SetActor(c.Context(), u.ID, nil)
+
+	return c.Next()
+}
+
+// impersonate accepts a token with an act claim while its impersonation runs, the admin in act is exposed
+// as Locals("actor") next to the impersonated user.
+func (a *AuthMiddleware) impersonate(c fiber.Ctx, token map[string]interface{}, u *user.User) error {
+	act, _ := token["act"].(map[string]interface{})
+	actorID, _ := act["sub"].(string)
+	sid, _ := token["sid"].(string)
+	id, err := uuid.Parse(sid)
+	if err != nil {
+		return e.NewUnauthorizedError("Unauthorized", e.Err401ImpersonationError)
+	}
+
+	i, actor, err := a.impersonationService.Active(c.Context(), id)
+	if err != nil || i == nil {
+		return e.NewUnauthorizedError("Unauthorized", e.Err401ImpersonationError)
+	}
+	if i.UserID != u.ID || i.ActorID.String() != actorID {
+		return e.NewUnauthorizedError("Unauthorized", e.Err401ImpersonationError)
+	}
+
+	c.Locals("actor", actor)
+	c.Locals("impersonation", i)
+
+	return nil
+}
@@ -0,0 +1,28 @@
+package middlewares
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+)
+
+const maxRequestIDLength = 100
+
+// NewRequestID keeps the X-Request-ID of the caller, e.g. a proxy, or makes one, and echoes it in the response.
+func NewRequestID() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		rid := c.Get(fiber.HeaderXRequestID)
+		if rid == "" || len(rid) > maxRequestIDLength {
+			rid = uuid.NewString()
+		}
+		c.Set(fiber.HeaderXRequestID, rid)
+		c.Locals("requestId", rid)
+
+		return c.Next()
+	}
+}
+
+// RequestID is the id NewRequestID gave the request.
+func RequestID(c fiber.Ctx) string {
+	rid, _ := c.Locals("requestId").(string)
+	return rid
+}
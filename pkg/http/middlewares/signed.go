@@ -0,0 +1,41 @@
+package middlewares
+
+import (
+	"errors"
+
+	"github.com/dbunt1tled/fiber-go-api/pkg/e"
+	"github.com/dbunt1tled/fiber-go-api/pkg/hasher"
+	"github.com/gofiber/fiber/v3"
+)
+
+type SignedURLMiddleware struct {
+	signer *hasher.URLSigner
+}
+
+func NewSignedURLMiddleware(signer *hasher.URLSigner) *SignedURLMiddleware {
+	return &SignedURLMiddleware{
+		signer: signer,
+	}
+}
+
+// Verify lets through only links produced by the signer, the signature
+// standing in for authentication on routes reached from emails.
+func (s *SignedURLMiddleware) Verify(c fiber.Ctx) error {
+	if err := s.verify(c); err != nil {
+		return err
+	}
+
+	return c.Next()
+}
+
+func (s *SignedURLMiddleware) verify(c fiber.Ctx) error {
+	err := s.signer.Verify(c.OriginalURL())
+	switch {
+	case errors.Is(err, hasher.ErrURLExpired):
+		return e.NewNotFoundError("Link has expired.", e.Err404URLExpired)
+	case err != nil:
+		return e.NewForbiddenError("Invalid link signature.", e.Err403URLSignatureInvalid)
+	}
+
+	return nil
+}
@@ -6,6 +6,17 @@ import (
 	"github.com/wneessen/go-mail"
 )
 
+const (
+	TransportSMTP = "smtp"
+	TransportFile = "file"
+	TransportLog  = "log"
+)
+
+type Sender interface {
+	SendCtx(ctx context.Context, messages ...*mail.Msg) error
+	Close() error
+}
+
 type Mailer struct {
 	client    *mail.Client
 	fromEmail string
@@ -0,0 +1,74 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dbunt1tled/fiber-go-api/pkg/log"
+	"github.com/google/uuid"
+	"github.com/wneessen/go-mail"
+)
+
+// FileMailer writes every message as an .eml file into a directory instead of delivering it.
+type FileMailer struct {
+	dir string
+}
+
+// LogMailer writes every message into the application log instead of delivering it.
+type LogMailer struct{}
+
+func NewFileMailer(dir string) *FileMailer {
+	return &FileMailer{dir: dir}
+}
+
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+func (m *FileMailer) SendCtx(ctx context.Context, messages ...*mail.Msg) error {
+	if err := os.MkdirAll(m.dir, 0o750); err != nil { //nolint:mnd // directory permissions
+		return fmt.Errorf("create mail directory: %w", err)
+	}
+	for _, msg := range messages {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		name := fmt.Sprintf("%s_%s.eml", time.Now().UTC().Format("20060102T150405"), uuid.NewString())
+		if err := msg.WriteToFile(filepath.Join(m.dir, name)); err != nil {
+			return fmt.Errorf("write mail file: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (m *FileMailer) Close() error {
+	return nil
+}
+
+func (m *LogMailer) SendCtx(ctx context.Context, messages ...*mail.Msg) error {
+	for _, msg := range messages {
+		var raw bytes.Buffer
+		if _, err := msg.WriteTo(&raw); err != nil {
+			return fmt.Errorf("render mail: %w", err)
+		}
+		log.Logger().InfoContext(
+			ctx,
+			"Mail written to log transport",
+			slog.String("to", strings.Join(msg.GetToString(), ",")),
+			slog.String("message", raw.String()),
+		)
+	}
+
+	return nil
+}
+
+func (m *LogMailer) Close() error {
+	return nil
+}
@@ -0,0 +1,130 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/github"
+)
+
+const gitHubAPI = "https://api.github.com"
+
+// GitHubProvider signs users in with GitHub, which speaks plain OAuth2: the identity
+// comes from the user API and the email from the verified primary address.
+type GitHubProvider struct {
+	opts   Options
+	config oauth2.Config
+}
+
+type gitHubUser struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+	Name  string `json:"name"`
+}
+
+type gitHubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+func NewGitHubProvider(opts Options) *GitHubProvider {
+	if len(opts.Scopes) == 0 {
+		opts.Scopes = []string{"read:user", "user:email"}
+	}
+	if opts.APIURL == "" {
+		opts.APIURL = gitHubAPI
+	}
+	opts.APIURL = strings.TrimSuffix(opts.APIURL, "/")
+	endpoint := github.Endpoint
+	if opts.AuthURL != "" {
+		endpoint.AuthURL = opts.AuthURL
+	}
+	if opts.TokenURL != "" {
+		endpoint.TokenURL = opts.TokenURL
+	}
+
+	return &GitHubProvider{
+		opts: opts,
+		config: oauth2.Config{
+			ClientID:     opts.ClientID,
+			ClientSecret: opts.ClientSecret,
+			RedirectURL:  opts.RedirectURL,
+			Endpoint:     endpoint,
+			Scopes:       opts.Scopes,
+		},
+	}
+}
+
+func (p *GitHubProvider) Name() string {
+	return p.opts.Name
+}
+
+func (p *GitHubProvider) AuthCodeURL(_ context.Context, state string, _ string, verifier string) (string, error) {
+	return p.config.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier)), nil
+}
+
+func (p *GitHubProvider) Exchange(ctx context.Context, code string, _ string, verifier string) (*Identity, error) {
+	ctx = p.opts.context(ctx)
+	token, err := p.config.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, errors.Join(ErrExchange, err)
+	}
+	client := p.config.Client(ctx, token)
+
+	var u gitHubUser
+	if err = p.get(ctx, client, "/user", &u); err != nil {
+		return nil, errors.Join(ErrExchange, err)
+	}
+	var emails []gitHubEmail
+	if err = p.get(ctx, client, "/user/emails", &emails); err != nil {
+		return nil, errors.Join(ErrExchange, err)
+	}
+
+	identity := &Identity{
+		Provider: p.opts.Name,
+		Subject:  strconv.FormatInt(u.ID, 10),
+	}
+	identity.FirstName, identity.LastName = splitName(u.Name)
+	if identity.FirstName == "" {
+		identity.FirstName = u.Login
+	}
+	for _, email := range emails {
+		if email.Primary {
+			identity.Email = email.Email
+			identity.EmailVerified = email.Verified
+		}
+	}
+	return identity, nil
+}
+
+func (p *GitHubProvider) get(ctx context.Context, client *http.Client, path string, dst any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.opts.APIURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("github %s: status %d", path, resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	return sonic.Unmarshal(body, dst)
+}
@@ -0,0 +1,97 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	DriverOIDC   = "oidc"
+	DriverGoogle = "google"
+	DriverGitHub = "github"
+
+	GoogleIssuer = "https://accounts.google.com"
+)
+
+var ErrExchange = errors.New("oauth: code exchange failed")
+
+// Identity is what a provider asserts about the user who signed in.
+type Identity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	FirstName     string
+	LastName      string
+}
+
+// Provider runs the authorization code flow with PKCE against one identity provider.
+type Provider interface {
+	Name() string
+	// AuthCodeURL is where the user agent is sent; nonce is bound into the ID token by OIDC providers.
+	AuthCodeURL(ctx context.Context, state string, nonce string, verifier string) (string, error)
+	Exchange(ctx context.Context, code string, nonce string, verifier string) (*Identity, error)
+}
+
+type Options struct {
+	// Name identifies the provider in routes and stored identities, Driver defaults to it.
+	Name         string
+	Driver       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	// Issuer is discovered through /.well-known/openid-configuration by OIDC providers.
+	Issuer string
+	Scopes []string
+	// AuthURL, TokenURL and APIURL override the GitHub endpoints, for GitHub Enterprise or a mock.
+	AuthURL  string
+	TokenURL string
+	APIURL   string
+	// Client makes discovery, token and API requests, http.DefaultClient when nil.
+	Client *http.Client
+}
+
+func New(opts Options) (Provider, error) {
+	if opts.ClientID == "" {
+		return nil, fmt.Errorf("oauth: provider %q has no client id", opts.Name)
+	}
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+	driver := opts.Driver
+	if driver == "" {
+		driver = opts.Name
+	}
+	switch driver {
+	case DriverGoogle:
+		if opts.Issuer == "" {
+			opts.Issuer = GoogleIssuer
+		}
+		return NewOIDCProvider(opts)
+	case DriverGitHub:
+		return NewGitHubProvider(opts), nil
+	case DriverOIDC:
+		return NewOIDCProvider(opts)
+	default:
+		return nil, fmt.Errorf("oauth: unknown driver %q", driver)
+	}
+}
+
+// Verifier returns a new PKCE code verifier.
+func Verifier() string {
+	return oauth2.GenerateVerifier()
+}
+
+func (o Options) context(ctx context.Context) context.Context {
+	return context.WithValue(ctx, oauth2.HTTPClient, o.Client)
+}
+
+func splitName(name string) (string, string) {
+	first, last, _ := strings.Cut(strings.TrimSpace(name), " ")
+	return first, strings.TrimSpace(last)
+}
@@ -0,0 +1,142 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// OIDCProvider signs users in with any OpenID Connect provider. Discovery runs on first use
+// and is retried until it succeeds, so a provider being down doesn't stop the application from starting.
+type OIDCProvider struct {
+	opts Options
+
+	mu       sync.Mutex
+	provider *oidc.Provider
+	config   oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+type oidcClaims struct {
+	Email         string `json:"email"`
+	EmailVerified flag   `json:"email_verified"`
+	Name          string `json:"name"`
+	GivenName     string `json:"given_name"`
+	FamilyName    string `json:"family_name"`
+}
+
+// flag accepts booleans sent as strings, as some providers do for email_verified.
+type flag bool
+
+func (f *flag) UnmarshalJSON(data []byte) error {
+	value, err := strconv.ParseBool(strings.Trim(string(data), `"`))
+	if err != nil {
+		return err
+	}
+	*f = flag(value)
+	return nil
+}
+
+func NewOIDCProvider(opts Options) (*OIDCProvider, error) {
+	if opts.Issuer == "" {
+		return nil, fmt.Errorf("oauth: provider %q has no issuer", opts.Name)
+	}
+	if len(opts.Scopes) == 0 {
+		opts.Scopes = []string{oidc.ScopeOpenID, "email", "profile"}
+	}
+	return &OIDCProvider{opts: opts}, nil
+}
+
+func (p *OIDCProvider) Name() string {
+	return p.opts.Name
+}
+
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context, state string, nonce string, verifier string) (string, error) {
+	if err := p.discover(ctx); err != nil {
+		return "", err
+	}
+	return p.config.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)), nil
+}
+
+func (p *OIDCProvider) Exchange(ctx context.Context, code string, nonce string, verifier string) (*Identity, error) {
+	if err := p.discover(ctx); err != nil {
+		return nil, err
+	}
+	ctx = p.opts.context(ctx)
+
+	token, err := p.config.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, errors.Join(ErrExchange, err)
+	}
+	raw, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, errors.Join(ErrExchange, errors.New("no id_token in token response"))
+	}
+	idToken, err := p.verifier.Verify(ctx, raw)
+	if err != nil {
+		return nil, errors.Join(ErrExchange, err)
+	}
+	if idToken.Nonce != nonce {
+		return nil, errors.Join(ErrExchange, errors.New("id_token nonce mismatch"))
+	}
+
+	var claims oidcClaims
+	if err = idToken.Claims(&claims); err != nil {
+		return nil, errors.Join(ErrExchange, err)
+	}
+	// some providers only put the email into the userinfo response
+	if claims.Email == "" {
+		info, err := p.provider.UserInfo(ctx, oauth2.StaticTokenSource(token))
+		if err != nil {
+			return nil, errors.Join(ErrExchange, err)
+		}
+		if info.Subject != idToken.Subject {
+			return nil, errors.Join(ErrExchange, errors.New("userinfo subject mismatch"))
+		}
+		if err = info.Claims(&claims); err != nil {
+			return nil, errors.Join(ErrExchange, err)
+		}
+	}
+
+	identity := &Identity{
+		Provider:      p.opts.Name,
+		Subject:       idToken.Subject,
+		Email:         claims.Email,
+		EmailVerified: bool(claims.EmailVerified),
+		FirstName:     claims.GivenName,
+		LastName:      claims.FamilyName,
+	}
+	if identity.FirstName == "" {
+		identity.FirstName, identity.LastName = splitName(claims.Name)
+	}
+	return identity, nil
+}
+
+func (p *OIDCProvider) discover(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.provider != nil {
+		return nil
+	}
+
+	provider, err := oidc.NewProvider(oidc.ClientContext(ctx, p.opts.Client), p.opts.Issuer)
+	if err != nil {
+		return fmt.Errorf("oauth: %s discovery: %w", p.opts.Name, err)
+	}
+	p.provider = provider
+	p.config = oauth2.Config{
+		ClientID:     p.opts.ClientID,
+		ClientSecret: p.opts.ClientSecret,
+		RedirectURL:  p.opts.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       p.opts.Scopes,
+	}
+	p.verifier = provider.Verifier(&oidc.Config{ClientID: p.opts.ClientID})
+	return nil
+}
@@ -0,0 +1,164 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testClientID = "client"
+	testKeyID    = "test-key"
+	testCode     = "code"
+	testVerifier = "verifier-verifier-verifier-verifier-verifier"
+)
+
+// testIssuer serves discovery, JWKS and a token endpoint answering with the ID token claims it is given.
+func testIssuer(t *testing.T, claims func(issuer string) jwt.MapClaims) *httptest.Server {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, map[string]any{
+			"issuer":                                server.URL,
+			"authorization_endpoint":                server.URL + "/authorize",
+			"token_endpoint":                        server.URL + "/token",
+			"jwks_uri":                              server.URL + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"alg": "RS256",
+			"use": "sig",
+			"kid": testKeyID,
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("code") != testCode || r.FormValue("code_verifier") != testVerifier {
+			w.WriteHeader(http.StatusBadRequest)
+			writeJSON(w, map[string]string{"error": "invalid_grant"})
+			return
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims(server.URL))
+		token.Header["kid"] = testKeyID
+		idToken, err := token.SignedString(key)
+		if err != nil {
+			t.Error(err)
+		}
+		writeJSON(w, map[string]any{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     idToken,
+		})
+	})
+	return server
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestOIDCExchange(t *testing.T) {
+	tests := []struct {
+		name    string
+		claims  func(issuer string) jwt.MapClaims
+		wantErr bool
+	}{
+		{
+			name:   "valid id token",
+			claims: func(issuer string) jwt.MapClaims { return idTokenClaims(issuer, testClientID, "nonce") },
+		},
+		{
+			name:    "wrong nonce",
+			claims:  func(issuer string) jwt.MapClaims { return idTokenClaims(issuer, testClientID, "other") },
+			wantErr: true,
+		},
+		{
+			name:    "wrong audience",
+			claims:  func(issuer string) jwt.MapClaims { return idTokenClaims(issuer, "other-client", "nonce") },
+			wantErr: true,
+		},
+		{
+			name:    "wrong issuer",
+			claims:  func(string) jwt.MapClaims { return idTokenClaims("https://issuer.invalid", testClientID, "nonce") },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := testIssuer(t, tt.claims)
+			provider, err := New(Options{
+				Name:        "test",
+				Driver:      DriverOIDC,
+				ClientID:    testClientID,
+				RedirectURL: "https://app.test/callback",
+				Issuer:      server.URL,
+				Client:      server.Client(),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			identity, err := provider.Exchange(context.Background(), testCode, "nonce", testVerifier)
+			if tt.wantErr {
+				if !errors.Is(err, ErrExchange) {
+					t.Fatalf("Exchange() error = %v, want %v", err, ErrExchange)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Exchange() error = %v", err)
+			}
+			want := Identity{
+				Provider:      "test",
+				Subject:       "42",
+				Email:         "jane@example.com",
+				EmailVerified: true,
+				FirstName:     "Jane",
+				LastName:      "Doe",
+			}
+			if *identity != want {
+				t.Errorf("Exchange() = %+v, want %+v", *identity, want)
+			}
+		})
+	}
+}
+
+func idTokenClaims(issuer string, audience string, nonce string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":            issuer,
+		"aud":            audience,
+		"sub":            "42",
+		"nonce":          nonce,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+		"email":          "jane@example.com",
+		"email_verified": "true",
+		"name":           "Jane Doe",
+	}
+}
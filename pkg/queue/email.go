@@ -4,22 +4,23 @@ import (
 	"context"
 	"time"
 
-	"github.com/bytedance/sonic"
 	"github.com/dbunt1tled/fiber-go-api/internal/lib/email"
-	"github.com/hibiken/asynq"
+	"github.com/dbunt1tled/fiber-go-api/pkg/f"
 )
 
 const (
-	EmailQueue    = "emails"
-	EmailSendTask = "email:send"
-	MaxRetry      = 3
-	TimeOut       = 30 * time.Second
+	EmailQueue       = "emails"
+	EmailSendTask    = "email:send"
+	EmailSendVersion = 1
+	MaxRetry         = 3
+	TimeOut          = 30 * time.Second
 )
 
 type EmailPayload struct {
 	To      string `json:"to"`
 	Subject string `json:"subject"`
 	Body    string `json:"body"`
+	Text    string `json:"text"`
 }
 
 type EmailHandler struct {
@@ -32,45 +33,14 @@ func NewEmailHandler(mailerService *email.MailService) *EmailHandler {
 	}
 }
 
-func (e *EmailPayload) Data() ([]byte, error) {
-	return sonic.ConfigFastest.Marshal(map[string]string{
-		"to":      e.To,
-		"subject": e.Subject,
-		"body":    e.Body,
-	})
+func NewEmailTask(r *Registry, handler *EmailHandler) *Task[EmailPayload] {
+	return Register(r, EmailSendTask, EmailSendVersion, TaskOptions{
+		Queue:    EmailQueue,
+		MaxRetry: f.Pointer(MaxRetry),
+		Timeout:  TimeOut,
+	}, handler.Send)
 }
 
-func (p *Producer) SendEmail(payload *EmailPayload) error {
-	data, err := payload.Data()
-	if err != nil {
-		return err
-	}
-	task := asynq.NewTask(EmailSendTask, data)
-
-	info, err := p.client.Enqueue(task,
-		asynq.MaxRetry(MaxRetry),
-		asynq.Timeout(TimeOut),
-		asynq.Queue(EmailQueue),
-	)
-	if err != nil {
-		return err
-	}
-	logEnqueue(info)
-
-	return nil
-}
-
-func (e *EmailHandler) SendEmailHandler(ctx context.Context, t *asynq.Task) error {
-	var payload EmailPayload
-	err := sonic.ConfigFastest.Unmarshal(t.Payload(), &payload)
-	if err != nil {
-		return err
-	}
-
-	err = e.mailerService.SendEmail(ctx, payload.To, payload.Subject, payload.Body)
-	if err != nil {
-		return err
-	}
-
-	return nil
+func (e *EmailHandler) Send(ctx context.Context, payload EmailPayload) error {
+	return e.mailerService.SendEmail(ctx, payload.To, payload.Subject, payload.Body, payload.Text)
 }
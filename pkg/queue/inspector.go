@@ -0,0 +1,259 @@
+package queue
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/hibiken/asynq"
+)
+
+const (
+	StatePending   = "pending"
+	StateActive    = "active"
+	StateScheduled = "scheduled"
+	StateRetry     = "retry"
+	StateArchived  = "archived"
+	StateCompleted = "completed"
+
+	redacted = "[REDACTED]"
+)
+
+var ErrUnknownState = errors.New("unknown task state")
+
+// Inspector exposes asynq queues for administration. Payloads are always returned redacted.
+type Inspector struct {
+	inspector *asynq.Inspector
+}
+
+type QueueStats struct {
+	Queue          string        `json:"queue"`
+	Size           int           `json:"size"`
+	Pending        int           `json:"pending"`
+	Active         int           `json:"active"`
+	Scheduled      int           `json:"scheduled"`
+	Retry          int           `json:"retry"`
+	Archived       int           `json:"archived"`
+	Completed      int           `json:"completed"`
+	Processed      int           `json:"processed"`
+	Failed         int           `json:"failed"`
+	ProcessedTotal int           `json:"processedTotal"`
+	FailedTotal    int           `json:"failedTotal"`
+	Latency        time.Duration `json:"latency"`
+	MemoryUsage    int64         `json:"memoryUsage"`
+	Paused         bool          `json:"paused"`
+}
+
+type TaskView struct {
+	ID            string     `json:"id"`
+	Queue         string     `json:"queue"`
+	Type          string     `json:"type"`
+	State         string     `json:"state"`
+	Payload       any        `json:"payload"`
+	MaxRetry      int        `json:"maxRetry"`
+	Retried       int        `json:"retried"`
+	LastErr       string     `json:"lastError,omitempty"`
+	LastFailedAt  *time.Time `json:"lastFailedAt,omitempty"`
+	NextProcessAt *time.Time `json:"nextProcessAt,omitempty"`
+	CompletedAt   *time.Time `json:"completedAt,omitempty"`
+}
+
+func NewInspector(redis RedisOptions) *Inspector {
+	return &Inspector{
+		inspector: asynq.NewInspector(redis.ClientOpt()),
+	}
+}
+
+func (i *Inspector) Close() error {
+	return i.inspector.Close()
+}
+
+func (i *Inspector) Queues() ([]*QueueStats, error) {
+	names, err := i.inspector.Queues()
+	if err != nil {
+		return nil, err
+	}
+	stats := make([]*QueueStats, 0, len(names))
+	for _, name := range names {
+		s, err := i.Queue(name)
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+
+	return stats, nil
+}
+
+func (i *Inspector) Queue(name string) (*QueueStats, error) {
+	info, err := i.inspector.GetQueueInfo(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &QueueStats{
+		Queue:          info.Queue,
+		Size:           info.Size,
+		Pending:        info.Pending,
+		Active:         info.Active,
+		Scheduled:      info.Scheduled,
+		Retry:          info.Retry,
+		Archived:       info.Archived,
+		Completed:      info.Completed,
+		Processed:      info.Processed,
+		Failed:         info.Failed,
+		ProcessedTotal: info.ProcessedTotal,
+		FailedTotal:    info.FailedTotal,
+		Latency:        info.Latency,
+		MemoryUsage:    info.MemoryUsage,
+		Paused:         info.Paused,
+	}, nil
+}
+
+func (i *Inspector) Tasks(queue string, state string, page int, perPage int) ([]*TaskView, error) {
+	var (
+		tasks []*asynq.TaskInfo
+		err   error
+	)
+	opts := []asynq.ListOption{asynq.Page(page), asynq.PageSize(perPage)}
+	switch state {
+	case StatePending:
+		tasks, err = i.inspector.ListPendingTasks(queue, opts...)
+	case StateActive:
+		tasks, err = i.inspector.ListActiveTasks(queue, opts...)
+	case StateScheduled:
+		tasks, err = i.inspector.ListScheduledTasks(queue, opts...)
+	case StateRetry:
+		tasks, err = i.inspector.ListRetryTasks(queue, opts...)
+	case StateArchived:
+		tasks, err = i.inspector.ListArchivedTasks(queue, opts...)
+	case StateCompleted:
+		tasks, err = i.inspector.ListCompletedTasks(queue, opts...)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownState, state)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]*TaskView, len(tasks))
+	for n, t := range tasks {
+		views[n] = newTaskView(t)
+	}
+
+	return views, nil
+}
+
+func (i *Inspector) Task(queue string, id string) (*TaskView, error) {
+	t, err := i.inspector.GetTaskInfo(queue, id)
+	if err != nil {
+		return nil, err
+	}
+	return newTaskView(t), nil
+}
+
+func (i *Inspector) RunTask(queue string, id string) error {
+	return i.inspector.RunTask(queue, id)
+}
+
+func (i *Inspector) DeleteTask(queue string, id string) error {
+	return i.inspector.DeleteTask(queue, id)
+}
+
+func (i *Inspector) RunAllArchived(queue string) (int, error) {
+	return i.inspector.RunAllArchivedTasks(queue)
+}
+
+func (i *Inspector) DeleteAllArchived(queue string) (int, error) {
+	return i.inspector.DeleteAllArchivedTasks(queue)
+}
+
+func (i *Inspector) Pause(queue string) error {
+	return i.inspector.PauseQueue(queue)
+}
+
+func (i *Inspector) Unpause(queue string) error {
+	return i.inspector.UnpauseQueue(queue)
+}
+
+// Cancel sends a cancellation signal to the worker processing the task.
+func (i *Inspector) Cancel(id string) error {
+	return i.inspector.CancelProcessing(id)
+}
+
+func IsNotFound(err error) bool {
+	return IsQueueNotFound(err) || errors.Is(err, asynq.ErrTaskNotFound)
+}
+
+func IsQueueNotFound(err error) bool {
+	return errors.Is(err, asynq.ErrQueueNotFound)
+}
+
+func newTaskView(t *asynq.TaskInfo) *TaskView {
+	return &TaskView{
+		ID:            t.ID,
+		Queue:         t.Queue,
+		Type:          t.Type,
+		State:         t.State.String(),
+		Payload:       RedactPayload(t.Payload),
+		MaxRetry:      t.MaxRetry,
+		Retried:       t.Retried,
+		LastErr:       t.LastErr,
+		LastFailedAt:  timeOrNil(t.LastFailedAt),
+		NextProcessAt: timeOrNil(t.NextProcessAt),
+		CompletedAt:   timeOrNil(t.CompletedAt),
+	}
+}
+
+// RedactPayload decodes a JSON payload and masks every value under a sensitive key.
+// Non JSON payloads are reduced to their size.
+func RedactPayload(payload []byte) any {
+	if len(payload) == 0 {
+		return nil
+	}
+	var data any
+	if err := sonic.ConfigFastest.Unmarshal(payload, &data); err != nil {
+		return fmt.Sprintf("[%d bytes]", len(payload))
+	}
+	return redact(data)
+}
+
+func redact(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, item := range val {
+			if isSensitiveKey(k) {
+				val[k] = redacted
+				continue
+			}
+			val[k] = redact(item)
+		}
+		return val
+	case []any:
+		for n, item := range val {
+			val[n] = redact(item)
+		}
+		return val
+	default:
+		return v
+	}
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range []string{"password", "token", "secret", "body", "text", "key", "signature"} {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
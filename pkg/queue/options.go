@@ -0,0 +1,168 @@
+package queue
+
+import (
+	"crypto/tls"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
+)
+
+const DefaultQueue = "default"
+
+type RedisOptions struct {
+	Addr          string
+	Username      string
+	Password      string
+	DB            int
+	TLS           bool
+	TLSServerName string
+	TLSInsecure   bool
+}
+
+// DefaultRetryMax caps the backoff of policies without a Max.
+const DefaultRetryMax = 24 * time.Hour
+
+// RetryPolicy is an exponential backoff: Base * 2^n capped by Max (DefaultRetryMax when zero), spread by ±Jitter (0..1).
+type RetryPolicy struct {
+	Base   time.Duration
+	Max    time.Duration
+	Jitter float64
+}
+
+type TaskOptions struct {
+	Queue string
+	// MaxRetry nil falls back to the defaults, zero disables retries.
+	MaxRetry *int
+	Timeout  time.Duration
+	Unique   time.Duration
+	Retry    *RetryPolicy
+}
+
+type ConsumerOptions struct {
+	Concurrency     int
+	Queues          map[string]int
+	StrictPriority  bool
+	ShutdownTimeout time.Duration
+	Retry           RetryPolicy
+	Tasks           map[string]TaskOptions
+	// RetryDelayFuncs overrides the configured policy for a task type from code.
+	RetryDelayFuncs map[string]asynq.RetryDelayFunc
+}
+
+func (o RedisOptions) ClientOpt() asynq.RedisClientOpt {
+	opt := asynq.RedisClientOpt{
+		Addr:     o.Addr,
+		Username: o.Username,
+		Password: o.Password,
+		DB:       o.DB,
+	}
+	if o.TLS {
+		opt.TLSConfig = &tls.Config{
+			MinVersion:         tls.VersionTLS12,
+			ServerName:         o.TLSServerName,
+			InsecureSkipVerify: o.TLSInsecure, //nolint:gosec // explicitly enabled by configuration
+		}
+	}
+
+	return opt
+}
+
+// Client opens a plain Redis client with the same connection settings as the queue.
+func (o RedisOptions) Client() redis.UniversalClient {
+	client, ok := o.ClientOpt().MakeRedisClient().(redis.UniversalClient)
+	if !ok {
+		panic("queue: unsupported redis client")
+	}
+	return client
+}
+
+// TaskConfigKey converts a task type into the key used in the configuration
+// (`email:send` -> `emailsend`), as `:` cannot be part of an environment variable.
+func TaskConfigKey(taskType string) string {
+	return strings.ReplaceAll(taskType, ":", "")
+}
+
+func (p RetryPolicy) Delay(n int) time.Duration {
+	if p.Base <= 0 {
+		return asynq.DefaultRetryDelayFunc(n, nil, nil)
+	}
+	limit := p.Max
+	if limit <= 0 {
+		limit = DefaultRetryMax
+	}
+	// capping before the conversion keeps large n from overflowing the duration
+	delay := float64(p.Base) * math.Pow(2, float64(n)) //nolint:mnd // exponential backoff
+	if delay > float64(limit) {
+		delay = float64(limit)
+	}
+	if p.Jitter > 0 {
+		delay += delay * p.Jitter * (2*rand.Float64() - 1) //nolint:gosec,mnd // jitter does not need crypto rand
+	}
+
+	return time.Duration(delay)
+}
+
+func (o ConsumerOptions) retryDelayFunc() asynq.RetryDelayFunc {
+	return func(n int, err error, t *asynq.Task) time.Duration {
+		if fn, ok := o.RetryDelayFuncs[t.Type()]; ok {
+			return fn(n, err, t)
+		}
+		if task, ok := o.Tasks[TaskConfigKey(t.Type())]; ok && task.Retry != nil {
+			return task.Retry.Delay(n)
+		}
+		return o.Retry.Delay(n)
+	}
+}
+
+func (o ConsumerOptions) config() asynq.Config {
+	queues := o.Queues
+	if len(queues) == 0 {
+		queues = map[string]int{DefaultQueue: 1}
+	}
+
+	return asynq.Config{
+		Concurrency:     o.Concurrency,
+		Queues:          queues,
+		StrictPriority:  o.StrictPriority,
+		ShutdownTimeout: o.ShutdownTimeout,
+		RetryDelayFunc:  o.retryDelayFunc(),
+	}
+}
+
+func taskOptions(tasks map[string]TaskOptions, taskType string, defaults TaskOptions) []asynq.Option {
+	task, ok := tasks[TaskConfigKey(taskType)]
+	if !ok {
+		task = defaults
+	}
+	if task.Queue == "" {
+		task.Queue = defaults.Queue
+	}
+	if task.MaxRetry == nil {
+		task.MaxRetry = defaults.MaxRetry
+	}
+	if task.Timeout == 0 {
+		task.Timeout = defaults.Timeout
+	}
+	if task.Unique == 0 {
+		task.Unique = defaults.Unique
+	}
+
+	opts := []asynq.Option{
+		asynq.Queue(task.Queue),
+	}
+	if task.MaxRetry != nil {
+		opts = append(opts, asynq.MaxRetry(*task.MaxRetry))
+	}
+	if task.Timeout > 0 {
+		opts = append(opts, asynq.Timeout(task.Timeout))
+	}
+	if task.Unique > 0 {
+		opts = append(opts, asynq.Unique(task.Unique))
+	}
+
+	return opts
+}
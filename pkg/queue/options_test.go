@@ -0,0 +1,43 @@
+package queue
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy RetryPolicy
+		n      int
+		want   time.Duration
+	}{
+		{name: "first retry", policy: RetryPolicy{Base: time.Second, Max: time.Minute}, n: 0, want: time.Second},
+		{name: "exponential", policy: RetryPolicy{Base: time.Second, Max: time.Minute}, n: 3, want: 8 * time.Second},
+		{name: "capped", policy: RetryPolicy{Base: time.Second, Max: time.Minute}, n: 10, want: time.Minute},
+		{name: "large n", policy: RetryPolicy{Base: time.Second, Max: time.Minute}, n: 5000, want: time.Minute},
+		{name: "no max", policy: RetryPolicy{Base: time.Second}, n: 0, want: time.Second},
+		{name: "no max large n", policy: RetryPolicy{Base: time.Second}, n: 5000, want: DefaultRetryMax},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Delay(tt.n); got != tt.want {
+				t.Errorf("Delay(%d) = %v, want %v", tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDelayJitter(t *testing.T) {
+	policy := RetryPolicy{Base: time.Second, Jitter: 0.5}
+	for _, n := range []int{0, 5, 64, 5000} {
+		base := RetryPolicy{Base: policy.Base}.Delay(n)
+		for range 100 {
+			got := policy.Delay(n)
+			if got < base/2 || got > base+base/2 {
+				t.Fatalf("Delay(%d) = %v, want within 50%% of %v", n, got, base)
+			}
+		}
+	}
+}
@@ -10,52 +10,60 @@ import (
 
 type Producer struct {
 	client *asynq.Client
+	tasks  map[string]TaskOptions
 }
 
 type Consumer struct {
 	server *asynq.Server
 }
 
-func NewQueueProducer(redisAddr string) *Producer {
+func NewQueueProducer(redis RedisOptions, tasks map[string]TaskOptions) *Producer {
 	return &Producer{
-		client: asynq.NewClient(asynq.RedisClientOpt{
-			Addr: redisAddr,
-		}),
+		client: asynq.NewClient(redis.ClientOpt()),
+		tasks:  tasks,
 	}
 }
 
-func NewQueueConsumer(redisAddr string) *Consumer {
+func NewQueueConsumer(redis RedisOptions, opts ConsumerOptions) *Consumer {
 	return &Consumer{
-		server: asynq.NewServer(
-			asynq.RedisClientOpt{Addr: redisAddr},
-			asynq.Config{
-				Concurrency: 3, //nolint:mnd // for example
-				Queues: map[string]int{
-					EmailQueue: 2, //nolint:mnd // 2 + 1 = 3
-					"default":  1,
-				},
-			}),
+		server: asynq.NewServer(redis.ClientOpt(), opts.config()),
 	}
 }
 
 func NewQueueMux(
-	emailHandler *EmailHandler,
+	tasks *Registry,
+	periodic *PeriodicRegistry,
 ) *asynq.ServeMux {
 	mux := asynq.NewServeMux()
 	mux.Use(loggingMiddleware)
-	mux.HandleFunc(EmailSendTask, emailHandler.SendEmailHandler)
+	for typ, handler := range tasks.handlers {
+		mux.Handle(typ, handler)
+	}
+	for _, task := range periodic.Tasks() {
+		mux.HandleFunc(task.Type, task.Handler)
+	}
 
 	return mux
 }
 
+// Close stops pulling new tasks and waits up to the configured shutdown timeout for active ones.
 func (c *Consumer) Close() {
-	c.server.Stop()
+	c.server.Shutdown()
 }
 
 func (c *Consumer) Run(mux *asynq.ServeMux) error {
 	return c.server.Run(mux)
 }
 
+// Start runs the consumer in the background, leaving signal handling to the caller.
+func (c *Consumer) Start(mux *asynq.ServeMux) error {
+	return c.server.Start(mux)
+}
+
+func (c *Consumer) Ping() error {
+	return c.server.Ping()
+}
+
 func (p *Producer) Close() error {
 	return p.client.Close()
 }
@@ -0,0 +1,231 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/dbunt1tled/fiber-go-api/pkg/log"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
+)
+
+const schedulerLeaderKey = "asynq:scheduler:leader"
+
+// PeriodicTask is a recurring job declared in code. Cron may be overridden by configuration.
+type PeriodicTask struct {
+	Name    string
+	Cron    string
+	Type    string
+	Handler asynq.HandlerFunc
+	Options []asynq.Option
+}
+
+// PeriodicOverride is the configuration side of a PeriodicTask, keyed by its name.
+type PeriodicOverride struct {
+	Cron     string
+	Disabled bool
+}
+
+type PeriodicRegistry struct {
+	tasks []PeriodicTask
+}
+
+type SchedulerOptions struct {
+	Location  *time.Location
+	LeaderTTL time.Duration
+	Overrides map[string]PeriodicOverride
+}
+
+// Scheduler enqueues periodic tasks. Every instance may run one, but only the
+// instance holding the leader lock in Redis registers the cron entries.
+type Scheduler struct {
+	client   redis.UniversalClient
+	registry *PeriodicRegistry
+	opts     SchedulerOptions
+	id       string
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+func NewPeriodicRegistry(tasks ...PeriodicTask) *PeriodicRegistry {
+	return &PeriodicRegistry{tasks: tasks}
+}
+
+func (r *PeriodicRegistry) Register(task PeriodicTask) *PeriodicRegistry {
+	r.tasks = append(r.tasks, task)
+	return r
+}
+
+func (r *PeriodicRegistry) Tasks() []PeriodicTask {
+	return r.tasks
+}
+
+func NewScheduler(redisOpts RedisOptions, registry *PeriodicRegistry, opts SchedulerOptions) *Scheduler {
+	if opts.LeaderTTL <= 0 {
+		opts.LeaderTTL = 30 * time.Second //nolint:mnd // default lock ttl
+	}
+	return &Scheduler{
+		client:   redisOpts.Client(),
+		registry: registry,
+		opts:     opts,
+		id:       uuid.NewString(),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start runs the leader election loop in the background.
+func (s *Scheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+	go s.run(ctx)
+}
+
+func (s *Scheduler) Close() error {
+	if s.cancel != nil {
+		s.cancel()
+		<-s.done
+	}
+	return s.client.Close()
+}
+
+func (s *Scheduler) run(ctx context.Context) {
+	defer close(s.done)
+
+	var scheduler *asynq.Scheduler
+	stop := func() {
+		if scheduler != nil {
+			scheduler.Shutdown()
+			scheduler = nil
+			log.Logger().Info("Scheduler: leadership released", slog.String("id", s.id))
+		}
+	}
+	defer func() {
+		stop()
+		_ = s.release(context.Background())
+	}()
+
+	ticker := time.NewTicker(s.opts.LeaderTTL / 3) //nolint:mnd // renew three times per ttl
+	defer ticker.Stop()
+	for {
+		leader, err := s.elect(ctx)
+		switch {
+		case err != nil:
+			log.Logger().Error("Scheduler: leader election failed", err, slog.String("id", s.id))
+			stop()
+		case leader && scheduler == nil:
+			scheduler, err = s.newScheduler()
+			if err != nil {
+				log.Logger().Error("Scheduler: can't start", err, slog.String("id", s.id))
+				_ = s.release(ctx)
+			} else {
+				log.Logger().Info("Scheduler: elected as leader", slog.String("id", s.id))
+			}
+		case !leader:
+			stop()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// elect acquires the lock or extends it when this instance already holds it.
+func (s *Scheduler) elect(ctx context.Context) (bool, error) {
+	ok, err := s.client.SetNX(ctx, schedulerLeaderKey, s.id, s.opts.LeaderTTL).Result()
+	if err != nil {
+		return false, err
+	}
+	if ok {
+		return true, nil
+	}
+
+	renewed, err := renewScript.Run(
+		ctx,
+		s.client,
+		[]string{schedulerLeaderKey},
+		s.id,
+		s.opts.LeaderTTL.Milliseconds(),
+	).Int()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return false, err
+	}
+
+	return renewed == 1, nil
+}
+
+func (s *Scheduler) release(ctx context.Context) error {
+	return releaseScript.Run(ctx, s.client, []string{schedulerLeaderKey}, s.id).Err()
+}
+
+// newScheduler shares the election client, so a failed attempt leaves no connection behind;
+// Shutdown doesn't release anything of a scheduler that never started.
+func (s *Scheduler) newScheduler() (*asynq.Scheduler, error) {
+	scheduler := asynq.NewScheduler(sharedConnOpt{client: s.client}, &asynq.SchedulerOpts{
+		Location: s.opts.Location,
+		PostEnqueueFunc: func(info *asynq.TaskInfo, err error) {
+			if err != nil {
+				log.Logger().Error("Scheduler: enqueue failed", err)
+				return
+			}
+			logEnqueue(info)
+		},
+	})
+
+	for _, task := range s.registry.Tasks() {
+		cron := task.Cron
+		override, ok := s.opts.Overrides[task.Name]
+		if ok && override.Disabled {
+			continue
+		}
+		if ok && override.Cron != "" {
+			cron = override.Cron
+		}
+		if _, err := scheduler.Register(cron, asynq.NewTask(task.Type, nil), task.Options...); err != nil {
+			scheduler.Shutdown()
+			return nil, fmt.Errorf("register periodic task %s: %w", task.Name, err)
+		}
+	}
+
+	if err := scheduler.Start(); err != nil {
+		scheduler.Shutdown()
+		return nil, err
+	}
+
+	return scheduler, nil
+}
+
+// sharedConnOpt lends the election client to an asynq scheduler, whose Shutdown closes the client it was
+// given; the Scheduler closes it once it stops.
+type sharedConnOpt struct {
+	client redis.UniversalClient
+}
+
+func (o sharedConnOpt) MakeRedisClient() interface{} {
+	return sharedClient{UniversalClient: o.client}
+}
+
+type sharedClient struct {
+	redis.UniversalClient
+}
+
+func (sharedClient) Close() error {
+	return nil
+}
+
+var renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`) //nolint:gochecknoglobals // compiled once
+
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`) //nolint:gochecknoglobals // compiled once
@@ -0,0 +1,140 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/bytedance/sonic"
+	"github.com/hibiken/asynq"
+)
+
+// Registry binds typed tasks to the producer and collects their handlers for the consumer mux.
+type Registry struct {
+	producer *Producer
+	handlers map[string]asynq.Handler
+}
+
+// Task is a background job with a typed payload. Payloads are stored as a
+// versioned JSON envelope so handlers can upgrade tasks enqueued by older releases.
+type Task[P any] struct {
+	registry *Registry
+	typ      string
+	version  int
+	defaults TaskOptions
+	handler  func(context.Context, P) error
+	upgrade  func(version int, data []byte) (P, error)
+}
+
+type envelope struct {
+	Version *int            `json:"v"`
+	Data    json.RawMessage `json:"data"`
+}
+
+func NewRegistry(producer *Producer) *Registry {
+	return &Registry{
+		producer: producer,
+		handlers: make(map[string]asynq.Handler),
+	}
+}
+
+// Register declares a task type once: its payload, schema version, default options and handler.
+func Register[P any](
+	r *Registry,
+	typ string,
+	version int,
+	defaults TaskOptions,
+	handler func(context.Context, P) error,
+) *Task[P] {
+	if _, ok := r.handlers[typ]; ok {
+		panic(fmt.Sprintf("queue: task %s already registered", typ))
+	}
+	if defaults.Queue == "" {
+		defaults.Queue = DefaultQueue
+	}
+	t := &Task[P]{
+		registry: r,
+		typ:      typ,
+		version:  version,
+		defaults: defaults,
+		handler:  handler,
+	}
+	r.handlers[typ] = t
+
+	return t
+}
+
+// WithUpgrade decodes payloads written with an older schema version.
+func (t *Task[P]) WithUpgrade(upgrade func(version int, data []byte) (P, error)) *Task[P] {
+	t.upgrade = upgrade
+	return t
+}
+
+func (t *Task[P]) Type() string {
+	return t.typ
+}
+
+// Enqueue stores the task; opts are applied after the configured defaults, e.g. asynq.ProcessIn or asynq.ProcessAt.
+func (t *Task[P]) Enqueue(ctx context.Context, payload P, opts ...asynq.Option) (*asynq.TaskInfo, error) {
+	data, err := t.encode(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	options := append(taskOptions(t.registry.producer.tasks, t.typ, t.defaults), opts...)
+	info, err := t.registry.producer.client.EnqueueContext(ctx, asynq.NewTask(t.typ, data), options...)
+	if err != nil {
+		return nil, err
+	}
+	logEnqueue(info)
+
+	return info, nil
+}
+
+func (t *Task[P]) ProcessTask(ctx context.Context, task *asynq.Task) error {
+	payload, err := t.decode(task.Payload())
+	if err != nil {
+		// a payload that can't be decoded won't be fixed by a retry
+		return fmt.Errorf("decode %s payload: %w: %w", t.typ, err, asynq.SkipRetry)
+	}
+
+	return t.handler(ctx, payload)
+}
+
+func (t *Task[P]) encode(payload P) ([]byte, error) {
+	data, err := sonic.ConfigFastest.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return sonic.ConfigFastest.Marshal(envelope{Version: &t.version, Data: data})
+}
+
+func (t *Task[P]) decode(raw []byte) (P, error) {
+	var (
+		payload P
+		env     envelope
+	)
+	if err := sonic.ConfigFastest.Unmarshal(raw, &env); err != nil {
+		return payload, err
+	}
+
+	// payloads enqueued before the envelope existed are version 0
+	version, data := 0, raw
+	if env.Version != nil {
+		version, data = *env.Version, env.Data
+	}
+
+	switch {
+	case version == t.version:
+		err := sonic.ConfigFastest.Unmarshal(data, &payload)
+		return payload, err
+	case version > t.version:
+		return payload, fmt.Errorf("unsupported payload version %d (max %d)", version, t.version)
+	case t.upgrade != nil:
+		return t.upgrade(version, data)
+	default:
+		err := sonic.ConfigFastest.Unmarshal(data, &payload)
+		return payload, err
+	}
+}
@@ -0,0 +1,88 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/bytedance/sonic"
+	"github.com/hibiken/asynq"
+)
+
+type testPayload struct {
+	Name string `json:"name"`
+}
+
+func TestTaskDecode(t *testing.T) {
+	// version 2 renamed title to name
+	upgrade := func(version int, data []byte) (testPayload, error) {
+		var old struct {
+			Title string `json:"title"`
+		}
+		if err := sonic.ConfigFastest.Unmarshal(data, &old); err != nil {
+			return testPayload{}, err
+		}
+		return testPayload{Name: fmt.Sprintf("v%d %s", version, old.Title)}, nil
+	}
+
+	tests := []struct {
+		name     string
+		version  int
+		upgrade  func(int, []byte) (testPayload, error)
+		raw      string
+		want     testPayload
+		wantSkip bool
+	}{
+		{name: "current", version: 2, raw: `{"v":2,"data":{"name":"a"}}`, want: testPayload{Name: "a"}},
+		{name: "bare", version: 1, raw: `{"name":"a"}`, want: testPayload{Name: "a"}},
+		{name: "older without upgrade", version: 2, raw: `{"v":1,"data":{"name":"a"}}`, want: testPayload{Name: "a"}},
+		{name: "older with upgrade", version: 2, upgrade: upgrade, raw: `{"v":1,"data":{"title":"a"}}`, want: testPayload{Name: "v1 a"}},
+		{name: "bare with upgrade", version: 2, upgrade: upgrade, raw: `{"title":"a"}`, want: testPayload{Name: "v0 a"}},
+		{name: "newer", version: 1, raw: `{"v":2,"data":{"name":"a"}}`, wantSkip: true},
+		{name: "malformed", version: 1, raw: `{"v":`, wantSkip: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got testPayload
+			task := Register(NewRegistry(nil), "test:task", tt.version, TaskOptions{}, func(_ context.Context, p testPayload) error {
+				got = p
+				return nil
+			})
+			if tt.upgrade != nil {
+				task.WithUpgrade(tt.upgrade)
+			}
+
+			err := task.ProcessTask(context.Background(), asynq.NewTask("test:task", []byte(tt.raw)))
+			if tt.wantSkip {
+				if !errors.Is(err, asynq.SkipRetry) {
+					t.Errorf("ProcessTask() error = %v, want %v", err, asynq.SkipRetry)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ProcessTask() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("payload = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTaskEncodeRoundTrip(t *testing.T) {
+	task := Register(NewRegistry(nil), "test:task", 3, TaskOptions{}, func(context.Context, testPayload) error { return nil })
+
+	raw, err := task.encode(testPayload{Name: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"v":3,"data":{"name":"a"}}`; string(raw) != want {
+		t.Errorf("encode() = %s, want %s", raw, want)
+	}
+	got, err := task.decode(raw)
+	if err != nil || got.Name != "a" {
+		t.Errorf("decode() = %+v, %v", got, err)
+	}
+}
@@ -0,0 +1,234 @@
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/dbunt1tled/fiber-go-api/pkg/log"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	channelKey     = "sse:events"
+	sequenceKey    = "sse:seq"
+	bufferKey      = "sse:buffer:"
+	subscriberSize = 16
+)
+
+var ErrClosed = errors.New("sse: hub is closed")
+
+type Message struct {
+	ID    uint64          `json:"id"`
+	User  string          `json:"user"`
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
+}
+
+type Options struct {
+	// BufferSize is how many recent messages per user are kept for Last-Event-ID resume.
+	BufferSize int
+	BufferTTL  time.Duration
+}
+
+// Hub fans messages out to the streams of a user. Messages go through Redis
+// pub/sub, so a client receives them whichever instance (or prefork child) it is connected to.
+type Hub struct {
+	client redis.UniversalClient
+	opts   Options
+
+	mu          sync.RWMutex
+	subscribers map[string]map[*Subscription]struct{}
+	closed      bool
+	pubsub      *redis.PubSub
+	done        chan struct{}
+}
+
+type Subscription struct {
+	hub      *Hub
+	user     string
+	messages chan Message
+	once     sync.Once
+}
+
+func NewHub(client redis.UniversalClient, opts Options) *Hub {
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 100 //nolint:mnd // default buffer
+	}
+	if opts.BufferTTL <= 0 {
+		opts.BufferTTL = 5 * time.Minute //nolint:mnd // default buffer ttl
+	}
+
+	return &Hub{
+		client:      client,
+		opts:        opts,
+		subscribers: make(map[string]map[*Subscription]struct{}),
+	}
+}
+
+// Start listens to the Redis channel and delivers messages to local subscribers.
+// Only instances that hold client connections need to start the hub; publishing works without it.
+func (h *Hub) Start(ctx context.Context) {
+	h.pubsub = h.client.Subscribe(ctx, channelKey)
+	h.done = make(chan struct{})
+	go h.run(h.pubsub.Channel())
+}
+
+// Close ends every open stream and the channel subscription, the Redis client stays open for its owner.
+func (h *Hub) Close() error {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return nil
+	}
+	h.closed = true
+	for _, subs := range h.subscribers {
+		for sub := range subs {
+			close(sub.messages)
+		}
+	}
+	h.subscribers = nil
+	h.mu.Unlock()
+
+	if h.pubsub != nil {
+		_ = h.pubsub.Close()
+		<-h.done
+	}
+	return nil
+}
+
+func (h *Hub) Publish(ctx context.Context, user string, event string, data any) error {
+	payload, err := sonic.ConfigFastest.Marshal(data)
+	if err != nil {
+		return err
+	}
+	id, err := h.client.Incr(ctx, sequenceKey).Uint64()
+	if err != nil {
+		return err
+	}
+	raw, err := sonic.ConfigFastest.Marshal(Message{ID: id, User: user, Event: event, Data: payload})
+	if err != nil {
+		return err
+	}
+
+	key := bufferKey + user
+	_, err = h.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.LPush(ctx, key, raw)
+		p.LTrim(ctx, key, 0, int64(h.opts.BufferSize-1))
+		p.Expire(ctx, key, h.opts.BufferTTL)
+		p.Publish(ctx, channelKey, raw)
+		return nil
+	})
+
+	return err
+}
+
+// Subscribe registers a stream for the user and returns the buffered messages newer than lastEventID.
+func (h *Hub) Subscribe(ctx context.Context, user string, lastEventID uint64) (*Subscription, []Message, error) {
+	sub := &Subscription{
+		hub:      h,
+		user:     user,
+		messages: make(chan Message, subscriberSize),
+	}
+
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return nil, nil, ErrClosed
+	}
+	if h.subscribers[user] == nil {
+		h.subscribers[user] = make(map[*Subscription]struct{})
+	}
+	h.subscribers[user][sub] = struct{}{}
+	h.mu.Unlock()
+
+	if lastEventID == 0 {
+		return sub, nil, nil
+	}
+	replay, err := h.buffered(ctx, user, lastEventID)
+	if err != nil {
+		sub.Close()
+		return nil, nil, err
+	}
+
+	return sub, replay, nil
+}
+
+func (s *Subscription) Messages() <-chan Message {
+	return s.messages
+}
+
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.hub.mu.Lock()
+		defer s.hub.mu.Unlock()
+		subs, ok := s.hub.subscribers[s.user]
+		if !ok {
+			return
+		}
+		if _, ok = subs[s]; ok {
+			delete(subs, s)
+			close(s.messages)
+		}
+		if len(subs) == 0 {
+			delete(s.hub.subscribers, s.user)
+		}
+	})
+}
+
+func (h *Hub) buffered(ctx context.Context, user string, lastEventID uint64) ([]Message, error) {
+	items, err := h.client.LRange(ctx, bufferKey+user, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]Message, 0, len(items))
+	for _, item := range items {
+		var m Message
+		if err = sonic.ConfigFastest.UnmarshalFromString(item, &m); err != nil {
+			continue
+		}
+		if m.ID > lastEventID {
+			messages = append(messages, m)
+		}
+	}
+	// the buffer is newest first
+	slices.Reverse(messages)
+
+	return messages, nil
+}
+
+func (h *Hub) run(ch <-chan *redis.Message) {
+	defer close(h.done)
+	for msg := range ch {
+		var m Message
+		if err := sonic.ConfigFastest.UnmarshalFromString(msg.Payload, &m); err != nil {
+			log.Logger().Error("SSE: invalid message", err)
+			continue
+		}
+		h.dispatch(m)
+	}
+}
+
+// dispatch never blocks: a stream that can't keep up loses the message and
+// can recover it from the buffer after reconnecting.
+func (h *Hub) dispatch(m Message) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for sub := range h.subscribers[m.User] {
+		select {
+		case sub.messages <- m:
+		default:
+			log.Logger().Warnf("SSE: dropped message %d for slow stream of %s", m.ID, m.User)
+		}
+	}
+}
+
+func (m Message) String() string {
+	return fmt.Sprintf("id: %d\nevent: %s\ndata: %s\n\n", m.ID, m.Event, m.Data)
+}
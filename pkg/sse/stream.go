@@ -0,0 +1,68 @@
+package sse
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"time"
+)
+
+type StreamOptions struct {
+	Heartbeat time.Duration
+	// Retry is the reconnection delay suggested to the browser.
+	Retry time.Duration
+}
+
+// Serve writes the replayed messages, then live ones until the subscription is
+// closed or the client goes away. Comment pings keep proxies from dropping an idle connection.
+func Serve(w *bufio.Writer, conn net.Conn, sub *Subscription, replay []Message, opts StreamOptions) {
+	defer sub.Close()
+	if opts.Heartbeat <= 0 {
+		opts.Heartbeat = 15 * time.Second //nolint:mnd // default ping interval
+	}
+
+	write := func(chunk string) bool {
+		if conn != nil {
+			// the server write timeout would otherwise cut long lived streams
+			_ = conn.SetWriteDeadline(time.Now().Add(2 * opts.Heartbeat)) //nolint:mnd // two missed pings
+		}
+		if _, err := w.WriteString(chunk); err != nil {
+			return false
+		}
+		return w.Flush() == nil
+	}
+
+	if opts.Retry > 0 && !write(fmt.Sprintf("retry: %d\n\n", opts.Retry.Milliseconds())) {
+		return
+	}
+	var last uint64
+	for _, m := range replay {
+		if !write(m.String()) {
+			return
+		}
+		last = m.ID
+	}
+
+	ticker := time.NewTicker(opts.Heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case m, ok := <-sub.Messages():
+			if !ok {
+				return
+			}
+			// already sent from the buffer
+			if m.ID <= last {
+				continue
+			}
+			if !write(m.String()) {
+				return
+			}
+			last = m.ID
+		case <-ticker.C:
+			if !write(": ping\n\n") {
+				return
+			}
+		}
+	}
+}
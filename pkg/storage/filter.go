@@ -46,6 +46,7 @@ type Filter struct {
 	OrderBy []Sort
 	Limit   int
 	Offset  int
+	trashed trashed
 }
 
 type PaginationInfo interface {
@@ -67,6 +68,23 @@ type Paginator[T any] struct {
 	HasPrev    bool  `json:"prev"`
 }
 
+func NewPaginator[T any](items []T, total int64, page int, perPage int) *Paginator[T] {
+	totalPages := int(total) / perPage
+	if int(total)%perPage != 0 {
+		totalPages++
+	}
+
+	return &Paginator[T]{
+		Items:      items,
+		Total:      total,
+		Page:       page,
+		PerPage:    perPage,
+		TotalPages: totalPages,
+		HasNext:    page < totalPages,
+		HasPrev:    page > 1,
+	}
+}
+
 func (p *Paginator[T]) GetTotal() int64 {
 	return p.Total
 }
@@ -98,11 +116,21 @@ type Sort struct {
 
 type QueryOption func(*queryConfig)
 
+// trashed selects the rows of SoftDeletable models a query reads.
+type trashed int
+
+const (
+	trashedExcluded trashed = iota
+	trashedIncluded
+	trashedOnly
+)
+
 type queryConfig struct {
 	rules   []Rule
 	orderBy []Sort
 	limit   int
 	offset  int
+	trashed trashed
 }
 
 func WithFilter(rules ...Rule) QueryOption {
@@ -167,6 +195,20 @@ func WithPagination(page int, perPage int) QueryOption {
 	}
 }
 
+// WithTrashed reads soft deleted rows along with the others.
+func WithTrashed() QueryOption {
+	return func(cfg *queryConfig) {
+		cfg.trashed = trashedIncluded
+	}
+}
+
+// OnlyTrashed reads soft deleted rows only.
+func OnlyTrashed() QueryOption {
+	return func(cfg *queryConfig) {
+		cfg.trashed = trashedOnly
+	}
+}
+
 func NewRule(field string, oper Oper, value interface{}) Rule {
 	return Rule{
 		Field:     field,
@@ -1,9 +1,34 @@
 package storage
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DeletedAtColumn marks the rows of SoftDeletable models as deleted.
+	DeletedAtColumn = "deleted_at"
+	// VersionColumn counts the updates of the rows of Versioned models.
+	VersionColumn = "version"
+)
 
 type Model interface {
 	TableName() string
 	GetID() uuid.UUID
 	SetID(uuid.UUID)
 }
+
+// SoftDeletable models are deleted by setting DeletedAtColumn, reads leave them out unless
+// WithTrashed or OnlyTrashed is given.
+type SoftDeletable interface {
+	Model
+	GetDeletedAt() *time.Time
+}
+
+// Versioned models are updated only while their version is still the stored one, every update
+// increments it; a stale update fails with ErrConflict.
+type Versioned interface {
+	Model
+	GetVersion() int
+}
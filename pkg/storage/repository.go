@@ -22,6 +22,12 @@ type RowsScanner[T Model] func(pgx.Rows) ([]T, error)
 
 type RecordBuilder[T Model] func(T) goqu.Record
 
+var (
+	ErrNotSoftDeletable = errors.New("entity is not soft deletable")
+	// ErrConflict means the entity was changed or deleted since it was read.
+	ErrConflict = errors.New("entity was changed by another request")
+)
+
 type Repository[T Model] struct {
 	db            *pgxpool.Pool
 	dialect       goqu.DialectWrapper
@@ -29,6 +35,8 @@ type Repository[T Model] struct {
 	scanner       Scanner[T]
 	rowsScanner   RowsScanner[T]
 	recordBuilder RecordBuilder[T]
+	softDelete    bool
+	versioned     bool
 }
 
 func NewRepository[T Model](
@@ -38,6 +46,10 @@ func NewRepository[T Model](
 	rowsScanner RowsScanner[T],
 	recordBuilder RecordBuilder[T],
 ) *Repository[T] {
+	var zero T
+	_, softDelete := any(zero).(SoftDeletable)
+	_, versioned := any(zero).(Versioned)
+
 	return &Repository[T]{
 		db:            pool,
 		dialect:       goqu.Dialect("postgres"),
@@ -45,11 +57,14 @@ func NewRepository[T Model](
 		scanner:       scanner,
 		rowsScanner:   rowsScanner,
 		recordBuilder: recordBuilder,
+		softDelete:    softDelete,
+		versioned:     versioned,
 	}
 }
 
+// FindByID leaves soft deleted rows out, use One with WithTrashed to read them.
 func (r *Repository[T]) FindByID(ctx context.Context, id uuid.UUID) (T, error) {
-	return r.findByIDWithQuerier(ctx, r.db, id)
+	return r.findByIDWithQuerier(ctx, r.db, id, trashedExcluded)
 }
 
 func (r *Repository[T]) List(ctx context.Context, opts ...QueryOption) ([]T, error) {
@@ -102,7 +117,6 @@ func (r *Repository[T]) Paginate(
 	var (
 		items      []T
 		totalCount int64
-		totalPages int
 	)
 
 	g, cx := errgroup.WithContext(ctx)
@@ -133,20 +147,7 @@ func (r *Repository[T]) Paginate(
 		return nil, err
 	}
 
-	totalPages = int(totalCount) / perPage
-	if int(totalCount)%perPage != 0 {
-		totalPages++
-	}
-
-	return &Paginator[T]{
-		Items:      items,
-		Total:      totalCount,
-		Page:       page,
-		PerPage:    perPage,
-		TotalPages: totalPages,
-		HasNext:    page < totalPages,
-		HasPrev:    page > 1,
-	}, nil
+	return NewPaginator(items, totalCount, page, perPage), nil
 }
 
 func (r *Repository[T]) Insert(ctx context.Context, entity T) (T, error) {
@@ -156,7 +157,28 @@ func (r *Repository[T]) Insert(ctx context.Context, entity T) (T, error) {
 		return zero, fmt.Errorf("insert entity: %w", err)
 	}
 
-	return r.findByIDWithQuerier(ctx, r.db, entity.GetID())
+	return r.findByIDWithQuerier(ctx, r.db, entity.GetID(), trashedIncluded)
+}
+
+// InsertIgnore skips the entity when its row already exists, so a retried write stores it once.
+func (r *Repository[T]) InsertIgnore(ctx context.Context, entity T) error {
+	record := r.recordBuilder(entity)
+	record["id"] = entity.GetID()
+
+	sql, args, err := r.dialect.
+		Insert(r.table).
+		Rows(record).
+		OnConflict(goqu.DoNothing()).
+		ToSQL()
+	if err != nil {
+		return fmt.Errorf("build query: %w", err)
+	}
+
+	if _, err := r.db.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("execute query: %w", err)
+	}
+
+	return nil
 }
 
 func (r *Repository[T]) InsertBatch(ctx context.Context, entities []T) error {
@@ -169,17 +191,113 @@ func (r *Repository[T]) Update(ctx context.Context, entity T) (T, error) {
 	if err != nil {
 		return zero, err
 	}
-	return r.FindByID(ctx, entity.GetID())
+	return r.findByIDWithQuerier(ctx, r.db, entity.GetID(), trashedIncluded)
+}
+
+// UpdateWhere sets the record on every row matching the rules and returns how many rows changed,
+// soft deleted rows are left alone. Versions of Versioned models are incremented.
+func (r *Repository[T]) UpdateWhere(ctx context.Context, record goqu.Record, rules ...Rule) (int64, error) {
+	query := r.dialect.Update(r.table).Set(r.nextVersion(record))
+	for _, rule := range rules {
+		if exp := ruleExpression(rule); exp != nil {
+			query = query.Where(exp)
+		}
+	}
+	if exp := r.trashedExpression(trashedExcluded); exp != nil {
+		query = query.Where(exp)
+	}
+
+	sql, args, err := query.ToSQL()
+	if err != nil {
+		return 0, fmt.Errorf("build query: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("execute query: %w", err)
+	}
+
+	return result.RowsAffected(), nil
 }
 
+// Delete removes the row, rows of SoftDeletable models are only marked deleted.
 func (r *Repository[T]) Delete(ctx context.Context, id uuid.UUID) error {
+	if r.softDelete {
+		return r.trashWithQuerier(ctx, r.db, id)
+	}
 	return r.deleteWithQuerier(ctx, r.db, id)
 }
 
 func (r *Repository[T]) DeleteBatch(ctx context.Context, ids []uuid.UUID) error {
+	if r.softDelete {
+		return r.trashBatchWithQuerier(ctx, r.db, ids)
+	}
+	return r.deleteBatchWithQuerier(ctx, r.db, ids)
+}
+
+// ForceDeleteWhere removes every row matching the conditions for good, soft deleted or not,
+// and returns the removed entities.
+func (r *Repository[T]) ForceDeleteWhere(ctx context.Context, where ...goqu.Expression) ([]T, error) {
+	sql, args, err := r.dialect.
+		Delete(r.table).
+		Where(where...).
+		Returning(goqu.Star()).
+		ToSQL()
+	if err != nil {
+		return nil, fmt.Errorf("build query: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("execute query: %w", err)
+	}
+	defer rows.Close()
+
+	entities, err := r.rowsScanner(rows)
+	if err != nil {
+		return nil, fmt.Errorf("scan rows: %w", err)
+	}
+
+	return entities, nil
+}
+
+// ForceDelete removes the row for good, soft deleted or not.
+func (r *Repository[T]) ForceDelete(ctx context.Context, id uuid.UUID) error {
+	return r.deleteWithQuerier(ctx, r.db, id)
+}
+
+func (r *Repository[T]) ForceDeleteBatch(ctx context.Context, ids []uuid.UUID) error {
 	return r.deleteBatchWithQuerier(ctx, r.db, ids)
 }
 
+// Restore brings back a soft deleted row.
+func (r *Repository[T]) Restore(ctx context.Context, id uuid.UUID) (T, error) {
+	var zero T
+	if !r.softDelete {
+		return zero, ErrNotSoftDeletable
+	}
+
+	sql, args, err := r.dialect.
+		Update(r.table).
+		Set(r.nextVersion(goqu.Record{DeletedAtColumn: nil})).
+		Where(goqu.Ex{"id": id}, goqu.C(DeletedAtColumn).IsNotNull()).
+		ToSQL()
+	if err != nil {
+		return zero, fmt.Errorf("build query: %w", err)
+	}
+
+	result, err := r.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return zero, fmt.Errorf("execute query: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return zero, fmt.Errorf("entity not found")
+	}
+
+	return r.findByIDWithQuerier(ctx, r.db, id, trashedExcluded)
+}
+
 func (r *Repository[T]) Count(ctx context.Context, opts ...QueryOption) (int64, error) {
 	cfg := &queryConfig{}
 	for _, opt := range opts {
@@ -195,6 +313,9 @@ func (r *Repository[T]) countWithConfig(ctx context.Context, cfg *queryConfig) (
 	for _, rule := range cfg.rules {
 		query = r.applyRule(query, rule)
 	}
+	if exp := r.trashedExpression(cfg.trashed); exp != nil {
+		query = query.Where(exp)
+	}
 
 	sql, args, err := query.ToSQL()
 	if err != nil {
@@ -209,13 +330,17 @@ func (r *Repository[T]) countWithConfig(ctx context.Context, cfg *queryConfig) (
 	return count, nil
 }
 
-func (r *Repository[T]) findByIDWithQuerier(ctx context.Context, q Querier, id uuid.UUID) (T, error) {
+func (r *Repository[T]) findByIDWithQuerier(ctx context.Context, q Querier, id uuid.UUID, t trashed) (T, error) {
 	var zero T
 
-	sql, args, err := r.dialect.
+	query := r.dialect.
 		From(r.table).
-		Where(goqu.Ex{"id": id}).
-		ToSQL()
+		Where(goqu.Ex{"id": id})
+	if exp := r.trashedExpression(t); exp != nil {
+		query = query.Where(exp)
+	}
+
+	sql, args, err := query.ToSQL()
 	if err != nil {
 		return zero, fmt.Errorf("build query: %w", err)
 	}
@@ -303,14 +428,22 @@ func (r *Repository[T]) insertBatchWithQuerier(ctx context.Context, q Querier, e
 	return nil
 }
 
+// updateWithQuerier updates a row of a Versioned model only while it has the version of the entity,
+// soft deleted rows are left alone.
 func (r *Repository[T]) updateWithQuerier(ctx context.Context, q Querier, entity T) error {
-	record := r.recordBuilder(entity)
-
-	sql, args, err := r.dialect.
+	where := goqu.Ex{"id": entity.GetID()}
+	if v, ok := any(entity).(Versioned); ok {
+		where[VersionColumn] = v.GetVersion()
+	}
+	query := r.dialect.
 		Update(r.table).
-		Set(record).
-		Where(goqu.Ex{"id": entity.GetID()}).
-		ToSQL()
+		Set(r.nextVersion(r.recordBuilder(entity))).
+		Where(where)
+	if exp := r.trashedExpression(trashedExcluded); exp != nil {
+		query = query.Where(exp)
+	}
+
+	sql, args, err := query.ToSQL()
 	if err != nil {
 		return fmt.Errorf("build query: %w", err)
 	}
@@ -321,12 +454,30 @@ func (r *Repository[T]) updateWithQuerier(ctx context.Context, q Querier, entity
 	}
 
 	if result.RowsAffected() == 0 {
+		if r.versioned {
+			return ErrConflict
+		}
 		return fmt.Errorf("entity not found")
 	}
 
 	return nil
 }
 
+// nextVersion adds the version increment of Versioned models to a copy of the record.
+func (r *Repository[T]) nextVersion(record goqu.Record) goqu.Record {
+	if !r.versioned {
+		return record
+	}
+
+	next := make(goqu.Record, len(record)+1)
+	for field, value := range record {
+		next[field] = value
+	}
+	next[VersionColumn] = goqu.L("? + 1", goqu.C(VersionColumn))
+
+	return next
+}
+
 func (r *Repository[T]) deleteWithQuerier(ctx context.Context, q Querier, id uuid.UUID) error {
 	sql, args, err := r.dialect.
 		Delete(r.table).
@@ -348,6 +499,50 @@ func (r *Repository[T]) deleteWithQuerier(ctx context.Context, q Querier, id uui
 	return nil
 }
 
+// trashWithQuerier marks a row of a SoftDeletable model deleted, a row deleted before counts as not found.
+func (r *Repository[T]) trashWithQuerier(ctx context.Context, q Querier, id uuid.UUID) error {
+	sql, args, err := r.dialect.
+		Update(r.table).
+		Set(r.nextVersion(goqu.Record{DeletedAtColumn: goqu.L("NOW()")})).
+		Where(goqu.Ex{"id": id}, goqu.C(DeletedAtColumn).IsNull()).
+		ToSQL()
+	if err != nil {
+		return fmt.Errorf("build query: %w", err)
+	}
+
+	result, err := q.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("execute query: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("entity not found")
+	}
+
+	return nil
+}
+
+func (r *Repository[T]) trashBatchWithQuerier(ctx context.Context, q Querier, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	sql, args, err := r.dialect.
+		Update(r.table).
+		Set(r.nextVersion(goqu.Record{DeletedAtColumn: goqu.L("NOW()")})).
+		Where(goqu.Ex{"id": ids}, goqu.C(DeletedAtColumn).IsNull()).
+		ToSQL()
+	if err != nil {
+		return fmt.Errorf("build query: %w", err)
+	}
+
+	if _, err := q.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("execute query: %w", err)
+	}
+
+	return nil
+}
+
 func (r *Repository[T]) deleteBatchWithQuerier(ctx context.Context, q Querier, ids []uuid.UUID) error {
 	if len(ids) == 0 {
 		return nil
@@ -378,6 +573,7 @@ func (r *Repository[T]) buildFilter(cfg *queryConfig) *Filter {
 		OrderBy: cfg.orderBy,
 		Limit:   cfg.limit,
 		Offset:  cfg.offset,
+		trashed: cfg.trashed,
 	}
 }
 
@@ -389,6 +585,9 @@ func (r *Repository[T]) applyFilter(query *goqu.SelectDataset, filter *Filter) *
 	for _, rule := range filter.Rules {
 		query = r.applyRule(query, rule)
 	}
+	if exp := r.trashedExpression(filter.trashed); exp != nil {
+		query = query.Where(exp)
+	}
 
 	for _, order := range filter.OrderBy {
 		if order.Descending {
@@ -410,56 +609,81 @@ func (r *Repository[T]) applyFilter(query *goqu.SelectDataset, filter *Filter) *
 }
 
 func (r *Repository[T]) applyRule(query *goqu.SelectDataset, rule Rule) *goqu.SelectDataset {
+	if exp := ruleExpression(rule); exp != nil {
+		return query.Where(exp)
+	}
+	return query
+}
+
+// trashedExpression limits a query of a SoftDeletable model to the selected rows; nil means no limit.
+func (r *Repository[T]) trashedExpression(t trashed) goqu.Expression {
+	if !r.softDelete {
+		return nil
+	}
+
+	switch t {
+	case trashedIncluded:
+		return nil
+	case trashedOnly:
+		return goqu.C(DeletedAtColumn).IsNotNull()
+	default:
+		return goqu.C(DeletedAtColumn).IsNull()
+	}
+}
+
+// ruleExpression converts a rule into a WHERE expression; nil means the rule is skipped.
+func ruleExpression(rule Rule) goqu.Expression {
 	if f.IsNil(rule.Value) && (rule.Operation != OpIsNull && rule.Operation != OpIsNotNull) {
-		return query
+		return nil
 	}
 
 	col := goqu.C(rule.Field)
 
 	switch rule.Operation {
 	case OpEqual:
-		return query.Where(goqu.Ex{rule.Field: rule.Value})
+		return goqu.Ex{rule.Field: rule.Value}
 	case OpNotEqual:
-		return query.Where(col.Neq(rule.Value))
+		return col.Neq(rule.Value)
 	case OpGreaterThan:
-		return query.Where(col.Gt(rule.Value))
+		return col.Gt(rule.Value)
 	case OpGreaterThanOrEqual:
-		return query.Where(col.Gte(rule.Value))
+		return col.Gte(rule.Value)
 	case OpLessThan:
-		return query.Where(col.Lt(rule.Value))
+		return col.Lt(rule.Value)
 	case OpLessThanOrEqual:
-		return query.Where(col.Lte(rule.Value))
+		return col.Lte(rule.Value)
 	case OpLike:
-		return query.Where(col.Like(rule.Value))
+		return col.Like(rule.Value)
 	case OpILike:
-		return query.Where(col.ILike(rule.Value))
+		return col.ILike(rule.Value)
 	case OpIn:
-		return query.Where(goqu.Ex{rule.Field: rule.Value})
+		return goqu.Ex{rule.Field: rule.Value}
 	case OpNotIn:
-		return query.Where(col.NotIn(rule.Value))
+		return col.NotIn(rule.Value)
 	case OpIsNull:
-		return query.Where(col.IsNull())
+		return col.IsNull()
 	case OpIsNotNull:
-		return query.Where(col.IsNotNull())
+		return col.IsNotNull()
 	case OpContains:
 		// Array contains: column @> ARRAY[val1, val2]::type[]
-		return query.Where(buildArrayCondition(rule.Field, "@>", rule.Value))
+		return buildArrayCondition(rule.Field, "@>", rule.Value)
 	case OpContainedBy:
 		// Array contained by: column <@ ARRAY[val1, val2]::type[]
-		return query.Where(buildArrayCondition(rule.Field, "<@", rule.Value))
+		return buildArrayCondition(rule.Field, "<@", rule.Value)
 	case OpOverlaps:
 		// Array overlaps: column && ARRAY[val1, val2]::type[]
-		return query.Where(buildArrayCondition(rule.Field, "&&", rule.Value))
+		return buildArrayCondition(rule.Field, "&&", rule.Value)
 	case OpJsonContains:
 		// JSONB contains: column @> value::jsonb
-		return query.Where(goqu.L("? @> ?::jsonb", col, rule.Value))
+		return goqu.L("? @> ?::jsonb", col, rule.Value)
 	case OpJsonExists:
 		// JSONB key exists: column ? value
-		return query.Where(goqu.L("? ?? ?", col, rule.Value))
+		return goqu.L("? ?? ?", col, rule.Value)
 	default:
-		return query
+		return nil
 	}
 }
+
 func escapeSingleQuote(s string) string {
 	return strings.ReplaceAll(s, "'", "''")
 }
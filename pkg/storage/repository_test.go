@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type testModel struct {
+	ID   uuid.UUID
+	Name string
+}
+
+func (m *testModel) TableName() string  { return "tests" }
+func (m *testModel) GetID() uuid.UUID   { return m.ID }
+func (m *testModel) SetID(id uuid.UUID) { m.ID = id }
+
+type softModel struct {
+	testModel
+	DeletedAt *time.Time
+}
+
+func (m *softModel) GetDeletedAt() *time.Time { return m.DeletedAt }
+
+// recordQuerier keeps the statements it is given and reports rowsAffected for each of them.
+type recordQuerier struct {
+	rowsAffected int64
+	sql          []string
+}
+
+func (q *recordQuerier) Query(_ context.Context, _ string, _ ...interface{}) (pgx.Rows, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (q *recordQuerier) Exec(_ context.Context, sql string, _ ...interface{}) (pgconn.CommandTag, error) {
+	q.sql = append(q.sql, sql)
+	return pgconn.NewCommandTag("UPDATE " + strconv.FormatInt(q.rowsAffected, 10)), nil
+}
+
+func (q *recordQuerier) QueryRow(_ context.Context, _ string, _ ...interface{}) pgx.Row {
+	return nil
+}
+
+func testRepository[T Model](builder RecordBuilder[T]) *Repository[T] {
+	return NewRepository[T](nil, "tests", nil, nil, builder)
+}
+
+func TestUpdateSkipsSoftDeletedRows(t *testing.T) {
+	r := testRepository(func(m *softModel) goqu.Record { return goqu.Record{"name": m.Name} })
+	q := &recordQuerier{}
+	entity := &softModel{testModel: testModel{ID: uuid.New(), Name: "name"}}
+
+	if err := r.updateWithQuerier(context.Background(), q, entity); err == nil {
+		t.Fatal("updateWithQuerier() of a soft deleted row succeeded")
+	}
+	if len(q.sql) != 1 || !strings.Contains(q.sql[0], `"deleted_at" IS NULL`) {
+		t.Errorf("update = %v, want it limited to rows that aren't deleted", q.sql)
+	}
+
+	q.rowsAffected = 1
+	if err := r.updateWithQuerier(context.Background(), q, entity); err != nil {
+		t.Errorf("updateWithQuerier() error = %v", err)
+	}
+}
+
+type versionedModel struct {
+	testModel
+	Version int
+}
+
+func (m *versionedModel) GetVersion() int { return m.Version }
+
+func TestUpdateStaleVersion(t *testing.T) {
+	r := testRepository(func(m *versionedModel) goqu.Record { return goqu.Record{"name": m.Name} })
+	q := &recordQuerier{}
+	entity := &versionedModel{testModel: testModel{ID: uuid.New(), Name: "name"}, Version: 3}
+
+	err := r.updateWithQuerier(context.Background(), q, entity)
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("updateWithQuerier() error = %v, want %v", err, ErrConflict)
+	}
+	if len(q.sql) != 1 || !strings.Contains(q.sql[0], `"version" = 3`) || !strings.Contains(q.sql[0], `"version"="version" + 1`) {
+		t.Errorf("update = %v, want it to check and increment the version", q.sql)
+	}
+}
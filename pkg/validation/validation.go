@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/dbunt1tled/fiber-go-api/pkg/e"
 	"github.com/dbunt1tled/fiber-go-api/pkg/validation/validators"
@@ -21,7 +22,6 @@ var customMessages = map[string]string{
 	"positive":  "Field %s must be a positive number",
 	"alphanum":  "Field %s must contain only alphanumeric characters",
 	"oneof":     "Invalid value for field %s",
-	"passwd":    "Field %s must contain at least 1 letter and 1 number",
 	"unique_db": "%s is already taken",
 	"eqfield":   "Field %s must be equal to %s",
 }
@@ -36,7 +36,8 @@ func ErrorValidation(err error) []e.ErrNo {
 		errorsMap := make([]e.ErrNo, 0, len(validationErrors))
 		for _, err := range validationErrors {
 			fieldName := err.StructNamespace()
-			tag := err.Tag()
+			// aliases like passwd report the rule that failed
+			tag := err.ActualTag()
 			customMessage, ok = customMessages[tag]
 			if !ok {
 				msg = defaultErrorMessage(err)
@@ -67,17 +68,23 @@ func defaultErrorMessage(err validator.FieldError) string {
 	return fmt.Sprintf("Field '%s' failed on the '%s' tag", err.Field(), err.Tag())
 }
 
-func Validator(db *pgxpool.Pool) (*validator.Validate, error) {
+func Validator(db *pgxpool.Pool, policy *validators.PasswordPolicy) (*validator.Validate, error) {
 	validate := validator.New()
 	if validate == nil {
 		return nil, errors.New("validator is not initialized")
 	}
 
-	if err := validate.RegisterValidation("passwd", validators.Password); err != nil {
-		return nil, fmt.Errorf("failed to register password validator: %w", err)
+	for tag, fn := range policy.Validators() {
+		if err := validate.RegisterValidationCtx(tag, fn); err != nil {
+			return nil, fmt.Errorf("failed to register password validator: %w", err)
+		}
+	}
+	for tag, msg := range policy.Messages() {
+		customMessages[tag] = msg
 	}
+	validate.RegisterAlias("passwd", strings.Join(validators.PasswordTags, ","))
 
-	if err := validate.RegisterValidation("regex", validators.Password); err != nil {
+	if err := validate.RegisterValidation("regex", validators.Regex); err != nil {
 		return nil, fmt.Errorf("failed to register regex validator: %w", err)
 	}
 
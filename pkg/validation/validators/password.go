@@ -1,30 +1,192 @@
 package validators
 
 import (
+	"context"
+	"fmt"
+	"strings"
 	"unicode"
+	"unicode/utf8"
 
+	"github.com/dbunt1tled/fiber-go-api/pkg/breach"
+	"github.com/dbunt1tled/fiber-go-api/pkg/log"
 	"github.com/go-playground/validator/v10"
+	"github.com/nbutton23/zxcvbn-go"
 )
 
-func Password(field validator.FieldLevel) bool {
-	s := field.Field().String()
-	var (
-		hasUpper   = false
-		hasLower   = false
-		hasNumber  = false
-		hasSpecial = false
-	)
-	for _, char := range s {
+const (
+	ClassUpper   = "upper"
+	ClassLower   = "lower"
+	ClassNumber  = "number"
+	ClassSpecial = "special"
+
+	// personalMinLength skips name parts too short to be meaningful, like initials.
+	personalMinLength = 3
+)
+
+// PasswordTags are the rules the `passwd` alias expands to, checked in this order.
+var PasswordTags = []string{
+	"passwd_length",
+	"passwd_classes",
+	"passwd_personal",
+	"passwd_strength",
+	"passwd_breached",
+}
+
+// PersonalData is implemented by requests that know whose password is validated.
+type PersonalData interface {
+	PersonalData() []string
+}
+
+type PasswordPolicy struct {
+	MinLength int
+	MaxLength int
+	// Classes lists the character classes a password needs, see the Class constants.
+	Classes []string
+	// MinScore is the zxcvbn strength score from 0 to 4 a password needs, 0 disables the check.
+	MinScore int
+	// Personal rejects passwords that contain the email or name of the user.
+	Personal bool
+	// Breached is consulted last, nil disables the check.
+	Breached breach.Checker
+}
+
+// ParseClasses reads a comma separated list of character classes.
+func ParseClasses(list string) ([]string, error) {
+	classes := make([]string, 0)
+	for _, class := range strings.Split(list, ",") {
+		switch class = strings.TrimSpace(class); class {
+		case "":
+		case ClassUpper, ClassLower, ClassNumber, ClassSpecial:
+			classes = append(classes, class)
+		default:
+			return nil, fmt.Errorf("unknown password character class %q", class)
+		}
+	}
+	return classes, nil
+}
+
+func (p *PasswordPolicy) Validators() map[string]validator.FuncCtx {
+	return map[string]validator.FuncCtx{
+		"passwd_length":   p.length,
+		"passwd_classes":  p.classes,
+		"passwd_personal": p.personal,
+		"passwd_strength": p.strength,
+		"passwd_breached": p.breached,
+	}
+}
+
+// Messages describe each rule with the configured values, %s being the field name.
+func (p *PasswordPolicy) Messages() map[string]string {
+	return map[string]string{
+		"passwd_length": fmt.Sprintf(
+			"Field %%s must be between %d and %d characters long",
+			p.MinLength,
+			p.MaxLength,
+		),
+		"passwd_classes":  "Field %s must contain " + p.classNames(),
+		"passwd_personal": "Field %s must not contain your email or name",
+		"passwd_strength": "Field %s is too easy to guess, try a longer passphrase",
+		"passwd_breached": "Field %s has appeared in a data breach, choose another password",
+	}
+}
+
+func (p *PasswordPolicy) classNames() string {
+	names := map[string]string{
+		ClassUpper:   "an uppercase letter",
+		ClassLower:   "a lowercase letter",
+		ClassNumber:  "a number",
+		ClassSpecial: "a special character",
+	}
+	parts := make([]string, 0, len(p.Classes))
+	for _, class := range p.Classes {
+		parts = append(parts, names[class])
+	}
+	if len(parts) > 1 {
+		return strings.Join(parts[:len(parts)-1], ", ") + " and " + parts[len(parts)-1]
+	}
+	return strings.Join(parts, "")
+}
+
+func (p *PasswordPolicy) length(_ context.Context, field validator.FieldLevel) bool {
+	n := utf8.RuneCountInString(field.Field().String())
+	return n >= p.MinLength && (p.MaxLength == 0 || n <= p.MaxLength)
+}
+
+func (p *PasswordPolicy) classes(_ context.Context, field validator.FieldLevel) bool {
+	found := make(map[string]bool, len(p.Classes))
+	for _, char := range field.Field().String() {
 		switch {
 		case unicode.IsUpper(char):
-			hasUpper = true
+			found[ClassUpper] = true
 		case unicode.IsLower(char):
-			hasLower = true
+			found[ClassLower] = true
 		case unicode.IsNumber(char):
-			hasNumber = true
-		case unicode.IsPunct(char) || unicode.IsSymbol(char):
-			hasSpecial = true
+			found[ClassNumber] = true
+		case unicode.IsPunct(char) || unicode.IsSymbol(char) || unicode.IsSpace(char):
+			found[ClassSpecial] = true
+		}
+	}
+	for _, class := range p.Classes {
+		if !found[class] {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *PasswordPolicy) personal(_ context.Context, field validator.FieldLevel) bool {
+	if !p.Personal {
+		return true
+	}
+	password := strings.ToLower(field.Field().String())
+	for _, part := range personalData(field) {
+		if len(part) >= personalMinLength && strings.Contains(password, strings.ToLower(part)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *PasswordPolicy) strength(_ context.Context, field validator.FieldLevel) bool {
+	if p.MinScore <= 0 {
+		return true
+	}
+	return zxcvbn.PasswordStrength(field.Field().String(), personalData(field)).Score >= p.MinScore
+}
+
+// breached lets the password through when the corpus can't be reached, registration shouldn't depend on it.
+func (p *PasswordPolicy) breached(ctx context.Context, field validator.FieldLevel) bool {
+	if p.Breached == nil {
+		return true
+	}
+	found, err := p.Breached.Breached(ctx, field.Field().String())
+	if err != nil {
+		log.Logger().ErrorContext(ctx, "Password breach check error", err)
+		return true
+	}
+	return !found
+}
+
+// personalData collects the email, its local part and the name parts of the request.
+func personalData(field validator.FieldLevel) []string {
+	parent := field.Parent()
+	if !parent.CanInterface() {
+		return nil
+	}
+	data, ok := parent.Interface().(PersonalData)
+	if !ok && parent.CanAddr() {
+		data, ok = parent.Addr().Interface().(PersonalData)
+	}
+	if !ok {
+		return nil
+	}
+
+	parts := make([]string, 0)
+	for _, value := range data.PersonalData() {
+		if local, _, found := strings.Cut(value, "@"); found {
+			parts = append(parts, local)
 		}
+		parts = append(parts, strings.Fields(value)...)
 	}
-	return hasUpper && hasLower && hasNumber && hasSpecial
+	return parts
 }
@@ -5,13 +5,17 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"sync"
 
+	"github.com/dbunt1tled/fiber-go-api/pkg/storage"
 	"github.com/go-playground/validator/v10"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type UniqueFieldValidator struct {
 	db *pgxpool.Pool
+	// softDeletes caches whether a table has storage.DeletedAtColumn, keyed by table name.
+	softDeletes sync.Map
 }
 
 func NewUniqueFieldValidator(db *pgxpool.Pool) *UniqueFieldValidator {
@@ -28,10 +32,20 @@ func (uv *UniqueFieldValidator) Validate(fl validator.FieldLevel) bool {
 	tableName := params[0]
 	columnName := params[1]
 
+	softDelete, err := uv.softDelete(tableName)
+	if err != nil {
+		return false
+	}
+
 	// Prepare the query
 	query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s = $1", tableName, columnName)
 	args := []interface{}{fl.Field().Interface()}
 
+	// soft deleted rows don't keep the value taken
+	if softDelete {
+		query += fmt.Sprintf(" AND %s IS NULL", storage.DeletedAtColumn)
+	}
+
 	if len(params) > 2 && params[2] == "exclude_id" {
 		currentStruct := fl.Parent()
 		if currentStruct.Kind() == reflect.Ptr {
@@ -49,7 +63,7 @@ func (uv *UniqueFieldValidator) Validate(fl validator.FieldLevel) bool {
 	}
 
 	var count int
-	err := uv.db.QueryRow(context.Background(), query, args...).Scan(&count)
+	err = uv.db.QueryRow(context.Background(), query, args...).Scan(&count)
 	if err != nil {
 		// In case of error, fail closed (assume not unique)
 		return false
@@ -57,3 +71,26 @@ func (uv *UniqueFieldValidator) Validate(fl validator.FieldLevel) bool {
 
 	return count == 0
 }
+
+func (uv *UniqueFieldValidator) softDelete(tableName string) (bool, error) {
+	if softDelete, ok := uv.softDeletes.Load(tableName); ok {
+		return softDelete.(bool), nil
+	}
+
+	var softDelete bool
+	err := uv.db.QueryRow(
+		context.Background(),
+		`SELECT EXISTS (
+			SELECT 1 FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name = $1 AND column_name = $2
+		)`,
+		tableName,
+		storage.DeletedAtColumn,
+	).Scan(&softDelete)
+	if err != nil {
+		return false, err
+	}
+
+	uv.softDeletes.Store(tableName, softDelete)
+	return softDelete, nil
+}